@@ -0,0 +1,81 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterAwaySwitchSpec defines the desired state of ClusterAwaySwitch
+// +kubebuilder:validation:XValidation:rule="!has(self.start) || !has(self.end) || timestamp(self.start) < timestamp(self.end)",message="start must be before end"
+// +kubebuilder:validation:XValidation:rule="(has(self.off) && self.off) != has(self.targetTemperature)",message="exactly one of off or targetTemperature must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.targetTemperature) || ((has(self.unit) && self.unit == 'fahrenheit') ? (double(self.targetTemperature) >= 34.0 && double(self.targetTemperature) <= 102.0) : (double(self.targetTemperature) >= 1.0 && double(self.targetTemperature) <= 39.0))",message="targetTemperature must be between 1 and 39 in celsius or 34 and 102 in fahrenheit"
+type ClusterAwaySwitchSpec struct {
+	// ThermoPilots, in every namespace, put into away mode. An empty selector
+	// selects every ThermoPilot
+	// +required
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// Time away mode starts. Away mode starts as soon as the switch is created when unset
+	// +optional
+	Start *metav1.Time `json:"start,omitempty"`
+	// Time normal control resumes. Away mode lasts until the switch is deleted when unset
+	// +optional
+	End *metav1.Time `json:"end,omitempty"`
+
+	// Unit of targetTemperature, converted to the unit of each ThermoPilot
+	// +kubebuilder:validation:Enum=celsius;fahrenheit
+	// +kubebuilder:default=celsius
+	// +optional
+	Unit string `json:"unit,omitempty"`
+
+	AwaySettings `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Start",type=string,JSONPath=`.spec.start`
+// +kubebuilder:printcolumn:name="End",type=string,JSONPath=`.spec.end`
+// +kubebuilder:printcolumn:name="Off",type=boolean,JSONPath=`.spec.off`
+// +kubebuilder:printcolumn:name="Target",type=string,JSONPath=`.spec.targetTemperature`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ClusterAwaySwitch puts every ThermoPilot matching its selector into away mode
+type ClusterAwaySwitch struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of ClusterAwaySwitch
+	// +required
+	Spec ClusterAwaySwitchSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterAwaySwitchList contains a list of ClusterAwaySwitch
+type ClusterAwaySwitchList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []ClusterAwaySwitch `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterAwaySwitch{}, &ClusterAwaySwitchList{})
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SwitchBotAccountSpec defines the desired state of SwitchBotAccount
+type SwitchBotAccountSpec struct {
+	// SwitchBot API credentials stored in a Secret in the same namespace
+	// +required
+	SecretRef SecretReference `json:"secretRef"`
+
+	// How often the device inventory is synced from the SwitchBot API
+	// +kubebuilder:default="1h"
+	// +optional
+	SyncInterval *metav1.Duration `json:"syncInterval,omitempty"`
+}
+
+// ClusterSwitchBotAccountSpec defines the desired state of ClusterSwitchBotAccount
+type ClusterSwitchBotAccountSpec struct {
+	// SwitchBot API credentials stored in a Secret
+	// +required
+	SecretRef NamespacedSecretReference `json:"secretRef"`
+
+	// Namespace in which SwitchBotDevice objects are created for this account.
+	// Devices are not materialized when empty
+	// +optional
+	DeviceNamespace string `json:"deviceNamespace,omitempty"`
+
+	// How often the device inventory is synced from the SwitchBot API
+	// +kubebuilder:default="1h"
+	// +optional
+	SyncInterval *metav1.Duration `json:"syncInterval,omitempty"`
+}
+
+// NamespacedSecretReference holds a reference to a Secret in an explicit namespace
+type NamespacedSecretReference struct {
+	SecretReference `json:",inline"`
+	// Namespace of the Secret
+	// +required
+	Namespace string `json:"namespace"`
+}
+
+// SwitchBotAccountStatus defines the observed state of a SwitchBot account.
+type SwitchBotAccountStatus struct {
+	// conditions represent the current state of the account.
+	//
+	// Condition types include:
+	// - "CredentialsValid": the SwitchBot API accepted the credentials
+	// - "Ready": the last inventory sync succeeded
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Number of physical devices in the account
+	// +optional
+	DeviceCount int32 `json:"deviceCount,omitempty"`
+	// Number of infrared remotes in the account
+	// +optional
+	InfraredRemoteCount int32 `json:"infraredRemoteCount,omitempty"`
+	// Time of the last successful inventory sync
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+	// API quota usage of the account
+	// +optional
+	Quota *QuotaStatus `json:"quota,omitempty"`
+}
+
+// QuotaStatus reports how much of the daily SwitchBot API quota has been used
+type QuotaStatus struct {
+	// Requests sent by this controller since the quota was last reset, as of the
+	// last sync. Restored from here after a controller restart
+	RequestsToday int64 `json:"requestsToday"`
+	// Maximum number of requests SwitchBot allows per day
+	DailyLimit int64 `json:"dailyLimit"`
+	// Time at which the quota resets
+	// +optional
+	ResetTime *metav1.Time `json:"resetTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Devices",type=integer,JSONPath=`.status.deviceCount`
+// +kubebuilder:printcolumn:name="Remotes",type=integer,JSONPath=`.status.infraredRemoteCount`
+// +kubebuilder:printcolumn:name="Requests",type=integer,JSONPath=`.status.quota.requestsToday`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// SwitchBotAccount is the Schema for the switchbotaccounts API
+type SwitchBotAccount struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of SwitchBotAccount
+	// +required
+	Spec SwitchBotAccountSpec `json:"spec"`
+
+	// status defines the observed state of SwitchBotAccount
+	// +optional
+	Status SwitchBotAccountStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// SwitchBotAccountList contains a list of SwitchBotAccount
+type SwitchBotAccountList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []SwitchBotAccount `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Devices",type=integer,JSONPath=`.status.deviceCount`
+// +kubebuilder:printcolumn:name="Remotes",type=integer,JSONPath=`.status.infraredRemoteCount`
+// +kubebuilder:printcolumn:name="Requests",type=integer,JSONPath=`.status.quota.requestsToday`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ClusterSwitchBotAccount is the Schema for the clusterswitchbotaccounts API
+type ClusterSwitchBotAccount struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of ClusterSwitchBotAccount
+	// +required
+	Spec ClusterSwitchBotAccountSpec `json:"spec"`
+
+	// status defines the observed state of ClusterSwitchBotAccount
+	// +optional
+	Status SwitchBotAccountStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterSwitchBotAccountList contains a list of ClusterSwitchBotAccount
+type ClusterSwitchBotAccountList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []ClusterSwitchBotAccount `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SwitchBotAccount{}, &SwitchBotAccountList{})
+	SchemeBuilder.Register(&ClusterSwitchBotAccount{}, &ClusterSwitchBotAccountList{})
+}
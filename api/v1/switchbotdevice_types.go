@@ -0,0 +1,103 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// SwitchBotDeviceSpec mirrors an entry of the SwitchBot device inventory.
+// It is managed by the account controller and overwritten on every sync.
+type SwitchBotDeviceSpec struct {
+	// Account the device was discovered in
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="accountRef is immutable"
+	// +required
+	AccountRef AccountReference `json:"accountRef"`
+	// SwitchBot device ID
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="deviceId is immutable"
+	// +required
+	DeviceID string `json:"deviceId"`
+	// Name of the device in the SwitchBot app
+	// +optional
+	DeviceName string `json:"deviceName,omitempty"`
+	// Device type, or remote type for infrared remotes (e.g., MeterPro, Air Conditioner)
+	// +optional
+	DeviceType string `json:"deviceType,omitempty"`
+	// Whether the device is an infrared remote controlled through a hub
+	// +optional
+	Infrared bool `json:"infrared,omitempty"`
+	// ID of the hub the device is connected to
+	// +optional
+	HubDeviceID string `json:"hubDeviceId,omitempty"`
+	// Whether cloud service is enabled for the device
+	// +optional
+	EnableCloudService bool `json:"enableCloudService,omitempty"`
+}
+
+// SwitchBotDeviceStatus defines the observed state of SwitchBotDevice.
+type SwitchBotDeviceStatus struct {
+	// Time the device was last present in the account inventory
+	// +optional
+	LastSeenTime *metav1.Time `json:"lastSeenTime,omitempty"`
+	// Last status body reported by the device. Infrared remotes do not report a status
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +optional
+	DeviceStatus *runtime.RawExtension `json:"deviceStatus,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=sbdev
+// +kubebuilder:printcolumn:name="Device ID",type=string,JSONPath=`.spec.deviceId`
+// +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.deviceType`
+// +kubebuilder:printcolumn:name="Device Name",type=string,JSONPath=`.spec.deviceName`
+// +kubebuilder:printcolumn:name="Hub",type=string,JSONPath=`.spec.hubDeviceId`,priority=1
+// +kubebuilder:printcolumn:name="Cloud",type=boolean,JSONPath=`.spec.enableCloudService`,priority=1
+// +kubebuilder:printcolumn:name="Last Seen",type=date,JSONPath=`.status.lastSeenTime`
+
+// SwitchBotDevice is a read-only mirror of a device registered in a SwitchBot account
+type SwitchBotDevice struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec mirrors the device inventory entry
+	// +required
+	Spec SwitchBotDeviceSpec `json:"spec"`
+
+	// status defines the observed state of SwitchBotDevice
+	// +optional
+	Status SwitchBotDeviceStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// SwitchBotDeviceList contains a list of SwitchBotDevice
+type SwitchBotDeviceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []SwitchBotDevice `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SwitchBotDevice{}, &SwitchBotDeviceList{})
+}
@@ -24,6 +24,20 @@ import (
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // ThermoPilotSpec defines the desired state of ThermoPilot
+// +kubebuilder:validation:XValidation:rule="has(self.secretRef) != has(self.accountRef)",message="exactly one of secretRef or accountRef must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.upperThreshold) || double(self.upperThreshold) > 0.0",message="upperThreshold must be greater than 0"
+// +kubebuilder:validation:XValidation:rule="!has(self.lowerThreshold) || double(self.lowerThreshold) > 0.0",message="lowerThreshold must be greater than 0"
+// +kubebuilder:validation:XValidation:rule="!has(self.aggressiveOffset) || !has(self.aggressiveOffset.cool) || double(self.aggressiveOffset.cool) >= double(has(self.lowerThreshold) ? self.lowerThreshold : (has(self.threshold) ? self.threshold : '1.0'))",message="aggressiveOffset.cool must be at least the lower threshold"
+// +kubebuilder:validation:XValidation:rule="!has(self.aggressiveOffset) || !has(self.aggressiveOffset.heat) || double(self.aggressiveOffset.heat) >= double(has(self.upperThreshold) ? self.upperThreshold : (has(self.threshold) ? self.threshold : '1.0'))",message="aggressiveOffset.heat must be at least the upper threshold"
+// +kubebuilder:validation:XValidation:rule="double(self.targetTemperature) >= ((has(self.unit) && self.unit == 'fahrenheit') ? 34.0 : 1.0) && double(self.targetTemperature) <= ((has(self.unit) && self.unit == 'fahrenheit') ? 102.0 : 39.0)",message="targetTemperature must be between 1 and 39 in celsius or 34 and 102 in fahrenheit"
+// +kubebuilder:validation:XValidation:rule="!has(self.override) || !has(self.override.targetTemperature) || (double(self.override.targetTemperature) >= ((has(self.unit) && self.unit == 'fahrenheit') ? 34.0 : 1.0) && double(self.override.targetTemperature) <= ((has(self.unit) && self.unit == 'fahrenheit') ? 102.0 : 39.0))",message="override.targetTemperature must be between 1 and 39 in celsius or 34 and 102 in fahrenheit"
+// +kubebuilder:validation:XValidation:rule="!has(self.onDelete) || !has(self.onDelete.fallbackTemperature) || (double(self.onDelete.fallbackTemperature) >= ((has(self.unit) && self.unit == 'fahrenheit') ? 34.0 : 1.0) && double(self.onDelete.fallbackTemperature) <= ((has(self.unit) && self.unit == 'fahrenheit') ? 102.0 : 39.0))",message="onDelete.fallbackTemperature must be between 1 and 39 in celsius or 34 and 102 in fahrenheit"
+// +kubebuilder:validation:XValidation:rule="!has(self.sensorFailurePolicy) || !has(self.sensorFailurePolicy.safeTemperature) || (double(self.sensorFailurePolicy.safeTemperature) >= ((has(self.unit) && self.unit == 'fahrenheit') ? 34.0 : 1.0) && double(self.sensorFailurePolicy.safeTemperature) <= ((has(self.unit) && self.unit == 'fahrenheit') ? 102.0 : 39.0))",message="sensorFailurePolicy.safeTemperature must be between 1 and 39 in celsius or 34 and 102 in fahrenheit"
+// +kubebuilder:validation:XValidation:rule="!has(self.safety) || ((!has(self.safety.minTemperature) || (double(self.safety.minTemperature) >= ((has(self.unit) && self.unit == 'fahrenheit') ? 34.0 : 1.0) && double(self.safety.minTemperature) <= ((has(self.unit) && self.unit == 'fahrenheit') ? 102.0 : 39.0))) && (!has(self.safety.maxTemperature) || (double(self.safety.maxTemperature) >= ((has(self.unit) && self.unit == 'fahrenheit') ? 34.0 : 1.0) && double(self.safety.maxTemperature) <= ((has(self.unit) && self.unit == 'fahrenheit') ? 102.0 : 39.0))) && (!has(self.safety.heatSetpoint) || (double(self.safety.heatSetpoint) >= ((has(self.unit) && self.unit == 'fahrenheit') ? 34.0 : 1.0) && double(self.safety.heatSetpoint) <= ((has(self.unit) && self.unit == 'fahrenheit') ? 102.0 : 39.0))) && (!has(self.safety.coolSetpoint) || (double(self.safety.coolSetpoint) >= ((has(self.unit) && self.unit == 'fahrenheit') ? 34.0 : 1.0) && double(self.safety.coolSetpoint) <= ((has(self.unit) && self.unit == 'fahrenheit') ? 102.0 : 39.0))))",message="safety temperatures must be between 1 and 39 in celsius or 34 and 102 in fahrenheit"
+// +kubebuilder:validation:XValidation:rule="(!has(self.threshold) || double(self.threshold) <= ((has(self.unit) && self.unit == 'fahrenheit') ? 9.0 : 5.0)) && (!has(self.upperThreshold) || double(self.upperThreshold) <= ((has(self.unit) && self.unit == 'fahrenheit') ? 9.0 : 5.0)) && (!has(self.lowerThreshold) || double(self.lowerThreshold) <= ((has(self.unit) && self.unit == 'fahrenheit') ? 9.0 : 5.0))",message="thresholds must be at most 5 in celsius or 9 in fahrenheit"
+// +kubebuilder:validation:XValidation:rule="!has(self.aggressiveOffset) || ((!has(self.aggressiveOffset.cool) || double(self.aggressiveOffset.cool) <= ((has(self.unit) && self.unit == 'fahrenheit') ? 18.0 : 10.0)) && (!has(self.aggressiveOffset.heat) || double(self.aggressiveOffset.heat) <= ((has(self.unit) && self.unit == 'fahrenheit') ? 18.0 : 10.0)))",message="aggressive offsets must be at most 10 in celsius or 18 in fahrenheit"
+// +kubebuilder:validation:XValidation:rule="!has(self.away) || self.away.all(a, !has(a.targetTemperature) || (double(a.targetTemperature) >= ((has(self.unit) && self.unit == 'fahrenheit') ? 34.0 : 1.0) && double(a.targetTemperature) <= ((has(self.unit) && self.unit == 'fahrenheit') ? 102.0 : 39.0)))",message="away targetTemperature must be between 1 and 39 in celsius or 34 and 102 in fahrenheit"
+// +kubebuilder:validation:XValidation:rule="!has(self.occupancy) || ((!has(self.occupancy.comfortTemperature) || (double(self.occupancy.comfortTemperature) >= ((has(self.unit) && self.unit == 'fahrenheit') ? 34.0 : 1.0) && double(self.occupancy.comfortTemperature) <= ((has(self.unit) && self.unit == 'fahrenheit') ? 102.0 : 39.0))) && double(self.occupancy.setbackTemperature) >= ((has(self.unit) && self.unit == 'fahrenheit') ? 34.0 : 1.0) && double(self.occupancy.setbackTemperature) <= ((has(self.unit) && self.unit == 'fahrenheit') ? 102.0 : 39.0))",message="occupancy temperatures must be between 1 and 39 in celsius or 34 and 102 in fahrenheit"
 type ThermoPilotSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
@@ -31,31 +45,517 @@ type ThermoPilotSpec struct {
 	// More info: https://book.kubebuilder.io/reference/markers/crd-validation.html
 
 	// SwitchBot API credentials stored in a Secret
-	// +required
-	SecretRef SecretReference `json:"secretRef"`
+	// +optional
+	SecretRef SecretReference `json:"secretRef,omitzero"`
+
+	// SwitchBot account providing the API credentials, shared with other ThermoPilots
+	// +optional
+	AccountRef *AccountReference `json:"accountRef,omitempty"`
 
 	// Device IDs for controlling temperature
 	// +optional
 	AirConditionerID string `json:"airConditionerId,omitempty"`
 
+	// Name of a SwitchBotDevice in the same namespace to use as the air conditioner.
+	// The device must be mirrored by the account in accountRef.
+	// Takes precedence over airConditionerId
+	// +optional
+	AirConditionerRef string `json:"airConditionerRef,omitempty"`
+
+	// Name of a SwitchBotDevice in the same namespace to use as the temperature sensor.
+	// The device must be mirrored by the account in accountRef.
+	// When omitted, the first sensor of temperatureSensorType in the account is used
+	// +optional
+	TemperatureSensorRef string `json:"temperatureSensorRef,omitempty"`
+
 	// Type of temperature sensor to use (e.g., MeterPro)
 	// +kubebuilder:validation:Enum=MeterPro
 	// +required
 	TemperatureSensorType string `json:"temperatureSensorType"`
 
+	// Unit of every temperature in the spec and the status. The SwitchBot API works
+	// in Celsius, so readings and setpoints are converted when talking to it
+	// +kubebuilder:validation:Enum=celsius;fahrenheit
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="unit is immutable"
+	// +kubebuilder:default=celsius
+	// +optional
+	Unit string `json:"unit,omitempty"`
+
 	// Temperature control settings
-	// +kubebuilder:validation:Pattern=^([1-3][0-9]|[1-9])(\.[0-9])?$
+	// +kubebuilder:validation:Pattern=`^[0-9]{1,3}(\.[0-9])?$`
 	// +required
 	TargetTemperature string `json:"targetTemperature"`
-	// +kubebuilder:validation:Pattern=^[0-5](\.[0-9])?$
+	// +kubebuilder:validation:Pattern=^[0-9](\.[0-9])?$
 	// +kubebuilder:default="1.0"
 	// +optional
 	Threshold string `json:"threshold,omitempty"`
+	// Band above the target before the room counts as too warm. Defaults to threshold
+	// +kubebuilder:validation:Pattern=^[0-9](\.[0-9])?$
+	// +optional
+	UpperThreshold string `json:"upperThreshold,omitempty"`
+	// Band below the target before the room counts as too cold. Defaults to threshold
+	// +kubebuilder:validation:Pattern=^[0-9](\.[0-9])?$
+	// +optional
+	LowerThreshold string `json:"lowerThreshold,omitempty"`
+	// How far beyond the target the setpoint is driven when the room overshoots in
+	// the direction opposite to the mode
+	// +optional
+	AggressiveOffset *AggressiveOffset `json:"aggressiveOffset,omitempty"`
 
 	// Air conditioner mode: cool or heat
 	// +kubebuilder:validation:Enum=cool;heat
 	// +required
 	Mode string `json:"mode"`
+
+	// Suspend stops sending commands to the air conditioners while readings keep being updated
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// DryRun computes decisions and records the would-be commands in status and events
+	// without sending any command to the air conditioners
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// Temporary manual override that takes precedence over targetTemperature and mode until it expires
+	// +optional
+	Override *Override `json:"override,omitempty"`
+
+	// Periods the room is left empty, during which the away setpoint or mode applies
+	// instead of targetTemperature and mode. Normal control resumes once they end
+	// +kubebuilder:validation:MaxItems=20
+	// +optional
+	Away []AwayPeriod `json:"away,omitempty"`
+
+	// What to do with the controlled air conditioners when the ThermoPilot is deleted
+	// +optional
+	OnDelete *OnDeletePolicy `json:"onDelete,omitempty"`
+
+	// How control continues when the temperature sensor cannot be read. Without a
+	// policy a failed reading fails the reconcile and the air conditioners keep their state
+	// +optional
+	SensorFailurePolicy *SensorFailurePolicy `json:"sensorFailurePolicy,omitempty"`
+
+	// Capabilities of the air conditioners. Every command is fitted to the profile
+	// of its air conditioner: clamped to the setpoint range of the mode, quantized
+	// to the step and sent with a supported fan speed
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=10
+	// +optional
+	CapabilityProfiles []CapabilityProfile `json:"capabilityProfiles,omitempty"`
+
+	// Calibration offsets of the sensors and air conditioners
+	// +optional
+	Calibration *Calibration `json:"calibration,omitempty"`
+
+	// Absolute temperature limits enforced before every other policy
+	// +optional
+	Safety *SafetyLimits `json:"safety,omitempty"`
+
+	// Moves the effective target gradually towards a new target instead of jumping
+	// +optional
+	Ramp *Ramp `json:"ramp,omitempty"`
+
+	// Offsets applied to the target relative to bedtime every night
+	// +optional
+	SleepCurve *SleepCurve `json:"sleepCurve,omitempty"`
+
+	// Comfort and setback targets following an iCalendar feed, such as the booking
+	// calendar of a meeting room. Overrides and away periods take precedence
+	// +optional
+	Occupancy *OccupancySchedule `json:"occupancy,omitempty"`
+
+	// Limits on how often the air conditioners are started, stopped and switched
+	// between modes
+	// +optional
+	EquipmentProtection *EquipmentProtection `json:"equipmentProtection,omitempty"`
+
+	// Thresholds for reporting the temperature sensor as unhealthy
+	// +optional
+	SensorHealth *SensorHealthPolicy `json:"sensorHealth,omitempty"`
+
+	// Filtering applied to the sensor readings before they are used for decisions
+	// +optional
+	Filter *ReadingFilter `json:"filter,omitempty"`
+}
+
+// CapabilityProfile describes what an air conditioner model accepts
+type CapabilityProfile struct {
+	// Name of the profile
+	// +required
+	Name string `json:"name"`
+	// Device IDs of the air conditioners the profile applies to. A profile without
+	// devices applies to every air conditioner not listed in another profile
+	// +optional
+	Devices []string `json:"devices,omitempty"`
+	// Setpoint range accepted in cool mode, in Celsius whatever the unit. Defaults to 16-30
+	// +optional
+	Cool *SetpointRange `json:"cool,omitempty"`
+	// Setpoint range accepted in heat mode, in Celsius whatever the unit. Defaults to 16-30
+	// +optional
+	Heat *SetpointRange `json:"heat,omitempty"`
+	// Setpoint increment accepted by the air conditioner, in Celsius whatever the unit
+	// +kubebuilder:validation:Enum="0.5";"1"
+	// +kubebuilder:default="1"
+	// +optional
+	Step string `json:"step,omitempty"`
+	// Modes supported by the air conditioner. Empty means every mode
+	// +listType=set
+	// +optional
+	Modes []ProfileMode `json:"modes,omitempty"`
+	// Fan speeds supported by the air conditioner. Commands use auto when it is
+	// supported, the first listed speed otherwise. Empty means every speed
+	// +listType=set
+	// +optional
+	FanSpeeds []FanSpeed `json:"fanSpeeds,omitempty"`
+}
+
+// Calibration corrects sensors that read off and air conditioners that do not hold
+// their setpoint
+type Calibration struct {
+	// Offsets added to the readings of sensors before they are used for decisions
+	// +listType=map
+	// +listMapKey=deviceId
+	// +kubebuilder:validation:MaxItems=10
+	// +optional
+	Sensors []CalibrationOffset `json:"sensors,omitempty"`
+	// Offsets added to the setpoints sent to air conditioners
+	// +listType=map
+	// +listMapKey=deviceId
+	// +kubebuilder:validation:MaxItems=10
+	// +optional
+	AirConditioners []CalibrationOffset `json:"airConditioners,omitempty"`
+}
+
+// CalibrationOffset is the correction applied to a single device
+type CalibrationOffset struct {
+	// Device ID of the sensor or air conditioner
+	// +required
+	DeviceID string `json:"deviceId"`
+	// Degrees added to the value, such as "-1.2" for a sensor reading 1.2°C high
+	// or "2.0" for an air conditioner undershooting its setpoint by 2°C
+	// +kubebuilder:validation:Pattern=^-?[0-9](\.[0-9])?$
+	// +required
+	Offset string `json:"offset"`
+}
+
+// Ramp limits how fast the effective target moves towards a new target
+// +kubebuilder:validation:XValidation:rule="double(self.rate) > 0.0",message="rate must be greater than 0"
+// +kubebuilder:validation:XValidation:rule="!has(self.step) || double(self.step) > 0.0",message="step must be greater than 0"
+type Ramp struct {
+	// Degrees per hour the effective target moves by
+	// +kubebuilder:validation:Pattern=`^[0-9]{1,2}(\.[0-9])?$`
+	// +required
+	Rate string `json:"rate"`
+	// Degrees the effective target moves by at once. Reconciles are scheduled when
+	// the next step is due
+	// +kubebuilder:validation:Pattern=^[0-9](\.[0-9])?$
+	// +kubebuilder:default="0.5"
+	// +optional
+	Step string `json:"step,omitempty"`
+}
+
+// SleepCurve shifts the target by offsets relative to bedtime, such as letting a
+// bedroom warm up slightly during the night
+type SleepCurve struct {
+	// Time the curve starts every day, as HH:MM
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	// +required
+	Bedtime string `json:"bedtime"`
+	// IANA time zone bedtime is in
+	// +kubebuilder:default="UTC"
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+	// How long after bedtime the curve ends and the target returns to normal
+	// +required
+	Duration metav1.Duration `json:"duration"`
+	// Offsets of the curve. Each applies from its time after bedtime until the next
+	// point or the end of the curve
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=24
+	// +required
+	Points []SleepCurvePoint `json:"points"`
+}
+
+// SleepCurvePoint is an offset of the sleep curve
+type SleepCurvePoint struct {
+	// Time after bedtime the offset applies from
+	// +required
+	After metav1.Duration `json:"after"`
+	// Degrees added to the target, such as "1.0" or "-0.5"
+	// +kubebuilder:validation:Pattern=^-?[0-9](\.[0-9])?$
+	// +required
+	Offset string `json:"offset"`
+}
+
+// OccupancySchedule controls towards a comfort target while an event of a calendar
+// is in progress and towards a setback target otherwise
+type OccupancySchedule struct {
+	// ConfigMap key holding the iCalendar (ICS) feed. Busy events, recurring ones
+	// included, make the room occupied; cancelled and free events do not
+	// +required
+	CalendarRef ConfigMapKeyReference `json:"calendarRef"`
+	// Target while occupied, targetTemperature when unset
+	// +kubebuilder:validation:Pattern=`^[0-9]{1,3}(\.[0-9])?$`
+	// +optional
+	ComfortTemperature string `json:"comfortTemperature,omitempty"`
+	// Target while unoccupied
+	// +kubebuilder:validation:Pattern=`^[0-9]{1,3}(\.[0-9])?$`
+	// +required
+	SetbackTemperature string `json:"setbackTemperature"`
+	// How long before an event the comfort target applies, so that the room is
+	// comfortable when it starts
+	// +optional
+	PreConditioning *metav1.Duration `json:"preConditioning,omitempty"`
+	// IANA time zone of the times of the feed without one, and of all-day events
+	// +kubebuilder:default="UTC"
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// ConfigMapKeyReference references a key of a ConfigMap
+type ConfigMapKeyReference struct {
+	// Name of the ConfigMap in the same namespace
+	// +required
+	Name string `json:"name"`
+	// Key holding the feed
+	// +kubebuilder:default="calendar.ics"
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// ProfileMode is an air conditioner mode
+// +kubebuilder:validation:Enum=auto;cool;dry;fan;heat
+type ProfileMode string
+
+// FanSpeed is an air conditioner fan speed
+// +kubebuilder:validation:Enum=auto;low;medium;high
+type FanSpeed string
+
+// SetpointRange is the range of setpoints accepted in a mode, in Celsius whatever
+// the unit of the spec
+// +kubebuilder:validation:XValidation:rule="double(self.min) <= double(self.max)",message="min must not be above max"
+// +kubebuilder:validation:XValidation:rule="double(self.min) >= 1.0 && double(self.max) <= 39.0",message="setpoint range must be between 1 and 39 in celsius"
+type SetpointRange struct {
+	// +kubebuilder:validation:Pattern=`^[0-9]{1,3}(\.[0-9])?$`
+	// +required
+	Min string `json:"min"`
+	// +kubebuilder:validation:Pattern=`^[0-9]{1,3}(\.[0-9])?$`
+	// +required
+	Max string `json:"max"`
+}
+
+// AggressiveOffset sets, per mode, how far beyond the target the setpoint is
+// driven to let an overshooting room recover. It must be at least the threshold
+// on the side it corrects.
+// +kubebuilder:validation:XValidation:rule="!has(self.cool) || double(self.cool) > 0.0",message="cool offset must be greater than 0"
+// +kubebuilder:validation:XValidation:rule="!has(self.heat) || double(self.heat) > 0.0",message="heat offset must be greater than 0"
+type AggressiveOffset struct {
+	// Added to the target in cool mode when the room is too cold. Defaults to 3.0
+	// +kubebuilder:validation:Pattern=`^[0-9]{1,2}(\.[0-9])?$`
+	// +optional
+	Cool string `json:"cool,omitempty"`
+	// Subtracted from the target in heat mode when the room is too warm. Defaults to 3.0
+	// +kubebuilder:validation:Pattern=`^[0-9]{1,2}(\.[0-9])?$`
+	// +optional
+	Heat string `json:"heat,omitempty"`
+}
+
+// SafetyLimits protect the room when the configuration or the schedule would let it
+// get too cold or too hot. Outside the limits the air conditioners heat or cool
+// regardless of mode, override or suspend.
+// +kubebuilder:validation:XValidation:rule="has(self.minTemperature) || has(self.maxTemperature)",message="safety must set minTemperature or maxTemperature"
+// +kubebuilder:validation:XValidation:rule="!has(self.minTemperature) || !has(self.maxTemperature) || double(self.minTemperature) < double(self.maxTemperature)",message="minTemperature must be below maxTemperature"
+type SafetyLimits struct {
+	// Below this temperature the air conditioners heat (freeze protection)
+	// +kubebuilder:validation:Pattern=`^[0-9]{1,3}(\.[0-9])?$`
+	// +optional
+	MinTemperature string `json:"minTemperature,omitempty"`
+	// Above this temperature the air conditioners cool (overheat guard)
+	// +kubebuilder:validation:Pattern=`^[0-9]{1,3}(\.[0-9])?$`
+	// +optional
+	MaxTemperature string `json:"maxTemperature,omitempty"`
+	// Setpoint sent while below minTemperature. Defaults to minTemperature plus 3 degrees
+	// +kubebuilder:validation:Pattern=`^[0-9]{1,3}(\.[0-9])?$`
+	// +optional
+	HeatSetpoint string `json:"heatSetpoint,omitempty"`
+	// Setpoint sent while above maxTemperature. Defaults to maxTemperature minus 3 degrees
+	// +kubebuilder:validation:Pattern=`^[0-9]{1,3}(\.[0-9])?$`
+	// +optional
+	CoolSetpoint string `json:"coolSetpoint,omitempty"`
+}
+
+// EquipmentProtection keeps the compressors from short cycling. A command that
+// would break a limit is withheld until the limit allows it; safety limits are
+// enforced regardless.
+type EquipmentProtection struct {
+	// Minimum time the compressor runs once started before it is stopped or driven
+	// away from the target
+	// +optional
+	MinOnTime *metav1.Duration `json:"minOnTime,omitempty"`
+	// Minimum time the compressor rests once stopped before it is started again
+	// +optional
+	MinOffTime *metav1.Duration `json:"minOffTime,omitempty"`
+	// Maximum number of compressor starts within any hour
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxStartsPerHour int32 `json:"maxStartsPerHour,omitempty"`
+	// Minimum time between two changes of mode
+	// +optional
+	MinModeChangeInterval *metav1.Duration `json:"minModeChangeInterval,omitempty"`
+}
+
+// SensorHealthPolicy sets when a sensor is reported as running low on battery or frozen
+type SensorHealthPolicy struct {
+	// Battery level, in percent, at or below which the sensor is reported as low on battery.
+	// 0 disables the check
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default=20
+	// +optional
+	LowBattery *int32 `json:"lowBattery,omitempty"`
+	// How long a sensor may report the same temperature and humidity before it is
+	// reported as frozen. 0 disables the check
+	// +kubebuilder:default="3h"
+	// +optional
+	FrozenAfter *metav1.Duration `json:"frozenAfter,omitempty"`
+}
+
+// ReadingFilter smooths the sensor readings and rejects implausible jumps
+type ReadingFilter struct {
+	// Smoothing applied to the readings: none, ema (exponential moving average)
+	// or median (median of the last readings)
+	// +kubebuilder:validation:Enum=none;ema;median
+	// +kubebuilder:default=none
+	// +optional
+	Smoothing string `json:"smoothing,omitempty"`
+	// Weight of the newest reading in the exponential moving average, between 0 and 1
+	// +kubebuilder:validation:Pattern=^(0(\.[0-9]+)?|1(\.0+)?)$
+	// +kubebuilder:default="0.5"
+	// +optional
+	Alpha string `json:"alpha,omitempty"`
+	// Number of readings the median is taken over
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=15
+	// +kubebuilder:default=5
+	// +optional
+	Window int32 `json:"window,omitempty"`
+	// Readings further than this from the filtered value, in degrees, are rejected as
+	// outliers unless the next reading confirms them. Unset disables outlier rejection
+	// +kubebuilder:validation:Pattern=^[0-9]+(\.[0-9])?$
+	// +optional
+	MaxJump string `json:"maxJump,omitempty"`
+}
+
+// SensorFailurePolicy describes the fallback sensors and what to do once no reading is available
+// +kubebuilder:validation:XValidation:rule="self.safeAction != 'safeSetpoint' || (has(self.safeTemperature) && has(self.safeMode))",message="safeTemperature and safeMode are required when safeAction is safeSetpoint"
+type SensorFailurePolicy struct {
+	// Sensors tried in order when the primary sensor cannot be read
+	// +kubebuilder:validation:MaxItems=5
+	// +optional
+	FallbackSensors []SensorSource `json:"fallbackSensors,omitempty"`
+	// How long the last known reading keeps being used once no sensor can be read
+	// +kubebuilder:default="10m"
+	// +optional
+	HoldLastReading *metav1.Duration `json:"holdLastReading,omitempty"`
+	// Action once the last known reading is stale: keep the air conditioners as they are,
+	// turn them off, or send the safe setpoint
+	// +kubebuilder:validation:Enum=keep;off;safeSetpoint
+	// +kubebuilder:default=keep
+	// +optional
+	SafeAction string `json:"safeAction,omitempty"`
+	// Setpoint sent when safeAction is safeSetpoint
+	// +kubebuilder:validation:Pattern=`^[0-9]{1,3}(\.[0-9])?$`
+	// +optional
+	SafeTemperature string `json:"safeTemperature,omitempty"`
+	// Mode sent when safeAction is safeSetpoint
+	// +kubebuilder:validation:Enum=cool;heat
+	// +optional
+	SafeMode string `json:"safeMode,omitempty"`
+}
+
+// SensorSource identifies a temperature sensor
+// +kubebuilder:validation:XValidation:rule="has(self.deviceRef) != has(self.deviceId)",message="exactly one of deviceRef or deviceId must be set"
+type SensorSource struct {
+	// Name of a SwitchBotDevice in the same namespace, mirrored by the account in accountRef
+	// +optional
+	DeviceRef string `json:"deviceRef,omitempty"`
+	// SwitchBot device ID of the sensor
+	// +optional
+	DeviceID string `json:"deviceId,omitempty"`
+}
+
+// Override temporarily replaces the target temperature and mode
+// +kubebuilder:validation:XValidation:rule="has(self.targetTemperature) || has(self.mode)",message="override must set targetTemperature or mode"
+type Override struct {
+	// Target temperature while the override is active
+	// +kubebuilder:validation:Pattern=`^[0-9]{1,3}(\.[0-9])?$`
+	// +optional
+	TargetTemperature string `json:"targetTemperature,omitempty"`
+	// Mode while the override is active. Defaults to spec.mode
+	// +kubebuilder:validation:Enum=cool;heat
+	// +optional
+	Mode string `json:"mode,omitempty"`
+	// Time at which the override expires and normal control resumes
+	// +required
+	ExpiresAt metav1.Time `json:"expiresAt"`
+}
+
+// AwayPeriod is a time range during which the room is left empty
+// +kubebuilder:validation:XValidation:rule="timestamp(self.start) < timestamp(self.end)",message="start must be before end"
+// +kubebuilder:validation:XValidation:rule="(has(self.off) && self.off) != has(self.targetTemperature)",message="exactly one of off or targetTemperature must be set"
+type AwayPeriod struct {
+	// Time the room is left
+	// +required
+	Start metav1.Time `json:"start"`
+	// Time normal control resumes
+	// +required
+	End metav1.Time `json:"end"`
+
+	AwaySettings `json:",inline"`
+}
+
+// AwaySettings describes what the air conditioners do while away
+type AwaySettings struct {
+	// Turn the air conditioners off while away
+	// +optional
+	Off bool `json:"off,omitempty"`
+	// Target temperature while away
+	// +kubebuilder:validation:Pattern=`^[0-9]{1,3}(\.[0-9])?$`
+	// +optional
+	TargetTemperature string `json:"targetTemperature,omitempty"`
+	// Mode while away. Defaults to spec.mode
+	// +kubebuilder:validation:Enum=cool;heat
+	// +optional
+	Mode string `json:"mode,omitempty"`
+}
+
+// OnDeletePolicy describes how air conditioners are left when a ThermoPilot is deleted
+// +kubebuilder:validation:XValidation:rule="self.action != 'fallback' || (has(self.fallbackTemperature) && has(self.fallbackMode))",message="fallbackTemperature and fallbackMode are required when action is fallback"
+type OnDeletePolicy struct {
+	// Action to perform: leave the AC as-is, turn it off, or send the fallback setpoint
+	// +kubebuilder:validation:Enum=leave;off;fallback
+	// +kubebuilder:default=leave
+	// +optional
+	Action string `json:"action,omitempty"`
+	// Setpoint sent when action is fallback
+	// +kubebuilder:validation:Pattern=`^[0-9]{1,3}(\.[0-9])?$`
+	// +optional
+	FallbackTemperature string `json:"fallbackTemperature,omitempty"`
+	// Mode sent when action is fallback
+	// +kubebuilder:validation:Enum=cool;heat
+	// +optional
+	FallbackMode string `json:"fallbackMode,omitempty"`
+	// Number of retries per air conditioner before giving up
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	// +kubebuilder:default=3
+	// +optional
+	MaxRetries int32 `json:"maxRetries,omitempty"`
+	// Maximum time spent on the delete action before the finalizer is removed anyway
+	// +kubebuilder:default="2m"
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
 }
 
 // SecretReference holds a reference to a Secret containing SwitchBot API credentials
@@ -73,6 +573,18 @@ type SecretReference struct {
 	SecretKey string `json:"secretKey,omitempty"`
 }
 
+// AccountReference holds a reference to a SwitchBotAccount or ClusterSwitchBotAccount
+type AccountReference struct {
+	// Kind of the account
+	// +kubebuilder:validation:Enum=SwitchBotAccount;ClusterSwitchBotAccount
+	// +kubebuilder:default=SwitchBotAccount
+	// +optional
+	Kind string `json:"kind,omitempty"`
+	// Name of the account. SwitchBotAccounts are looked up in the same namespace
+	// +required
+	Name string `json:"name"`
+}
+
 // ThermoPilotStatus defines the observed state of ThermoPilot.
 type ThermoPilotStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
@@ -84,22 +596,290 @@ type ThermoPilotStatus struct {
 	// conditions represent the current state of the ThermoPilot resource.
 	// Each condition has a unique type and reflects the status of a specific aspect of the resource.
 	//
-	// Standard condition types include:
-	// - "Available": the resource is fully functional
-	// - "Progressing": the resource is being created or updated
-	// - "Degraded": the resource failed to reach or maintain its desired state
+	// Condition types:
+	// - "Ready": the last reconcile read the temperature and controlled the air conditioners without error
+	// - "SensorHealthy": the temperature sensor could be read
+	// - "ActuatorsHealthy": the air conditioners accepted the commands sent to them
+	// - "CredentialsValid": the SwitchBot credentials were resolved and accepted
+	// - "Progressing": the room is being driven towards the target temperature
 	//
 	// The status of each condition is one of True, False, or Unknown.
 	// +listType=map
 	// +listMapKey=type
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Generation of the spec the status was computed for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +optional
+	CurrentTemperature string `json:"currentTemperature,omitempty"`
+	// Relative humidity in percent measured by the temperature sensor
+	// +optional
+	CurrentHumidity *int32 `json:"currentHumidity,omitempty"`
+	// Latest decision made by the controller and the reasons behind it
+	// +optional
+	LastDecision *Decision `json:"lastDecision,omitempty"`
+	// Last command sent, or that would have been sent in dry-run mode
+	// +optional
+	LastCommand *CommandStatus `json:"lastCommand,omitempty"`
+	// Override currently in effect, if any
+	// +optional
+	ActiveOverride *OverrideStatus `json:"activeOverride,omitempty"`
+	// Away period in effect, if any
+	// +optional
+	Away *AwayStatus `json:"away,omitempty"`
+	// Target in force at the last reconcile, once the ramp and the sleep curve apply
+	// +optional
+	EffectiveTarget *EffectiveTargetStatus `json:"effectiveTarget,omitempty"`
+	// Occupancy according to the calendar at the last reconcile
+	// +optional
+	Occupancy *OccupancyStatus `json:"occupancy,omitempty"`
+	// Source of the reading the last decision was based on
+	// +optional
+	Sensor *SensorStatus `json:"sensor,omitempty"`
+	// State of the reading filter, kept so that it survives restarts
+	// +optional
+	Filter *FilterStatus `json:"filter,omitempty"`
+	// Compressor cycles tracked for equipment protection
+	// +optional
+	Equipment *EquipmentStatus `json:"equipment,omitempty"`
+}
+
+// EquipmentStatus records the compressor cycles equipment protection is enforced on
+type EquipmentStatus struct {
+	// Whether the last command drives the compressor towards a setpoint, as opposed
+	// to turning the air conditioners off or driving them away from the target
+	// +optional
+	Running bool `json:"running,omitempty"`
+	// Mode of the last command
+	// +optional
+	Mode string `json:"mode,omitempty"`
+	// Time the compressor was last started
+	// +optional
+	LastStart *metav1.Time `json:"lastStart,omitempty"`
+	// Time the compressor was last stopped
+	// +optional
+	LastStop *metav1.Time `json:"lastStop,omitempty"`
+	// Time the mode last changed
+	// +optional
+	LastModeChange *metav1.Time `json:"lastModeChange,omitempty"`
+	// Compressor starts within the last hour
+	// +optional
+	Starts []metav1.Time `json:"starts,omitempty"`
+}
+
+// FilterStatus records the filter input and output and the filter window
+type FilterStatus struct {
+	// Latest reading fed to the filter, after calibration. The reading as reported
+	// by the sensor is status.sensor.reportedTemperature
+	// +optional
+	RawTemperature string `json:"rawTemperature,omitempty"`
+	// Value the latest decision was based on after filtering
+	// +optional
+	FilteredTemperature string `json:"filteredTemperature,omitempty"`
+	// Latest accepted readings fed to the filter, oldest first
+	// +optional
+	Window []string `json:"window,omitempty"`
+	// Whether the latest reading was rejected as an outlier
+	// +optional
+	Rejected bool `json:"rejected,omitempty"`
+}
+
+// SensorStatus describes where the reading in use came from and how stale it is
+type SensorStatus struct {
+	// Source of the reading: primary, fallback, held (the last known reading) or none
+	Source string `json:"source"`
+	// Device the reading came from
+	// +optional
+	DeviceID string `json:"deviceId,omitempty"`
+	// Time since when no sensor could be read, unset while a sensor is readable
+	// +optional
+	FailingSince *metav1.Time `json:"failingSince,omitempty"`
+	// How long no sensor could be read, as of the last reconcile
+	// +optional
+	Staleness string `json:"staleness,omitempty"`
+	// Battery level in percent last reported by the sensor
+	// +optional
+	Battery *int32 `json:"battery,omitempty"`
+	// Temperature as reported by the sensor, before calibration and filtering
+	// +optional
+	ReportedTemperature string `json:"reportedTemperature,omitempty"`
+	// Calibration offset added to the reported temperature
+	// +optional
+	CalibrationOffset string `json:"calibrationOffset,omitempty"`
+	// Time since when the sensor has reported the same temperature and humidity
+	// +optional
+	UnchangedSince *metav1.Time `json:"unchangedSince,omitempty"`
+}
+
+// Decision is an explainable record of a control decision
+type Decision struct {
+	// Time the decision was made
+	Time metav1.Time `json:"time"`
+	// Room temperature the decision was based on
 	// +optional
 	CurrentTemperature string `json:"currentTemperature,omitempty"`
+	// Target temperature in force
+	// +optional
+	TargetTemperature string `json:"targetTemperature,omitempty"`
+	// Mode in force
+	// +optional
+	Mode string `json:"mode,omitempty"`
+	// Chosen action, "none" when the room is within the threshold
+	// +optional
+	Action string `json:"action,omitempty"`
+	// Desired air conditioner setpoint
+	// +optional
+	Setpoint string `json:"setpoint,omitempty"`
+	// Desired power state
+	// +optional
+	Power string `json:"power,omitempty"`
+	// Whether commands were withheld because control is suspended
+	// +optional
+	Suspended bool `json:"suspended,omitempty"`
+	// Whether commands were withheld because of dry-run mode
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+	// Commands planned for each air conditioner
+	// +optional
+	Commands []DeviceCommand `json:"commands,omitempty"`
+	// Action withheld by equipment protection, in which case action is "none"
+	// +optional
+	BlockedAction string `json:"blockedAction,omitempty"`
+	// Time from which equipment protection allows the blocked action
+	// +optional
+	BlockedUntil *metav1.Time `json:"blockedUntil,omitempty"`
+	// Human-readable explanation of the decision
+	// +optional
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// DeviceCommand is a command planned for a single air conditioner
+type DeviceCommand struct {
+	// Air conditioner device ID
+	DeviceID string `json:"deviceId"`
+	// Setpoint sent to the air conditioner, after fitting it to its capabilities
+	// +optional
+	Setpoint string `json:"setpoint,omitempty"`
+	// +optional
+	Mode string `json:"mode,omitempty"`
+	// +optional
+	Power string `json:"power,omitempty"`
+	// +optional
+	FanSpeed string `json:"fanSpeed,omitempty"`
+	// Calibration offset added to the decided setpoint for this air conditioner
+	// +optional
+	CalibrationOffset string `json:"calibrationOffset,omitempty"`
+	// Capability profile the command was fitted to
+	// +optional
+	Profile string `json:"profile,omitempty"`
+}
+
+// CommandStatus records a command sent to the air conditioners
+type CommandStatus struct {
+	// Time the command was issued
+	Time metav1.Time `json:"time"`
+	// Reason for the command
+	// +optional
+	Action string `json:"action,omitempty"`
+	// Air conditioners the command was sent to
+	// +optional
+	AirConditionerIDs []string `json:"airConditionerIds,omitempty"`
+	// Commanded setpoint, as sent after fitting it to the capabilities of the air
+	// conditioners
+	// +optional
+	Setpoint string `json:"setpoint,omitempty"`
+	// Setpoint decided on, when the air conditioners were sent a different one
+	// +optional
+	RequestedSetpoint string `json:"requestedSetpoint,omitempty"`
+	// Commanded mode
+	// +optional
+	Mode string `json:"mode,omitempty"`
+	// Whether the command was only computed and not sent
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// AwayStatus describes the away period in effect
+type AwayStatus struct {
+	// Where the away period comes from: spec.away or the ClusterAwaySwitch/<name>
+	Source string `json:"source"`
+	// Time the away period started
+	// +optional
+	Start *metav1.Time `json:"start,omitempty"`
+	// Time normal control resumes, unset until the switch is removed
+	// +optional
+	End *metav1.Time `json:"end,omitempty"`
+	// Whether the air conditioners are turned off
+	// +optional
+	Off bool `json:"off,omitempty"`
+	// Target temperature while away, in the unit of the ThermoPilot
+	// +optional
+	TargetTemperature string `json:"targetTemperature,omitempty"`
+	// Mode while away
+	// +optional
+	Mode string `json:"mode,omitempty"`
+}
+
+// EffectiveTargetStatus describes the target the room is controlled towards
+type EffectiveTargetStatus struct {
+	// Temperature the room is controlled towards
+	Temperature string `json:"temperature"`
+	// Target the ramp moves towards: targetTemperature, the occupancy target or the
+	// override, shifted by the sleep curve
+	Desired string `json:"desired"`
+	// Sleep curve offset included in the desired target
+	// +optional
+	SleepOffset string `json:"sleepOffset,omitempty"`
+	// Time the effective target last moved, from which the ramp continues
+	Since metav1.Time `json:"since"`
+	// Time the effective target is due to move next, if it is
+	// +optional
+	NextChange *metav1.Time `json:"nextChange,omitempty"`
+}
+
+// OccupancyStatus describes the occupancy of the room according to its calendar
+type OccupancyStatus struct {
+	// Whether an event of the calendar is in progress, or about to start
+	Occupied bool `json:"occupied"`
+	// Summary of the event in progress
+	// +optional
+	Event string `json:"event,omitempty"`
+	// Time the occupancy changes next, if within a week
+	// +optional
+	Until *metav1.Time `json:"until,omitempty"`
+}
+
+// OverrideStatus describes the override currently in effect
+type OverrideStatus struct {
+	// Target temperature while the override is active
+	// +optional
+	TargetTemperature string `json:"targetTemperature,omitempty"`
+	// Mode while the override is active
+	// +optional
+	Mode string `json:"mode,omitempty"`
+	// Time at which the override expires
+	ExpiresAt metav1.Time `json:"expiresAt"`
+	// Time left until the override expires, as of the last reconcile
+	// +optional
+	Remaining string `json:"remaining,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Current",type=string,JSONPath=`.status.currentTemperature`
+// +kubebuilder:printcolumn:name="Raw",type=string,JSONPath=`.status.sensor.reportedTemperature`,priority=1
+// +kubebuilder:printcolumn:name="Target",type=string,JSONPath=`.spec.targetTemperature`
+// +kubebuilder:printcolumn:name="Humidity",type=integer,JSONPath=`.status.currentHumidity`,priority=1
+// +kubebuilder:printcolumn:name="Away",type=string,JSONPath=`.status.away.source`,priority=1
+// +kubebuilder:printcolumn:name="Occupied",type=boolean,JSONPath=`.status.occupancy.occupied`,priority=1
+// +kubebuilder:printcolumn:name="Effective",type=string,JSONPath=`.status.effectiveTarget.temperature`,priority=1
+// +kubebuilder:printcolumn:name="Unit",type=string,JSONPath=`.spec.unit`,priority=1
+// +kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.spec.mode`
+// +kubebuilder:printcolumn:name="Action",type=string,JSONPath=`.status.lastDecision.action`,priority=1
+// +kubebuilder:printcolumn:name="Sensor",type=string,JSONPath=`.status.sensor.source`,priority=1
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // ThermoPilot is the Schema for the thermopilots API
 type ThermoPilot struct {
@@ -22,26 +22,968 @@ package v1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccountReference) DeepCopyInto(out *AccountReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccountReference.
+func (in *AccountReference) DeepCopy() *AccountReference {
+	if in == nil {
+		return nil
+	}
+	out := new(AccountReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AggressiveOffset) DeepCopyInto(out *AggressiveOffset) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AggressiveOffset.
+func (in *AggressiveOffset) DeepCopy() *AggressiveOffset {
+	if in == nil {
+		return nil
+	}
+	out := new(AggressiveOffset)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AwayPeriod) DeepCopyInto(out *AwayPeriod) {
+	*out = *in
+	in.Start.DeepCopyInto(&out.Start)
+	in.End.DeepCopyInto(&out.End)
+	out.AwaySettings = in.AwaySettings
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AwayPeriod.
+func (in *AwayPeriod) DeepCopy() *AwayPeriod {
+	if in == nil {
+		return nil
+	}
+	out := new(AwayPeriod)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AwaySettings) DeepCopyInto(out *AwaySettings) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AwaySettings.
+func (in *AwaySettings) DeepCopy() *AwaySettings {
+	if in == nil {
+		return nil
+	}
+	out := new(AwaySettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AwayStatus) DeepCopyInto(out *AwayStatus) {
+	*out = *in
+	if in.Start != nil {
+		in, out := &in.Start, &out.Start
+		*out = (*in).DeepCopy()
+	}
+	if in.End != nil {
+		in, out := &in.End, &out.End
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AwayStatus.
+func (in *AwayStatus) DeepCopy() *AwayStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AwayStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Calibration) DeepCopyInto(out *Calibration) {
+	*out = *in
+	if in.Sensors != nil {
+		in, out := &in.Sensors, &out.Sensors
+		*out = make([]CalibrationOffset, len(*in))
+		copy(*out, *in)
+	}
+	if in.AirConditioners != nil {
+		in, out := &in.AirConditioners, &out.AirConditioners
+		*out = make([]CalibrationOffset, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Calibration.
+func (in *Calibration) DeepCopy() *Calibration {
+	if in == nil {
+		return nil
+	}
+	out := new(Calibration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CalibrationOffset) DeepCopyInto(out *CalibrationOffset) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CalibrationOffset.
+func (in *CalibrationOffset) DeepCopy() *CalibrationOffset {
+	if in == nil {
+		return nil
+	}
+	out := new(CalibrationOffset)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapabilityProfile) DeepCopyInto(out *CapabilityProfile) {
+	*out = *in
+	if in.Devices != nil {
+		in, out := &in.Devices, &out.Devices
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Cool != nil {
+		in, out := &in.Cool, &out.Cool
+		*out = new(SetpointRange)
+		**out = **in
+	}
+	if in.Heat != nil {
+		in, out := &in.Heat, &out.Heat
+		*out = new(SetpointRange)
+		**out = **in
+	}
+	if in.Modes != nil {
+		in, out := &in.Modes, &out.Modes
+		*out = make([]ProfileMode, len(*in))
+		copy(*out, *in)
+	}
+	if in.FanSpeeds != nil {
+		in, out := &in.FanSpeeds, &out.FanSpeeds
+		*out = make([]FanSpeed, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapabilityProfile.
+func (in *CapabilityProfile) DeepCopy() *CapabilityProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(CapabilityProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterAwaySwitch) DeepCopyInto(out *ClusterAwaySwitch) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterAwaySwitch.
+func (in *ClusterAwaySwitch) DeepCopy() *ClusterAwaySwitch {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterAwaySwitch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterAwaySwitch) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterAwaySwitchList) DeepCopyInto(out *ClusterAwaySwitchList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterAwaySwitch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterAwaySwitchList.
+func (in *ClusterAwaySwitchList) DeepCopy() *ClusterAwaySwitchList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterAwaySwitchList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterAwaySwitchList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterAwaySwitchSpec) DeepCopyInto(out *ClusterAwaySwitchSpec) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+	if in.Start != nil {
+		in, out := &in.Start, &out.Start
+		*out = (*in).DeepCopy()
+	}
+	if in.End != nil {
+		in, out := &in.End, &out.End
+		*out = (*in).DeepCopy()
+	}
+	out.AwaySettings = in.AwaySettings
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterAwaySwitchSpec.
+func (in *ClusterAwaySwitchSpec) DeepCopy() *ClusterAwaySwitchSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterAwaySwitchSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterSwitchBotAccount) DeepCopyInto(out *ClusterSwitchBotAccount) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSwitchBotAccount.
+func (in *ClusterSwitchBotAccount) DeepCopy() *ClusterSwitchBotAccount {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterSwitchBotAccount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterSwitchBotAccount) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterSwitchBotAccountList) DeepCopyInto(out *ClusterSwitchBotAccountList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterSwitchBotAccount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSwitchBotAccountList.
+func (in *ClusterSwitchBotAccountList) DeepCopy() *ClusterSwitchBotAccountList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterSwitchBotAccountList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterSwitchBotAccountList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterSwitchBotAccountSpec) DeepCopyInto(out *ClusterSwitchBotAccountSpec) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+	if in.SyncInterval != nil {
+		in, out := &in.SyncInterval, &out.SyncInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSwitchBotAccountSpec.
+func (in *ClusterSwitchBotAccountSpec) DeepCopy() *ClusterSwitchBotAccountSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterSwitchBotAccountSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommandStatus) DeepCopyInto(out *CommandStatus) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.AirConditionerIDs != nil {
+		in, out := &in.AirConditionerIDs, &out.AirConditionerIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommandStatus.
+func (in *CommandStatus) DeepCopy() *CommandStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CommandStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapKeyReference) DeepCopyInto(out *ConfigMapKeyReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapKeyReference.
+func (in *ConfigMapKeyReference) DeepCopy() *ConfigMapKeyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapKeyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Decision) DeepCopyInto(out *Decision) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Commands != nil {
+		in, out := &in.Commands, &out.Commands
+		*out = make([]DeviceCommand, len(*in))
+		copy(*out, *in)
+	}
+	if in.BlockedUntil != nil {
+		in, out := &in.BlockedUntil, &out.BlockedUntil
+		*out = (*in).DeepCopy()
+	}
+	if in.Reasons != nil {
+		in, out := &in.Reasons, &out.Reasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Decision.
+func (in *Decision) DeepCopy() *Decision {
+	if in == nil {
+		return nil
+	}
+	out := new(Decision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceCommand) DeepCopyInto(out *DeviceCommand) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceCommand.
+func (in *DeviceCommand) DeepCopy() *DeviceCommand {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceCommand)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EffectiveTargetStatus) DeepCopyInto(out *EffectiveTargetStatus) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+	if in.NextChange != nil {
+		in, out := &in.NextChange, &out.NextChange
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EffectiveTargetStatus.
+func (in *EffectiveTargetStatus) DeepCopy() *EffectiveTargetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EffectiveTargetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EquipmentProtection) DeepCopyInto(out *EquipmentProtection) {
+	*out = *in
+	if in.MinOnTime != nil {
+		in, out := &in.MinOnTime, &out.MinOnTime
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MinOffTime != nil {
+		in, out := &in.MinOffTime, &out.MinOffTime
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MinModeChangeInterval != nil {
+		in, out := &in.MinModeChangeInterval, &out.MinModeChangeInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EquipmentProtection.
+func (in *EquipmentProtection) DeepCopy() *EquipmentProtection {
+	if in == nil {
+		return nil
+	}
+	out := new(EquipmentProtection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EquipmentStatus) DeepCopyInto(out *EquipmentStatus) {
+	*out = *in
+	if in.LastStart != nil {
+		in, out := &in.LastStart, &out.LastStart
+		*out = (*in).DeepCopy()
+	}
+	if in.LastStop != nil {
+		in, out := &in.LastStop, &out.LastStop
+		*out = (*in).DeepCopy()
+	}
+	if in.LastModeChange != nil {
+		in, out := &in.LastModeChange, &out.LastModeChange
+		*out = (*in).DeepCopy()
+	}
+	if in.Starts != nil {
+		in, out := &in.Starts, &out.Starts
+		*out = make([]metav1.Time, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EquipmentStatus.
+func (in *EquipmentStatus) DeepCopy() *EquipmentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EquipmentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FilterStatus) DeepCopyInto(out *FilterStatus) {
+	*out = *in
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilterStatus.
+func (in *FilterStatus) DeepCopy() *FilterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FilterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacedSecretReference) DeepCopyInto(out *NamespacedSecretReference) {
+	*out = *in
+	out.SecretReference = in.SecretReference
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedSecretReference.
+func (in *NamespacedSecretReference) DeepCopy() *NamespacedSecretReference {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacedSecretReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OccupancySchedule) DeepCopyInto(out *OccupancySchedule) {
+	*out = *in
+	out.CalendarRef = in.CalendarRef
+	if in.PreConditioning != nil {
+		in, out := &in.PreConditioning, &out.PreConditioning
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OccupancySchedule.
+func (in *OccupancySchedule) DeepCopy() *OccupancySchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(OccupancySchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OccupancyStatus) DeepCopyInto(out *OccupancyStatus) {
+	*out = *in
+	if in.Until != nil {
+		in, out := &in.Until, &out.Until
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OccupancyStatus.
+func (in *OccupancyStatus) DeepCopy() *OccupancyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OccupancyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OnDeletePolicy) DeepCopyInto(out *OnDeletePolicy) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OnDeletePolicy.
+func (in *OnDeletePolicy) DeepCopy() *OnDeletePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(OnDeletePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Override) DeepCopyInto(out *Override) {
+	*out = *in
+	in.ExpiresAt.DeepCopyInto(&out.ExpiresAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Override.
+func (in *Override) DeepCopy() *Override {
+	if in == nil {
+		return nil
+	}
+	out := new(Override)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OverrideStatus) DeepCopyInto(out *OverrideStatus) {
+	*out = *in
+	in.ExpiresAt.DeepCopyInto(&out.ExpiresAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OverrideStatus.
+func (in *OverrideStatus) DeepCopy() *OverrideStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OverrideStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaStatus) DeepCopyInto(out *QuotaStatus) {
+	*out = *in
+	if in.ResetTime != nil {
+		in, out := &in.ResetTime, &out.ResetTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaStatus.
+func (in *QuotaStatus) DeepCopy() *QuotaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Ramp) DeepCopyInto(out *Ramp) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Ramp.
+func (in *Ramp) DeepCopy() *Ramp {
+	if in == nil {
+		return nil
+	}
+	out := new(Ramp)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReadingFilter) DeepCopyInto(out *ReadingFilter) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadingFilter.
+func (in *ReadingFilter) DeepCopy() *ReadingFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(ReadingFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SafetyLimits) DeepCopyInto(out *SafetyLimits) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SafetyLimits.
+func (in *SafetyLimits) DeepCopy() *SafetyLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(SafetyLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecretReference) DeepCopyInto(out *SecretReference) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretReference.
-func (in *SecretReference) DeepCopy() *SecretReference {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretReference.
+func (in *SecretReference) DeepCopy() *SecretReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SensorFailurePolicy) DeepCopyInto(out *SensorFailurePolicy) {
+	*out = *in
+	if in.FallbackSensors != nil {
+		in, out := &in.FallbackSensors, &out.FallbackSensors
+		*out = make([]SensorSource, len(*in))
+		copy(*out, *in)
+	}
+	if in.HoldLastReading != nil {
+		in, out := &in.HoldLastReading, &out.HoldLastReading
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SensorFailurePolicy.
+func (in *SensorFailurePolicy) DeepCopy() *SensorFailurePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SensorFailurePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SensorHealthPolicy) DeepCopyInto(out *SensorHealthPolicy) {
+	*out = *in
+	if in.LowBattery != nil {
+		in, out := &in.LowBattery, &out.LowBattery
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FrozenAfter != nil {
+		in, out := &in.FrozenAfter, &out.FrozenAfter
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SensorHealthPolicy.
+func (in *SensorHealthPolicy) DeepCopy() *SensorHealthPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(SecretReference)
+	out := new(SensorHealthPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ThermoPilot) DeepCopyInto(out *ThermoPilot) {
+func (in *SensorSource) DeepCopyInto(out *SensorSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SensorSource.
+func (in *SensorSource) DeepCopy() *SensorSource {
+	if in == nil {
+		return nil
+	}
+	out := new(SensorSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SensorStatus) DeepCopyInto(out *SensorStatus) {
+	*out = *in
+	if in.FailingSince != nil {
+		in, out := &in.FailingSince, &out.FailingSince
+		*out = (*in).DeepCopy()
+	}
+	if in.Battery != nil {
+		in, out := &in.Battery, &out.Battery
+		*out = new(int32)
+		**out = **in
+	}
+	if in.UnchangedSince != nil {
+		in, out := &in.UnchangedSince, &out.UnchangedSince
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SensorStatus.
+func (in *SensorStatus) DeepCopy() *SensorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SensorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SetpointRange) DeepCopyInto(out *SetpointRange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SetpointRange.
+func (in *SetpointRange) DeepCopy() *SetpointRange {
+	if in == nil {
+		return nil
+	}
+	out := new(SetpointRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SleepCurve) DeepCopyInto(out *SleepCurve) {
+	*out = *in
+	out.Duration = in.Duration
+	if in.Points != nil {
+		in, out := &in.Points, &out.Points
+		*out = make([]SleepCurvePoint, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SleepCurve.
+func (in *SleepCurve) DeepCopy() *SleepCurve {
+	if in == nil {
+		return nil
+	}
+	out := new(SleepCurve)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SleepCurvePoint) DeepCopyInto(out *SleepCurvePoint) {
+	*out = *in
+	out.After = in.After
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SleepCurvePoint.
+func (in *SleepCurvePoint) DeepCopy() *SleepCurvePoint {
+	if in == nil {
+		return nil
+	}
+	out := new(SleepCurvePoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SwitchBotAccount) DeepCopyInto(out *SwitchBotAccount) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SwitchBotAccount.
+func (in *SwitchBotAccount) DeepCopy() *SwitchBotAccount {
+	if in == nil {
+		return nil
+	}
+	out := new(SwitchBotAccount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SwitchBotAccount) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SwitchBotAccountList) DeepCopyInto(out *SwitchBotAccountList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SwitchBotAccount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SwitchBotAccountList.
+func (in *SwitchBotAccountList) DeepCopy() *SwitchBotAccountList {
+	if in == nil {
+		return nil
+	}
+	out := new(SwitchBotAccountList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SwitchBotAccountList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SwitchBotAccountSpec) DeepCopyInto(out *SwitchBotAccountSpec) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+	if in.SyncInterval != nil {
+		in, out := &in.SyncInterval, &out.SyncInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SwitchBotAccountSpec.
+func (in *SwitchBotAccountSpec) DeepCopy() *SwitchBotAccountSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SwitchBotAccountSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SwitchBotAccountStatus) DeepCopyInto(out *SwitchBotAccountStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = new(QuotaStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SwitchBotAccountStatus.
+func (in *SwitchBotAccountStatus) DeepCopy() *SwitchBotAccountStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SwitchBotAccountStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SwitchBotDevice) DeepCopyInto(out *SwitchBotDevice) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -49,6 +991,105 @@ func (in *ThermoPilot) DeepCopyInto(out *ThermoPilot) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SwitchBotDevice.
+func (in *SwitchBotDevice) DeepCopy() *SwitchBotDevice {
+	if in == nil {
+		return nil
+	}
+	out := new(SwitchBotDevice)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SwitchBotDevice) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SwitchBotDeviceList) DeepCopyInto(out *SwitchBotDeviceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SwitchBotDevice, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SwitchBotDeviceList.
+func (in *SwitchBotDeviceList) DeepCopy() *SwitchBotDeviceList {
+	if in == nil {
+		return nil
+	}
+	out := new(SwitchBotDeviceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SwitchBotDeviceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SwitchBotDeviceSpec) DeepCopyInto(out *SwitchBotDeviceSpec) {
+	*out = *in
+	out.AccountRef = in.AccountRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SwitchBotDeviceSpec.
+func (in *SwitchBotDeviceSpec) DeepCopy() *SwitchBotDeviceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SwitchBotDeviceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SwitchBotDeviceStatus) DeepCopyInto(out *SwitchBotDeviceStatus) {
+	*out = *in
+	if in.LastSeenTime != nil {
+		in, out := &in.LastSeenTime, &out.LastSeenTime
+		*out = (*in).DeepCopy()
+	}
+	if in.DeviceStatus != nil {
+		in, out := &in.DeviceStatus, &out.DeviceStatus
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SwitchBotDeviceStatus.
+func (in *SwitchBotDeviceStatus) DeepCopy() *SwitchBotDeviceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SwitchBotDeviceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThermoPilot) DeepCopyInto(out *ThermoPilot) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThermoPilot.
 func (in *ThermoPilot) DeepCopy() *ThermoPilot {
 	if in == nil {
@@ -103,6 +1144,85 @@ func (in *ThermoPilotList) DeepCopyObject() runtime.Object {
 func (in *ThermoPilotSpec) DeepCopyInto(out *ThermoPilotSpec) {
 	*out = *in
 	out.SecretRef = in.SecretRef
+	if in.AccountRef != nil {
+		in, out := &in.AccountRef, &out.AccountRef
+		*out = new(AccountReference)
+		**out = **in
+	}
+	if in.AggressiveOffset != nil {
+		in, out := &in.AggressiveOffset, &out.AggressiveOffset
+		*out = new(AggressiveOffset)
+		**out = **in
+	}
+	if in.Override != nil {
+		in, out := &in.Override, &out.Override
+		*out = new(Override)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Away != nil {
+		in, out := &in.Away, &out.Away
+		*out = make([]AwayPeriod, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.OnDelete != nil {
+		in, out := &in.OnDelete, &out.OnDelete
+		*out = new(OnDeletePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SensorFailurePolicy != nil {
+		in, out := &in.SensorFailurePolicy, &out.SensorFailurePolicy
+		*out = new(SensorFailurePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CapabilityProfiles != nil {
+		in, out := &in.CapabilityProfiles, &out.CapabilityProfiles
+		*out = make([]CapabilityProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Calibration != nil {
+		in, out := &in.Calibration, &out.Calibration
+		*out = new(Calibration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Safety != nil {
+		in, out := &in.Safety, &out.Safety
+		*out = new(SafetyLimits)
+		**out = **in
+	}
+	if in.Ramp != nil {
+		in, out := &in.Ramp, &out.Ramp
+		*out = new(Ramp)
+		**out = **in
+	}
+	if in.SleepCurve != nil {
+		in, out := &in.SleepCurve, &out.SleepCurve
+		*out = new(SleepCurve)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Occupancy != nil {
+		in, out := &in.Occupancy, &out.Occupancy
+		*out = new(OccupancySchedule)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EquipmentProtection != nil {
+		in, out := &in.EquipmentProtection, &out.EquipmentProtection
+		*out = new(EquipmentProtection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SensorHealth != nil {
+		in, out := &in.SensorHealth, &out.SensorHealth
+		*out = new(SensorHealthPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Filter != nil {
+		in, out := &in.Filter, &out.Filter
+		*out = new(ReadingFilter)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThermoPilotSpec.
@@ -125,6 +1245,56 @@ func (in *ThermoPilotStatus) DeepCopyInto(out *ThermoPilotStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.CurrentHumidity != nil {
+		in, out := &in.CurrentHumidity, &out.CurrentHumidity
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LastDecision != nil {
+		in, out := &in.LastDecision, &out.LastDecision
+		*out = new(Decision)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastCommand != nil {
+		in, out := &in.LastCommand, &out.LastCommand
+		*out = new(CommandStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ActiveOverride != nil {
+		in, out := &in.ActiveOverride, &out.ActiveOverride
+		*out = new(OverrideStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Away != nil {
+		in, out := &in.Away, &out.Away
+		*out = new(AwayStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EffectiveTarget != nil {
+		in, out := &in.EffectiveTarget, &out.EffectiveTarget
+		*out = new(EffectiveTargetStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Occupancy != nil {
+		in, out := &in.Occupancy, &out.Occupancy
+		*out = new(OccupancyStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Sensor != nil {
+		in, out := &in.Sensor, &out.Sensor
+		*out = new(SensorStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Filter != nil {
+		in, out := &in.Filter, &out.Filter
+		*out = new(FilterStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Equipment != nil {
+		in, out := &in.Equipment, &out.Equipment
+		*out = new(EquipmentStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThermoPilotStatus.
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+	"github.com/seipan/thermo-pilot-controller/internal/planner"
+)
+
+// defaultBoostDuration is how long a boost lasts when --for is not set.
+const defaultBoostDuration = time.Hour
+
+// boostOptions are the flags of the boost command.
+type boostOptions struct {
+	to       string
+	by       float64
+	mode     string
+	duration time.Duration
+	cancel   bool
+}
+
+func newBoostCommand(o *options) *cobra.Command {
+	b := &boostOptions{}
+	cmd := &cobra.Command{
+		Use:   "boost NAME",
+		Short: "Temporarily override the target temperature",
+		Long: `Temporarily override the target temperature by setting spec.override.
+Normal control resumes once the override expires.
+
+--by moves the target towards more cooling in cool mode and more heating in
+heat mode.`,
+		Example: `  kubectl thermopilot boost living-room --by 2 --for 30m
+  kubectl thermopilot boost living-room --to 20 --mode heat
+  kubectl thermopilot boost living-room --cancel`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.patch(cmd, args[0], func(thermoPilot *thermopilotv1.ThermoPilot) (string, error) {
+				return b.apply(thermoPilot, o.now())
+			})
+		},
+	}
+	cmd.Flags().StringVar(&b.to, "to", "", "Target temperature while boosted, in the unit of the ThermoPilot.")
+	cmd.Flags().Float64Var(&b.by, "by", 0, "Degrees to move the target by in the direction of the mode.")
+	cmd.Flags().StringVar(&b.mode, "mode", "", "Mode while boosted: cool or heat. Defaults to spec.mode.")
+	cmd.Flags().DurationVar(&b.duration, "for", defaultBoostDuration, "How long the boost lasts.")
+	cmd.Flags().BoolVar(&b.cancel, "cancel", false, "Remove the active override and resume normal control.")
+	cmd.MarkFlagsMutuallyExclusive("to", "by", "cancel")
+	cmd.MarkFlagsOneRequired("to", "by", "cancel")
+	return cmd
+}
+
+// apply sets or removes the override of thermoPilot and returns what was done.
+func (b *boostOptions) apply(thermoPilot *thermopilotv1.ThermoPilot, now time.Time) (string, error) {
+	if b.cancel {
+		if thermoPilot.Spec.Override == nil {
+			return "", errNoChange
+		}
+		thermoPilot.Spec.Override = nil
+		return "override removed", nil
+	}
+	if b.duration <= 0 {
+		return "", errors.New("--for must be positive")
+	}
+	mode := b.mode
+	if mode == "" {
+		mode = thermoPilot.Spec.Mode
+	}
+	if mode != planner.ModeCool && mode != planner.ModeHeat {
+		return "", fmt.Errorf("unsupported mode: %s", mode)
+	}
+
+	var target float64
+	if b.to != "" {
+		value, err := planner.ParseTemperature(b.to)
+		if err != nil {
+			return "", err
+		}
+		target = value
+	} else {
+		if b.by <= 0 {
+			return "", errors.New("--by must be positive")
+		}
+		value, err := planner.ParseTemperature(thermoPilot.Spec.TargetTemperature)
+		if err != nil {
+			return "", err
+		}
+		if mode == planner.ModeCool {
+			target = value - b.by
+		} else {
+			target = value + b.by
+		}
+	}
+	// the bounds of the targetTemperature pattern
+	if target < 1 || target >= 40 {
+		return "", fmt.Errorf("target temperature %s is out of range", planner.FormatTemperature(target))
+	}
+
+	thermoPilot.Spec.Override = &thermopilotv1.Override{
+		TargetTemperature: planner.FormatTemperature(target),
+		Mode:              mode,
+		ExpiresAt:         metav1.NewTime(now.Add(b.duration).Truncate(time.Second)),
+	}
+	return fmt.Sprintf("boosted to %s (%s) until %s",
+		thermoPilot.Spec.Override.TargetTemperature, mode, thermoPilot.Spec.Override.ExpiresAt.UTC().Format(time.RFC3339)), nil
+}
+
+func newSuspendCommand(o *options, suspend bool) *cobra.Command {
+	use, short, done := "suspend NAME", "Stop sending commands while readings keep being updated", "suspended"
+	if !suspend {
+		use, short, done = "resume NAME", "Resume sending commands after a suspend", "resumed"
+	}
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.patch(cmd, args[0], func(thermoPilot *thermopilotv1.ThermoPilot) (string, error) {
+				if thermoPilot.Spec.Suspend == suspend {
+					return "", errNoChange
+				}
+				thermoPilot.Spec.Suspend = suspend
+				return done, nil
+			})
+		},
+	}
+}
+
+// errNoChange is returned by a mutation that leaves the ThermoPilot as it is.
+var errNoChange = errors.New("no change")
+
+// patch applies mutate to the named ThermoPilot and sends the difference as a merge
+// patch guarded by the resourceVersion, so that a concurrent edit is not overwritten.
+func (o *options) patch(cmd *cobra.Command, name string, mutate func(*thermopilotv1.ThermoPilot) (string, error)) error {
+	c, namespace, err := o.newClient()
+	if err != nil {
+		return err
+	}
+	var thermoPilot thermopilotv1.ThermoPilot
+	if err := c.Get(cmd.Context(), types.NamespacedName{Namespace: namespace, Name: name}, &thermoPilot); err != nil {
+		return err
+	}
+	original := thermoPilot.DeepCopy()
+	done, err := mutate(&thermoPilot)
+	if errors.Is(err, errNoChange) {
+		_, err = fmt.Fprintf(cmd.OutOrStdout(), "thermopilot/%s unchanged\n", name)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if err := c.Patch(cmd.Context(), &thermoPilot, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(cmd.OutOrStdout(), "thermopilot/%s %s\n", name, done)
+	return err
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+	"github.com/seipan/thermo-pilot-controller/internal/cli"
+)
+
+// Sources of history entries.
+const (
+	sourceEvent  = "Event"
+	sourceStatus = "Status"
+)
+
+// historyEntry is a reading or an action of a ThermoPilot at a point in time.
+type historyEntry struct {
+	Time    metav1.Time `json:"time"`
+	Source  string      `json:"source"`
+	Type    string      `json:"type"`
+	Reason  string      `json:"reason"`
+	Message string      `json:"message"`
+	Count   int32       `json:"count,omitempty"`
+}
+
+func newHistoryCommand(o *options) *cobra.Command {
+	var limit int
+	cmd := &cobra.Command{
+		Use:   "history NAME",
+		Short: "Show the recent readings and actions of a ThermoPilot",
+		Long: `Show the recent readings and actions of a ThermoPilot, oldest first.
+
+Entries come from the Events recorded for the ThermoPilot and from the last
+decision and command in its status. Events expire after the retention of the
+cluster, one hour by default.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, namespace, err := o.newClient()
+			if err != nil {
+				return err
+			}
+			var thermoPilot thermopilotv1.ThermoPilot
+			if err := c.Get(cmd.Context(), types.NamespacedName{Namespace: namespace, Name: args[0]}, &thermoPilot); err != nil {
+				return err
+			}
+			var events corev1.EventList
+			if err := c.List(cmd.Context(), &events, client.InNamespace(namespace), client.MatchingFields{
+				"involvedObject.kind": "ThermoPilot",
+				"involvedObject.name": thermoPilot.Name,
+			}); err != nil {
+				return fmt.Errorf("failed to list events: %w", err)
+			}
+			entries := history(&thermoPilot, events.Items)
+			if limit > 0 && len(entries) > limit {
+				entries = entries[len(entries)-limit:]
+			}
+			return printHistory(cmd.OutOrStdout(), o.output, entries, o.now)
+		},
+	}
+	cmd.Flags().IntVar(&limit, "limit", 20, "Show at most this many of the most recent entries; 0 shows all.")
+	return cmd
+}
+
+// history merges the events of thermoPilot with the decision and command recorded
+// in its status, oldest first. Events of an earlier object with the same name are
+// skipped.
+func history(thermoPilot *thermopilotv1.ThermoPilot, events []corev1.Event) []historyEntry {
+	var entries []historyEntry
+	for _, event := range events {
+		if event.InvolvedObject.UID != "" && event.InvolvedObject.UID != thermoPilot.UID {
+			continue
+		}
+		entries = append(entries, historyEntry{
+			Time:    eventTime(&event),
+			Source:  sourceEvent,
+			Type:    event.Type,
+			Reason:  event.Reason,
+			Message: event.Message,
+			Count:   event.Count,
+		})
+	}
+
+	status := &thermoPilot.Status
+	if decision := status.LastDecision; decision != nil {
+		message := fmt.Sprintf("current=%s target=%s mode=%s action=%s",
+			decision.CurrentTemperature, decision.TargetTemperature, decision.Mode, decision.Action)
+		if status.CurrentHumidity != nil {
+			message += fmt.Sprintf(" humidity=%d%%", *status.CurrentHumidity)
+		}
+		entries = append(entries, historyEntry{
+			Time:    decision.Time,
+			Source:  sourceStatus,
+			Type:    corev1.EventTypeNormal,
+			Reason:  "Decision",
+			Message: message,
+		})
+	}
+	if command := status.LastCommand; command != nil {
+		message := fmt.Sprintf("set %v to %s (%s) for action %s", command.AirConditionerIDs, command.Setpoint, command.Mode, command.Action)
+		if command.DryRun {
+			message = "dry run: " + message
+		}
+		entries = append(entries, historyEntry{
+			Time:    command.Time,
+			Source:  sourceStatus,
+			Type:    corev1.EventTypeNormal,
+			Reason:  "LastCommand",
+			Message: message,
+		})
+	}
+
+	slices.SortStableFunc(entries, func(a, b historyEntry) int {
+		return a.Time.Compare(b.Time.Time)
+	})
+	return entries
+}
+
+// eventTime returns the last time event was observed.
+func eventTime(event *corev1.Event) metav1.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp
+	case !event.EventTime.IsZero():
+		return metav1.Time{Time: event.EventTime.Time}
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp
+	default:
+		return event.CreationTimestamp
+	}
+}
+
+func printHistory(w io.Writer, format string, entries []historyEntry, now func() time.Time) error {
+	if cli.IsStructured(format) {
+		if entries == nil {
+			entries = []historyEntry{}
+		}
+		return cli.PrintStructured(w, format, entries)
+	}
+	table := cli.NewTable(w)
+	header := "AGE\tTYPE\tREASON\tMESSAGE"
+	if format == cli.OutputWide {
+		header = "TIME\tSOURCE\tTYPE\tREASON\tCOUNT\tMESSAGE"
+	}
+	_, _ = fmt.Fprintln(table, header)
+	for _, entry := range entries {
+		if format == cli.OutputWide {
+			_, _ = fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%d\t%s\n",
+				entry.Time.UTC().Format(time.RFC3339), entry.Source, entry.Type, entry.Reason, max(entry.Count, 1), entry.Message)
+			continue
+		}
+		_, _ = fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", cli.Age(entry.Time, now()), entry.Type, entry.Reason, entry.Message)
+	}
+	return table.Flush()
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command kubectl-thermopilot is a kubectl plugin giving a live overview of the
+// rooms controlled by ThermoPilots, their history, and verbs to boost, suspend and
+// resume control. Install it on the PATH and run it as "kubectl thermopilot".
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/seipan/thermo-pilot-controller/internal/cli"
+)
+
+// options are the flags shared by every subcommand.
+type options struct {
+	kube   cli.KubeFlags
+	output string
+	// now is the clock used for ages and override expiry
+	now func() time.Time
+	// newClient returns the client and namespace to use; replaced in tests
+	newClient func() (client.Client, string, error)
+}
+
+func main() {
+	o := &options{now: time.Now}
+	o.newClient = o.kube.Client
+	if err := newRootCommand(o).Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func newRootCommand(o *options) *cobra.Command {
+	root := &cobra.Command{
+		Use:          "kubectl-thermopilot",
+		Short:        "Overview and control of the rooms managed by ThermoPilots",
+		SilenceUsage: true,
+		Annotations: map[string]string{
+			cobra.CommandDisplayNameAnnotation: "kubectl thermopilot",
+		},
+		PersistentPreRunE: func(*cobra.Command, []string) error {
+			switch o.output {
+			case cli.OutputTable, cli.OutputWide, cli.OutputJSON, cli.OutputYAML:
+				return nil
+			default:
+				return fmt.Errorf("unsupported output format: %s", o.output)
+			}
+		},
+	}
+	flags := root.PersistentFlags()
+	o.kube.AddFlags(flags)
+	flags.StringVarP(&o.output, "output", "o", cli.OutputTable, "Output format: table, wide, json or yaml.")
+
+	root.AddCommand(
+		newStatusCommand(o),
+		newHistoryCommand(o),
+		newBoostCommand(o),
+		newSuspendCommand(o, true),
+		newSuspendCommand(o, false),
+	)
+	return root
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+	"github.com/seipan/thermo-pilot-controller/internal/cli"
+)
+
+var testNow = time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+func testThermoPilot() *thermopilotv1.ThermoPilot {
+	humidity := int32(55)
+	return &thermopilotv1.ThermoPilot{
+		ObjectMeta: metav1.ObjectMeta{Name: "living-room", Namespace: "home", UID: "uid-1"},
+		Spec: thermopilotv1.ThermoPilotSpec{
+			SecretRef:             thermopilotv1.SecretReference{Name: "switchbot"},
+			TemperatureSensorType: "MeterPro",
+			TargetTemperature:     "25.0",
+			Mode:                  "cool",
+		},
+		Status: thermopilotv1.ThermoPilotStatus{
+			CurrentTemperature: "27.2",
+			CurrentHumidity:    &humidity,
+			LastDecision: &thermopilotv1.Decision{
+				Time:               metav1.NewTime(testNow.Add(-time.Minute)),
+				CurrentTemperature: "27.2",
+				TargetTemperature:  "25.0",
+				Mode:               "cool",
+				Action:             "cooling",
+				Setpoint:           "22.0",
+			},
+			LastCommand: &thermopilotv1.CommandStatus{
+				Time:              metav1.NewTime(testNow.Add(-6 * time.Minute)),
+				Action:            "cooling",
+				AirConditionerIDs: []string{"AC1"},
+				Setpoint:          "22.0",
+				Mode:              "cool",
+			},
+			Conditions: []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Reconciled"}},
+		},
+	}
+}
+
+// run executes the plugin with args against objs and returns its output and the client.
+func run(t *testing.T, args []string, objs ...client.Object) (string, client.Client, error) {
+	t.Helper()
+	c := fake.NewClientBuilder().
+		WithScheme(cli.Scheme()).
+		WithObjects(objs...).
+		WithStatusSubresource(&thermopilotv1.ThermoPilot{}).
+		WithIndex(&corev1.Event{}, "involvedObject.kind", func(obj client.Object) []string {
+			return []string{obj.(*corev1.Event).InvolvedObject.Kind}
+		}).
+		WithIndex(&corev1.Event{}, "involvedObject.name", func(obj client.Object) []string {
+			return []string{obj.(*corev1.Event).InvolvedObject.Name}
+		}).
+		Build()
+
+	o := &options{
+		now:       func() time.Time { return testNow },
+		newClient: func() (client.Client, string, error) { return c, "home", nil },
+	}
+	root := newRootCommand(o)
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetArgs(args)
+	err := root.ExecuteContext(context.Background())
+	return out.String(), c, err
+}
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		contains []string
+	}{
+		{
+			name:     "table",
+			args:     []string{"status"},
+			contains: []string{"NAME", "HUMIDITY", "living-room", "27.2", "25.0", "55%", "cooling (6m ago)", "Healthy"},
+		},
+		{
+			name:     "wide",
+			args:     []string{"status", "living-room", "-o", "wide"},
+			contains: []string{"DECISION", "SETPOINT", "22.0", "false"},
+		},
+		{
+			name:     "all namespaces",
+			args:     []string{"status", "-A"},
+			contains: []string{"NAMESPACE", "home"},
+		},
+		{
+			name:     "json",
+			args:     []string{"status", "-o", "json"},
+			contains: []string{`"kind": "ThermoPilotList"`, `"currentHumidity": 55`},
+		},
+		{
+			name:     "yaml",
+			args:     []string{"status", "-o", "yaml"},
+			contains: []string{"kind: ThermoPilot", "currentTemperature: \"27.2\""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, _, err := run(t, tt.args, testThermoPilot())
+			require.NoError(t, err)
+			for _, want := range tt.contains {
+				assert.Contains(t, out, want)
+			}
+		})
+	}
+}
+
+func TestStatus_UnsupportedOutput(t *testing.T) {
+	_, _, err := run(t, []string{"status", "-o", "xml"}, testThermoPilot())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported output format")
+}
+
+func TestHistory(t *testing.T) {
+	thermoPilot := testThermoPilot()
+	event := func(name, uid, reason string, at time.Duration) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "home"},
+			InvolvedObject: corev1.ObjectReference{
+				Kind: "ThermoPilot", Namespace: "home", Name: "living-room", UID: types.UID(uid),
+			},
+			Type:          corev1.EventTypeNormal,
+			Reason:        reason,
+			Message:       reason + " message",
+			LastTimestamp: metav1.NewTime(testNow.Add(at)),
+		}
+	}
+	objs := []client.Object{
+		thermoPilot,
+		event("sent", "uid-1", "CommandSent", -6*time.Minute),
+		event("expired", "uid-1", "OverrideExpired", -30*time.Minute),
+		event("stale", "uid-0", "OnDeleteCompleted", -2*time.Hour),
+	}
+
+	out, _, err := run(t, []string{"history", "living-room", "-o", "json"}, objs...)
+	require.NoError(t, err)
+	assert.NotContains(t, out, "OnDeleteCompleted")
+
+	entries := history(thermoPilot, []corev1.Event{
+		*event("sent", "uid-1", "CommandSent", -6*time.Minute),
+		*event("expired", "uid-1", "OverrideExpired", -30*time.Minute),
+	})
+	reasons := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		reasons = append(reasons, entry.Reason)
+	}
+	assert.Equal(t, []string{"OverrideExpired", "CommandSent", "LastCommand", "Decision"}, reasons)
+	assert.Contains(t, entries[3].Message, "humidity=55%")
+
+	out, _, err = run(t, []string{"history", "living-room", "--limit", "2"}, objs...)
+	require.NoError(t, err)
+	assert.NotContains(t, out, "OverrideExpired")
+	assert.Contains(t, out, "Decision")
+}
+
+func TestBoostOptions_apply(t *testing.T) {
+	tests := []struct {
+		name       string
+		options    boostOptions
+		override   *thermopilotv1.Override
+		want       *thermopilotv1.Override
+		wantErrMsg string
+	}{
+		{
+			name:    "by in cool mode lowers the target",
+			options: boostOptions{by: 2, duration: 30 * time.Minute},
+			want: &thermopilotv1.Override{
+				TargetTemperature: "23.0", Mode: "cool", ExpiresAt: metav1.NewTime(testNow.Add(30 * time.Minute)),
+			},
+		},
+		{
+			name:    "by in heat mode raises the target",
+			options: boostOptions{by: 1.5, mode: "heat", duration: time.Hour},
+			want: &thermopilotv1.Override{
+				TargetTemperature: "26.5", Mode: "heat", ExpiresAt: metav1.NewTime(testNow.Add(time.Hour)),
+			},
+		},
+		{
+			name:    "to",
+			options: boostOptions{to: "21", duration: time.Hour},
+			want: &thermopilotv1.Override{
+				TargetTemperature: "21.0", Mode: "cool", ExpiresAt: metav1.NewTime(testNow.Add(time.Hour)),
+			},
+		},
+		{
+			name:       "out of range",
+			options:    boostOptions{by: 30, duration: time.Hour},
+			wantErrMsg: "out of range",
+		},
+		{
+			name:       "unsupported mode",
+			options:    boostOptions{to: "21", mode: "dry", duration: time.Hour},
+			wantErrMsg: "unsupported mode",
+		},
+		{
+			name:       "non-positive duration",
+			options:    boostOptions{to: "21"},
+			wantErrMsg: "--for must be positive",
+		},
+		{
+			name:     "cancel",
+			options:  boostOptions{cancel: true},
+			override: &thermopilotv1.Override{TargetTemperature: "21.0", ExpiresAt: metav1.NewTime(testNow)},
+		},
+		{
+			name:       "cancel without override",
+			options:    boostOptions{cancel: true},
+			wantErrMsg: errNoChange.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thermoPilot := testThermoPilot()
+			thermoPilot.Spec.Override = tt.override
+			_, err := tt.options.apply(thermoPilot, testNow)
+			if tt.wantErrMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, thermoPilot.Spec.Override)
+		})
+	}
+}
+
+func TestControlVerbs(t *testing.T) {
+	get := func(t *testing.T, c client.Client) *thermopilotv1.ThermoPilot {
+		var thermoPilot thermopilotv1.ThermoPilot
+		require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "home", Name: "living-room"}, &thermoPilot))
+		return &thermoPilot
+	}
+
+	t.Run("suspend and resume", func(t *testing.T) {
+		out, c, err := run(t, []string{"suspend", "living-room"}, testThermoPilot())
+		require.NoError(t, err)
+		assert.Equal(t, "thermopilot/living-room suspended\n", out)
+		assert.True(t, get(t, c).Spec.Suspend)
+		assert.Equal(t, "27.2", get(t, c).Status.CurrentTemperature, "status must be left alone")
+
+		suspended := testThermoPilot()
+		suspended.Spec.Suspend = true
+		out, c, err = run(t, []string{"resume", "living-room"}, suspended)
+		require.NoError(t, err)
+		assert.Equal(t, "thermopilot/living-room resumed\n", out)
+		assert.False(t, get(t, c).Spec.Suspend)
+	})
+
+	t.Run("resume when not suspended", func(t *testing.T) {
+		out, _, err := run(t, []string{"resume", "living-room"}, testThermoPilot())
+		require.NoError(t, err)
+		assert.Equal(t, "thermopilot/living-room unchanged\n", out)
+	})
+
+	t.Run("boost", func(t *testing.T) {
+		_, c, err := run(t, []string{"boost", "living-room", "--by", "2", "--for", "45m"}, testThermoPilot())
+		require.NoError(t, err)
+		override := get(t, c).Spec.Override
+		require.NotNil(t, override)
+		assert.Equal(t, "23.0", override.TargetTemperature)
+		assert.True(t, override.ExpiresAt.Equal(&metav1.Time{Time: testNow.Add(45 * time.Minute)}))
+	})
+
+	t.Run("boost requires a target", func(t *testing.T) {
+		_, _, err := run(t, []string{"boost", "living-room"}, testThermoPilot())
+		require.Error(t, err)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, _, err := run(t, []string{"suspend", "bedroom"}, testThermoPilot())
+		require.Error(t, err)
+	})
+}
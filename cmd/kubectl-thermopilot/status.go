@@ -0,0 +1,49 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+	"github.com/seipan/thermo-pilot-controller/internal/cli"
+)
+
+func newStatusCommand(o *options) *cobra.Command {
+	var allNamespaces bool
+	cmd := &cobra.Command{
+		Use:   "status [NAME]",
+		Short: "Show the rooms with their temperature, humidity, last action and health",
+		Example: `  kubectl thermopilot status
+  kubectl thermopilot status living-room -o wide
+  kubectl thermopilot status -A -o json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, namespace, err := o.newClient()
+			if err != nil {
+				return err
+			}
+			var items []thermopilotv1.ThermoPilot
+			if len(args) == 1 {
+				var thermoPilot thermopilotv1.ThermoPilot
+				if err := c.Get(cmd.Context(), types.NamespacedName{Namespace: namespace, Name: args[0]}, &thermoPilot); err != nil {
+					return err
+				}
+				items = append(items, thermoPilot)
+			} else {
+				var opts []client.ListOption
+				if !allNamespaces {
+					opts = append(opts, client.InNamespace(namespace))
+				}
+				var list thermopilotv1.ThermoPilotList
+				if err := c.List(cmd.Context(), &list, opts...); err != nil {
+					return err
+				}
+				items = list.Items
+			}
+			return cli.PrintThermoPilots(cmd.OutOrStdout(), o.output, items, allNamespaces, o.now())
+		},
+	}
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "List the ThermoPilots of every namespace.")
+	return cmd
+}
@@ -19,13 +19,16 @@ package main
 import (
 	"crypto/tls"
 	"flag"
+	"net/url"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+	switchbotclient "github.com/seipan/thermo-pilot-controller/internal/client"
 	"github.com/seipan/thermo-pilot-controller/internal/controller"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -58,6 +61,9 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var dryRun bool
+	var switchBotAPIURL, switchBotProxy, switchBotUserAgent string
+	var switchBotTimeout time.Duration
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -73,6 +79,16 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.BoolVar(&dryRun, "dry-run", false,
+		"If set, decisions are computed and recorded for every ThermoPilot but no commands are sent to air conditioners")
+	flag.StringVar(&switchBotAPIURL, "switchbot-api-url", switchbotclient.DefaultBaseURL,
+		"The SwitchBot API endpoint, for example a local mock server.")
+	flag.DurationVar(&switchBotTimeout, "switchbot-timeout", switchbotclient.DefaultTimeout,
+		"The timeout of a single SwitchBot API request. 0 disables the timeout.")
+	flag.StringVar(&switchBotProxy, "switchbot-proxy", "",
+		"If set, SwitchBot API requests are sent through this HTTP proxy instead of the one from the environment.")
+	flag.StringVar(&switchBotUserAgent, "switchbot-user-agent", switchbotclient.DefaultUserAgent,
+		"The User-Agent sent with SwitchBot API requests.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -154,13 +170,51 @@ func main() {
 		os.Exit(1)
 	}
 
+	clientOpts := []switchbotclient.Option{
+		switchbotclient.WithBaseURL(switchBotAPIURL),
+		switchbotclient.WithTimeout(switchBotTimeout),
+		switchbotclient.WithUserAgent(switchBotUserAgent),
+	}
+	if switchBotProxy != "" {
+		proxyURL, err := url.Parse(switchBotProxy)
+		if err != nil {
+			setupLog.Error(err, "invalid SwitchBot proxy URL", "proxy", switchBotProxy)
+			os.Exit(1)
+		}
+		clientOpts = append(clientOpts, switchbotclient.WithProxy(proxyURL))
+	}
+
+	quota := controller.NewQuotaTracker()
 	if err := (&controller.ThermoPilotReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:        mgr.GetClient(),
+		APIReader:     mgr.GetAPIReader(),
+		Scheme:        mgr.GetScheme(),
+		Quota:         quota,
+		Recorder:      mgr.GetEventRecorderFor("thermopilot-controller"),
+		ClientOptions: clientOpts,
+		DryRun:        dryRun,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ThermoPilot")
 		os.Exit(1)
 	}
+	if err := (&controller.SwitchBotAccountReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		Quota:         quota,
+		ClientOptions: clientOpts,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SwitchBotAccount")
+		os.Exit(1)
+	}
+	if err := (&controller.ClusterSwitchBotAccountReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		Quota:         quota,
+		ClientOptions: clientOpts,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterSwitchBotAccount")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
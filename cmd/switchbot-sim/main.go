@@ -0,0 +1,56 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command switchbot-sim serves a simulated SwitchBot API v1.1 backed by a thermal
+// model of the configured rooms. Point the manager at it with
+// --switchbot-api-url=http://<address>/v1.1.
+package main
+
+import (
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/seipan/thermo-pilot-controller/internal/simulator"
+)
+
+func main() {
+	var configPath, bindAddress string
+	flag.StringVar(&configPath, "config", "switchbot-sim.yaml", "Path to the simulator configuration file.")
+	flag.StringVar(&bindAddress, "bind-address", ":8080", "The address the simulated API binds to.")
+	flag.Parse()
+
+	cfg, err := simulator.LoadConfig(configPath)
+	if err != nil {
+		log.Fatalf("unable to load config: %v", err)
+	}
+	sim, err := simulator.New(cfg, nil)
+	if err != nil {
+		log.Fatalf("invalid config: %v", err)
+	}
+
+	server := &http.Server{
+		Addr:              bindAddress,
+		Handler:           sim.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Printf("serving simulated SwitchBot API on %s (%d rooms, time scale %gx)", bindAddress, len(cfg.Rooms), cfg.TimeScale)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("server failed: %v", err)
+	}
+}
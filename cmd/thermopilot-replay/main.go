@@ -0,0 +1,193 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command thermopilot-replay runs the controller's decision logic against a
+// recorded temperature trace and reports the command timeline together with
+// comfort and runtime statistics. Passing --spec several times compares the
+// specs on the same trace.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+	"github.com/seipan/thermo-pilot-controller/internal/calendar"
+	"github.com/seipan/thermo-pilot-controller/internal/replay"
+)
+
+type specFlags []string
+
+func (s *specFlags) String() string     { return strings.Join(*s, ",") }
+func (s *specFlags) Set(v string) error { *s = append(*s, v); return nil }
+
+func main() {
+	var specs specFlags
+	var tracePath, calendarPath, sensorID, format, output string
+	var interval time.Duration
+	var verbose bool
+	flag.Var(&specs, "spec", "Path to a ThermoPilot manifest. Repeat to compare several specs.")
+	flag.StringVar(&tracePath, "trace", "", "Path to the recorded trace (CSV or JSON lines).")
+	flag.StringVar(&calendarPath, "calendar", "", "Path to the iCalendar feed of specs with an occupancy schedule.")
+	flag.StringVar(&sensorID, "sensor-id", "", "Device ID of the sensor the trace was recorded from, to apply its calibration offset.")
+	flag.StringVar(&format, "format", "", "Trace format, csv or jsonl. Guessed from the file extension when empty.")
+	flag.StringVar(&output, "output", "text", "Output format, text or json.")
+	flag.DurationVar(&interval, "interval", 0, "Interval between decisions. Defaults to the controller's requeue interval.")
+	flag.BoolVar(&verbose, "verbose", false, "Print every decision instead of only those that send commands.")
+	flag.Parse()
+
+	if err := run(os.Stdout, specs, tracePath, calendarPath, sensorID, format, output, interval, verbose); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(w io.Writer, specs []string, tracePath, calendarPath, sensorID, format, output string, interval time.Duration, verbose bool) error {
+	if len(specs) == 0 || tracePath == "" {
+		return errors.New("--spec and --trace are required")
+	}
+	if format == "" {
+		format = replay.FormatFromPath(tracePath)
+	}
+	f, err := os.Open(tracePath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	samples, err := replay.ReadTrace(f, format)
+	if err != nil {
+		return fmt.Errorf("failed to read trace %s: %w", tracePath, err)
+	}
+
+	results := make([]*replay.Result, 0, len(specs))
+	for _, path := range specs {
+		name, spec, err := loadSpec(path)
+		if err != nil {
+			return err
+		}
+		cal, err := loadCalendar(calendarPath, spec)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		result, err := replay.Run(name, spec, samples, replay.Options{Interval: interval, Calendar: cal, SensorID: sensorID})
+		if err != nil {
+			return fmt.Errorf("failed to replay %s: %w", name, err)
+		}
+		results = append(results, result)
+	}
+
+	switch output {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	case "text":
+		printText(w, results, verbose)
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format: %s", output)
+	}
+}
+
+// loadSpec reads the ThermoPilot manifest at path and returns its name and spec.
+func loadSpec(path string) (string, thermopilotv1.ThermoPilotSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", thermopilotv1.ThermoPilotSpec{}, err
+	}
+	var thermoPilot thermopilotv1.ThermoPilot
+	if err := yaml.Unmarshal(data, &thermoPilot); err != nil {
+		return "", thermopilotv1.ThermoPilotSpec{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if thermoPilot.Kind != "" && thermoPilot.Kind != "ThermoPilot" {
+		return "", thermopilotv1.ThermoPilotSpec{}, fmt.Errorf("%s: expected a ThermoPilot, got %s", path, thermoPilot.Kind)
+	}
+	name := thermoPilot.Name
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return name, thermoPilot.Spec, nil
+}
+
+// loadCalendar parses the feed at path in the time zone of the occupancy schedule
+// of spec, returning nil when spec has none.
+func loadCalendar(path string, spec thermopilotv1.ThermoPilotSpec) (*calendar.Calendar, error) {
+	if spec.Occupancy == nil {
+		return nil, nil
+	}
+	if path == "" {
+		return nil, errors.New("--calendar is required for specs with an occupancy schedule")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	zone := spec.Occupancy.TimeZone
+	if zone == "" {
+		zone = "UTC"
+	}
+	location, err := time.LoadLocation(zone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid occupancy time zone %q", spec.Occupancy.TimeZone)
+	}
+	cal, err := calendar.Parse(string(data), location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse calendar %s: %w", path, err)
+	}
+	return cal, nil
+}
+
+func printText(w io.Writer, results []*replay.Result, verbose bool) {
+	for _, result := range results {
+		_, _ = fmt.Fprintf(w, "== %s\n", result.Name)
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "TIME\tCURRENT\tFILTERED\tTARGET\tACTION\tSETPOINT\tMODE\tSENT")
+		for _, step := range result.Steps {
+			if !verbose && !step.Sent {
+				continue
+			}
+			filtered := "-"
+			if step.Filtered != nil {
+				filtered = fmt.Sprintf("%.2f", *step.Filtered)
+			}
+			_, _ = fmt.Fprintf(tw, "%s\t%.1f\t%s\t%.1f\t%s\t%.1f\t%s\t%t\n",
+				step.Time.Format(time.RFC3339), step.Temperature, filtered, step.TargetTemperature,
+				step.Action, step.Setpoint, step.Mode, step.Sent)
+		}
+		_ = tw.Flush()
+		_, _ = fmt.Fprintln(w)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "SPEC\tDURATION\tDECISIONS\tCOMMANDS\tWITHIN\tMAE\tMAX DEV\tDEG-H OUT\tRUNTIME")
+	for _, result := range results {
+		s := result.Stats
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%.1f%%\t%.2f\t%.2f\t%.2f\t%s\n",
+			result.Name, s.Duration, s.Decisions, s.Commands, s.WithinThreshold*100,
+			s.MeanAbsoluteError, s.MaxDeviation, s.DegreeHoursOutside, s.Runtime)
+	}
+	_ = tw.Flush()
+}
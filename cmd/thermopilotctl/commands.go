@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+	"github.com/seipan/thermo-pilot-controller/internal/cli"
+	switchbotclient "github.com/seipan/thermo-pilot-controller/internal/client"
+)
+
+func newCredentialsCommand(o *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "credentials",
+		Short: "Inspect SwitchBot credentials",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "validate",
+		Short: "Check that the SwitchBot API accepts the credentials",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			sbClient, err := o.switchBotClient(cmd.Context())
+			if err != nil {
+				return err
+			}
+			devices, err := sbClient.ListDevices(cmd.Context())
+			if switchbotclient.IsUnauthorized(err) {
+				return fmt.Errorf("credentials were rejected: %w", err)
+			}
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "Credentials are valid: %d devices, %d infrared remotes\n",
+				len(devices.Body.DeviceList), len(devices.Body.InfraredRemoteList))
+			return err
+		},
+	})
+	return cmd
+}
+
+// deviceRow is a device or infrared remote as printed by "devices list".
+type deviceRow struct {
+	DeviceID    string `json:"deviceId"`
+	DeviceName  string `json:"deviceName"`
+	DeviceType  string `json:"deviceType"`
+	Infrared    bool   `json:"infrared"`
+	HubDeviceID string `json:"hubDeviceId,omitempty"`
+}
+
+func newDevicesCommand(o *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "devices",
+		Short: "Inspect the devices of the account",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List devices and infrared remotes",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			sbClient, err := o.switchBotClient(cmd.Context())
+			if err != nil {
+				return err
+			}
+			devices, err := sbClient.ListDevices(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return printDevices(cmd.OutOrStdout(), o.output, deviceRows(devices))
+		},
+	}, &cobra.Command{
+		Use:   "status DEVICE_ID",
+		Short: "Show the status reported by a device",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sbClient, err := o.switchBotClient(cmd.Context())
+			if err != nil {
+				return err
+			}
+			status, err := sbClient.GetDeviceStatus(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			if o.output == cli.OutputYAML {
+				var v any
+				if err := json.Unmarshal(status, &v); err != nil {
+					return err
+				}
+				return cli.PrintStructured(cmd.OutOrStdout(), cli.OutputYAML, v)
+			}
+			var out bytes.Buffer
+			if err := json.Indent(&out, status, "", "  "); err != nil {
+				return err
+			}
+			out.WriteByte('\n')
+			_, err = out.WriteTo(cmd.OutOrStdout())
+			return err
+		},
+	})
+	return cmd
+}
+
+func deviceRows(devices *switchbotclient.ListDeviceResponse) []deviceRow {
+	rows := make([]deviceRow, 0, len(devices.Body.DeviceList)+len(devices.Body.InfraredRemoteList))
+	for _, d := range devices.Body.DeviceList {
+		rows = append(rows, deviceRow{DeviceID: d.DeviceID, DeviceName: d.DeviceName, DeviceType: d.DeviceType, HubDeviceID: d.HubDeviceID})
+	}
+	for _, d := range devices.Body.InfraredRemoteList {
+		rows = append(rows, deviceRow{DeviceID: d.DeviceID, DeviceName: d.DeviceName, DeviceType: d.RemoteType, Infrared: true, HubDeviceID: d.HubDeviceID})
+	}
+	return rows
+}
+
+func printDevices(w io.Writer, format string, rows []deviceRow) error {
+	if cli.IsStructured(format) {
+		return cli.PrintStructured(w, format, rows)
+	}
+	table := cli.NewTable(w)
+	_, _ = fmt.Fprintln(table, "ID\tNAME\tTYPE\tINFRARED\tHUB")
+	for _, row := range rows {
+		_, _ = fmt.Fprintf(table, "%s\t%s\t%s\t%t\t%s\n",
+			row.DeviceID, row.DeviceName, row.DeviceType, row.Infrared, cli.ValueOrNone(row.HubDeviceID))
+	}
+	return table.Flush()
+}
+
+func newACCommand(o *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ac",
+		Short: "Send commands to an infrared air conditioner",
+	}
+
+	var temperature float64
+	var mode string
+	set := &cobra.Command{
+		Use:   "set DEVICE_ID",
+		Short: "Turn the air conditioner on with the given setpoint and mode",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			acMode, err := parseMode(mode)
+			if err != nil {
+				return err
+			}
+			sbClient, err := o.switchBotClient(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if err := sbClient.SetTemperature(cmd.Context(), args[0], temperature, acMode); err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "Set %s to %s°C (%s)\n", args[0], strconv.FormatFloat(temperature, 'f', 1, 64), mode)
+			return err
+		},
+	}
+	set.Flags().Float64Var(&temperature, "temperature", 0, "Setpoint in °C.")
+	set.Flags().StringVar(&mode, "mode", "cool", "Mode: auto, cool, dry, fan or heat.")
+	_ = set.MarkFlagRequired("temperature")
+
+	off := &cobra.Command{
+		Use:   "off DEVICE_ID",
+		Short: "Turn the air conditioner off",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sbClient, err := o.switchBotClient(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if err := sbClient.TurnOff(cmd.Context(), args[0]); err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "Turned %s off\n", args[0])
+			return err
+		},
+	}
+
+	cmd.AddCommand(set, off)
+	return cmd
+}
+
+func parseMode(mode string) (switchbotclient.AirConditionerMode, error) {
+	switch mode {
+	case "auto":
+		return switchbotclient.ModeAuto, nil
+	case "cool":
+		return switchbotclient.ModeCool, nil
+	case "dry":
+		return switchbotclient.ModeDry, nil
+	case "fan":
+		return switchbotclient.ModeFan, nil
+	case "heat":
+		return switchbotclient.ModeHeat, nil
+	default:
+		return 0, fmt.Errorf("unsupported mode: %s", mode)
+	}
+}
+
+func newStatusCommand(o *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status [NAME]",
+		Short: "Show the status of ThermoPilots in the cluster",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, namespace, err := o.kube.Client()
+			if err != nil {
+				return err
+			}
+			var items []thermopilotv1.ThermoPilot
+			if len(args) == 1 {
+				var thermoPilot thermopilotv1.ThermoPilot
+				if err := c.Get(cmd.Context(), types.NamespacedName{Name: args[0], Namespace: namespace}, &thermoPilot); err != nil {
+					return err
+				}
+				items = append(items, thermoPilot)
+			} else {
+				var list thermopilotv1.ThermoPilotList
+				if err := c.List(cmd.Context(), &list, client.InNamespace(namespace)); err != nil {
+					return err
+				}
+				items = list.Items
+			}
+			return cli.PrintThermoPilots(cmd.OutOrStdout(), o.output, items, false, time.Now())
+		},
+	}
+}
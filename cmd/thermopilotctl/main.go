@@ -0,0 +1,162 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command thermopilotctl talks to the SwitchBot API with the same client as the
+// controller, for troubleshooting credentials and devices without hand-signing
+// requests, and shows ThermoPilot status from the cluster.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+	"github.com/seipan/thermo-pilot-controller/internal/cli"
+	switchbotclient "github.com/seipan/thermo-pilot-controller/internal/client"
+	"github.com/seipan/thermo-pilot-controller/internal/controller"
+)
+
+// Environment variables holding the SwitchBot credentials.
+const (
+	envToken  = "SWITCHBOT_TOKEN"
+	envSecret = "SWITCHBOT_SECRET"
+)
+
+// options are the flags shared by every subcommand.
+type options struct {
+	kube       cli.KubeFlags
+	token      string
+	secret     string
+	secretRef  string
+	accountRef string
+	apiURL     string
+	timeout    time.Duration
+	output     string
+}
+
+func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func newRootCommand() *cobra.Command {
+	o := &options{}
+	root := &cobra.Command{
+		Use:           "thermopilotctl",
+		Short:         "Troubleshoot SwitchBot credentials, devices and ThermoPilots",
+		SilenceUsage:  true,
+		SilenceErrors: false,
+		PersistentPreRunE: func(*cobra.Command, []string) error {
+			if o.output != cli.OutputTable && o.output != cli.OutputWide && !cli.IsStructured(o.output) {
+				return fmt.Errorf("unsupported output format: %s", o.output)
+			}
+			return nil
+		},
+	}
+	flags := root.PersistentFlags()
+	o.kube.AddFlags(flags)
+	flags.StringVar(&o.token, "token", "", "SwitchBot API token. Defaults to $"+envToken+".")
+	flags.StringVar(&o.secret, "secret", "", "SwitchBot API secret. Defaults to $"+envSecret+".")
+	flags.StringVar(&o.secretRef, "secret-ref", "",
+		"Read the credentials from this Secret (token and secret keys) when --token/--secret are not set.")
+	flags.StringVar(&o.accountRef, "account-ref", "",
+		"Read the credentials from a SwitchBotAccount or ClusterSwitchBotAccount, as NAME or KIND/NAME.")
+	flags.StringVar(&o.apiURL, "api-url", switchbotclient.DefaultBaseURL, "The SwitchBot API endpoint.")
+	flags.DurationVar(&o.timeout, "timeout", switchbotclient.DefaultTimeout, "The timeout of a single API request.")
+	flags.StringVarP(&o.output, "output", "o", cli.OutputTable, "Output format: table, wide, json or yaml.")
+
+	root.AddCommand(
+		newCredentialsCommand(o),
+		newDevicesCommand(o),
+		newACCommand(o),
+		newStatusCommand(o),
+	)
+	return root
+}
+
+// switchBotClient builds an API client from the credentials selected by the flags.
+func (o *options) switchBotClient(ctx context.Context) (*switchbotclient.Client, error) {
+	creds, err := o.credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return switchbotclient.NewClient(creds.Token, creds.Secret,
+		switchbotclient.WithBaseURL(o.apiURL),
+		switchbotclient.WithTimeout(o.timeout),
+		switchbotclient.WithUserAgent("thermopilotctl"),
+	), nil
+}
+
+// credentials resolves the credentials from the flags, then the environment, then
+// the referenced Secret or account in the cluster.
+func (o *options) credentials(ctx context.Context) (*controller.SwitchBotCredentials, error) {
+	token, secret := firstNonEmpty(o.token, os.Getenv(envToken)), firstNonEmpty(o.secret, os.Getenv(envSecret))
+	if token != "" || secret != "" {
+		if token == "" || secret == "" {
+			return nil, errors.New("both a token and a secret are required")
+		}
+		return &controller.SwitchBotCredentials{Token: token, Secret: secret}, nil
+	}
+
+	spec, err := o.credentialsSpec()
+	if err != nil {
+		return nil, err
+	}
+	c, namespace, err := o.kube.Client()
+	if err != nil {
+		return nil, err
+	}
+	return controller.GetSwitchBotCredentials(ctx, c, spec, namespace)
+}
+
+// credentialsSpec expresses --secret-ref or --account-ref the way a ThermoPilot would.
+func (o *options) credentialsSpec() (thermopilotv1.ThermoPilotSpec, error) {
+	var spec thermopilotv1.ThermoPilotSpec
+	switch {
+	case o.secretRef != "" && o.accountRef != "":
+		return spec, errors.New("--secret-ref and --account-ref are mutually exclusive")
+	case o.secretRef != "":
+		spec.SecretRef = thermopilotv1.SecretReference{Name: o.secretRef}
+	case o.accountRef != "":
+		kind, name, found := strings.Cut(o.accountRef, "/")
+		if !found {
+			kind, name = controller.KindSwitchBotAccount, o.accountRef
+		}
+		if kind != controller.KindSwitchBotAccount && kind != controller.KindClusterSwitchBotAccount {
+			return spec, fmt.Errorf("unsupported account kind: %s", kind)
+		}
+		spec.AccountRef = &thermopilotv1.AccountReference{Kind: kind, Name: name}
+	default:
+		return spec, fmt.Errorf("no credentials: set --token and --secret, $%s and $%s, --secret-ref or --account-ref", envToken, envSecret)
+	}
+	return spec, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
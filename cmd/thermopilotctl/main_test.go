@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+	"github.com/seipan/thermo-pilot-controller/internal/controller"
+)
+
+func TestOptions_credentialsSpec(t *testing.T) {
+	tests := []struct {
+		name       string
+		options    options
+		want       thermopilotv1.ThermoPilotSpec
+		wantErrMsg string
+	}{
+		{
+			name:    "secret reference",
+			options: options{secretRef: "switchbot-credentials"},
+			want:    thermopilotv1.ThermoPilotSpec{SecretRef: thermopilotv1.SecretReference{Name: "switchbot-credentials"}},
+		},
+		{
+			name:    "account name defaults to SwitchBotAccount",
+			options: options{accountRef: "home"},
+			want: thermopilotv1.ThermoPilotSpec{
+				AccountRef: &thermopilotv1.AccountReference{Kind: controller.KindSwitchBotAccount, Name: "home"},
+			},
+		},
+		{
+			name:    "cluster account",
+			options: options{accountRef: "ClusterSwitchBotAccount/home"},
+			want: thermopilotv1.ThermoPilotSpec{
+				AccountRef: &thermopilotv1.AccountReference{Kind: controller.KindClusterSwitchBotAccount, Name: "home"},
+			},
+		},
+		{
+			name:       "unsupported kind",
+			options:    options{accountRef: "Secret/home"},
+			wantErrMsg: "unsupported account kind",
+		},
+		{
+			name:       "both references",
+			options:    options{secretRef: "a", accountRef: "b"},
+			wantErrMsg: "mutually exclusive",
+		},
+		{
+			name:       "no credentials",
+			wantErrMsg: "no credentials",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.options.credentialsSpec()
+			if tt.wantErrMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestOptions_credentials(t *testing.T) {
+	t.Run("flags take precedence over the environment", func(t *testing.T) {
+		t.Setenv(envToken, "env-token")
+		t.Setenv(envSecret, "env-secret")
+		o := options{token: "flag-token"}
+		creds, err := o.credentials(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "flag-token", creds.Token)
+		assert.Equal(t, "env-secret", creds.Secret)
+	})
+
+	t.Run("token without secret", func(t *testing.T) {
+		t.Setenv(envToken, "")
+		t.Setenv(envSecret, "")
+		o := options{token: "flag-token"}
+		_, err := o.credentials(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "both a token and a secret are required")
+	})
+}
@@ -0,0 +1,413 @@
+// Package calendar parses iCalendar (RFC 5545) feeds, such as those exported by
+// booking systems, and expands their events, recurring ones included, into the
+// busy periods of a time range. It has no dependency on the Kubernetes API.
+package calendar
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is a single busy occurrence of a calendar event.
+type Event struct {
+	Summary string
+	Start   time.Time
+	End     time.Time
+}
+
+// Calendar is a parsed iCalendar feed.
+type Calendar struct {
+	events []event
+}
+
+// event is a VEVENT, either standalone, the master of a recurrence set or an
+// override of one of its occurrences.
+type event struct {
+	uid     string
+	summary string
+	start   time.Time
+	// allDay events last whole days, which are not always 24 hours long
+	allDay   bool
+	days     int
+	duration time.Duration
+	rule     *rule
+	rdates   []time.Time
+	exdates  []time.Time
+	// recurrenceID is the original start of the occurrence an override replaces
+	recurrenceID time.Time
+	// free is set for cancelled and transparent events, which do not make the
+	// room busy but still replace the occurrence they override
+	free bool
+	// overridden are the original starts of the occurrences replaced by overrides
+	overridden []time.Time
+}
+
+// property is a content line: NAME;PARAM=VALUE:VALUE.
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// component is a BEGIN/END block with its properties and nested components.
+type component struct {
+	name       string
+	properties []property
+	children   []*component
+}
+
+func (c *component) property(name string) (property, bool) {
+	for _, p := range c.properties {
+		if p.name == name {
+			return p, true
+		}
+	}
+	return property{}, false
+}
+
+func (c *component) value(name string) string {
+	p, _ := c.property(name)
+	return p.value
+}
+
+// Parse parses an iCalendar feed. Times without a time zone, and all-day dates,
+// are in location. Time zones are resolved by their IANA name, or else from a
+// VTIMEZONE of the feed with a single offset.
+func Parse(data string, location *time.Location) (*Calendar, error) {
+	root, err := parseComponents(data)
+	if err != nil {
+		return nil, err
+	}
+	var vcalendar *component
+	for _, child := range root.children {
+		if child.name == "VCALENDAR" {
+			vcalendar = child
+			break
+		}
+	}
+	if vcalendar == nil {
+		return nil, errors.New("no VCALENDAR found")
+	}
+
+	zones := &zones{defaultLocation: location, definitions: map[string]*component{}, resolved: map[string]*time.Location{}}
+	for _, child := range vcalendar.children {
+		if child.name == "VTIMEZONE" {
+			zones.definitions[child.value("TZID")] = child
+		}
+	}
+	calendar := &Calendar{}
+	for _, child := range vcalendar.children {
+		if child.name != "VEVENT" {
+			continue
+		}
+		e, err := parseEvent(child, zones)
+		if err != nil {
+			if uid := child.value("UID"); uid != "" {
+				return nil, fmt.Errorf("event %s: %w", uid, err)
+			}
+			return nil, err
+		}
+		calendar.events = append(calendar.events, e)
+	}
+	for i := range calendar.events {
+		override := calendar.events[i]
+		if override.recurrenceID.IsZero() {
+			continue
+		}
+		for j := range calendar.events {
+			master := &calendar.events[j]
+			if master.uid == override.uid && master.recurrenceID.IsZero() {
+				master.overridden = append(master.overridden, override.recurrenceID)
+			}
+		}
+	}
+	return calendar, nil
+}
+
+// Between returns the busy occurrences overlapping [from, to), sorted by start.
+func (c *Calendar) Between(from, to time.Time) []Event {
+	var events []Event
+	for _, e := range c.events {
+		if e.free {
+			continue
+		}
+		e.occurrences(from.Add(-e.span()), to, func(start time.Time) {
+			end := e.end(start)
+			if end.After(from) && start.Before(to) {
+				events = append(events, Event{Summary: e.summary, Start: start, End: end})
+			}
+		})
+	}
+	slices.SortFunc(events, func(a, b Event) int {
+		if order := a.Start.Compare(b.Start); order != 0 {
+			return order
+		}
+		return a.End.Compare(b.End)
+	})
+	return events
+}
+
+// end returns the end of the occurrence starting at start.
+func (e *event) end(start time.Time) time.Time {
+	if e.allDay {
+		return start.AddDate(0, 0, e.days)
+	}
+	return start.Add(e.duration)
+}
+
+// span returns the longest an occurrence of e lasts.
+func (e *event) span() time.Duration {
+	if e.allDay {
+		// a day is at most 25 hours long across daylight saving time
+		return time.Duration(e.days) * 25 * time.Hour
+	}
+	return e.duration
+}
+
+// occurrences calls yield with the start of every occurrence of e before to.
+// Recurrences starting before since may be skipped.
+func (e *event) occurrences(since, to time.Time, yield func(time.Time)) {
+	emit := func(start time.Time) {
+		if containsTime(e.exdates, start) || containsTime(e.overridden, start) {
+			return
+		}
+		yield(start)
+	}
+	if e.rule == nil {
+		if e.start.Before(to) {
+			emit(e.start)
+		}
+	} else {
+		e.rule.expand(e.start, since, to, emit)
+	}
+	for _, rdate := range e.rdates {
+		if rdate.Before(to) && !rdate.Equal(e.start) {
+			emit(rdate)
+		}
+	}
+}
+
+func containsTime(times []time.Time, t time.Time) bool {
+	return slices.ContainsFunc(times, t.Equal)
+}
+
+// parseComponents unfolds the content lines of data and nests them into components.
+func parseComponents(data string) (*component, error) {
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	root := &component{}
+	stack := []*component{root}
+	for number, line := range lines {
+		p, err := parseProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", number+1, err)
+		}
+		current := stack[len(stack)-1]
+		switch p.name {
+		case "BEGIN":
+			child := &component{name: strings.ToUpper(p.value)}
+			current.children = append(current.children, child)
+			stack = append(stack, child)
+		case "END":
+			if len(stack) == 1 || current.name != strings.ToUpper(p.value) {
+				return nil, fmt.Errorf("line %d: unexpected END:%s", number+1, p.value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			current.properties = append(current.properties, p)
+		}
+	}
+	if len(stack) != 1 {
+		return nil, fmt.Errorf("%s is not closed", stack[len(stack)-1].name)
+	}
+	return root, nil
+}
+
+// parseProperty parses an unfolded content line.
+func parseProperty(line string) (property, error) {
+	colon, quoted := -1, false
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return property{}, fmt.Errorf("invalid content line %q", line)
+	}
+	parts := splitUnquoted(line[:colon], ';')
+	p := property{name: strings.ToUpper(parts[0]), params: map[string]string{}, value: line[colon+1:]}
+	for _, param := range parts[1:] {
+		name, value, ok := strings.Cut(param, "=")
+		if !ok {
+			return property{}, fmt.Errorf("invalid parameter %q", param)
+		}
+		p.params[strings.ToUpper(name)] = strings.Trim(value, `"`)
+	}
+	return p, nil
+}
+
+func splitUnquoted(s string, sep rune) []string {
+	var parts []string
+	start, quoted := 0, false
+	for i, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// parseEvent interprets a VEVENT.
+func parseEvent(c *component, zones *zones) (event, error) {
+	e := event{uid: c.value("UID"), summary: unescape(c.value("SUMMARY"))}
+	status, transparency := strings.ToUpper(c.value("STATUS")), strings.ToUpper(c.value("TRANSP"))
+	e.free = status == "CANCELLED" || transparency == "TRANSPARENT"
+
+	dtstart, ok := c.property("DTSTART")
+	if !ok {
+		return event{}, errors.New("DTSTART is missing")
+	}
+	start, allDay, err := zones.parseTime(dtstart)
+	if err != nil {
+		return event{}, fmt.Errorf("invalid DTSTART: %w", err)
+	}
+	e.start, e.allDay = start, allDay
+
+	if dtend, ok := c.property("DTEND"); ok {
+		end, _, err := zones.parseTime(dtend)
+		if err != nil {
+			return event{}, fmt.Errorf("invalid DTEND: %w", err)
+		}
+		if end.Before(start) {
+			return event{}, errors.New("DTEND is before DTSTART")
+		}
+		if allDay {
+			e.days = int(end.Sub(start).Round(24*time.Hour) / (24 * time.Hour))
+		} else {
+			e.duration = end.Sub(start)
+		}
+	} else if duration, ok := c.property("DURATION"); ok {
+		days, length, err := parseDuration(duration.value)
+		if err != nil {
+			return event{}, err
+		}
+		if allDay && length == 0 {
+			e.days = days
+		} else {
+			e.allDay = false
+			e.duration = time.Duration(days)*24*time.Hour + length
+		}
+	} else if allDay {
+		// a date without an end lasts the day
+		e.days = 1
+	}
+
+	if rrule, ok := c.property("RRULE"); ok {
+		if e.rule, err = parseRule(rrule.value, start.Location()); err != nil {
+			return event{}, fmt.Errorf("invalid RRULE: %w", err)
+		}
+	}
+	for _, p := range c.properties {
+		switch p.name {
+		case "EXDATE", "RDATE":
+			if strings.EqualFold(p.params["VALUE"], "PERIOD") {
+				return event{}, errors.New("RDATE periods are not supported")
+			}
+			for _, value := range strings.Split(p.value, ",") {
+				t, _, err := zones.parseTime(property{name: p.name, params: p.params, value: value})
+				if err != nil {
+					return event{}, fmt.Errorf("invalid %s: %w", p.name, err)
+				}
+				if p.name == "EXDATE" {
+					e.exdates = append(e.exdates, t)
+				} else {
+					e.rdates = append(e.rdates, t)
+				}
+			}
+		case "RECURRENCE-ID":
+			if e.recurrenceID, _, err = zones.parseTime(p); err != nil {
+				return event{}, fmt.Errorf("invalid RECURRENCE-ID: %w", err)
+			}
+		}
+	}
+	return e, nil
+}
+
+// parseDuration parses a DURATION value such as PT1H30M or P1D, returning the
+// whole days and the rest separately.
+func parseDuration(value string) (int, time.Duration, error) {
+	invalid := fmt.Errorf("invalid DURATION %q", value)
+	s := strings.TrimPrefix(value, "+")
+	if strings.HasPrefix(s, "-") {
+		return 0, 0, invalid
+	}
+	s, ok := strings.CutPrefix(s, "P")
+	if !ok || s == "" {
+		return 0, 0, invalid
+	}
+	var days int
+	var length time.Duration
+	inTime := false
+	for s != "" {
+		if s[0] == 'T' {
+			inTime, s = true, s[1:]
+			continue
+		}
+		i := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+		if i <= 0 {
+			return 0, 0, invalid
+		}
+		n, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return 0, 0, invalid
+		}
+		switch unit := s[i]; {
+		case !inTime && unit == 'W':
+			days += 7 * n
+		case !inTime && unit == 'D':
+			days += n
+		case inTime && unit == 'H':
+			length += time.Duration(n) * time.Hour
+		case inTime && unit == 'M':
+			length += time.Duration(n) * time.Minute
+		case inTime && unit == 'S':
+			length += time.Duration(n) * time.Second
+		default:
+			return 0, 0, invalid
+		}
+		s = s[i+1:]
+	}
+	return days, length, nil
+}
+
+// unescape resolves the escapes of a TEXT value.
+func unescape(value string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
@@ -0,0 +1,173 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// feed wraps events into a VCALENDAR with CRLF line endings.
+func feed(lines ...string) string {
+	all := append(append([]string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//test//EN"}, lines...), "END:VCALENDAR")
+	return strings.Join(all, "\r\n") + "\r\n"
+}
+
+func TestParse_Between(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	at := func(location *time.Location, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2025, month, day, hour, minute, 0, 0, location)
+	}
+	tests := []struct {
+		name     string
+		data     string
+		from, to time.Time
+		want     []Event
+	}{
+		{
+			name: "single event in UTC with a folded summary",
+			data: feed("BEGIN:VEVENT", "UID:1", "SUMMARY:Board", " meeting\\, Q1", "DTSTART:20250106T010000Z", "DTEND:20250106T020000Z", "END:VEVENT"),
+			from: at(time.UTC, 1, 6, 0, 0), to: at(time.UTC, 1, 7, 0, 0),
+			want: []Event{{Summary: "Boardmeeting, Q1", Start: at(time.UTC, 1, 6, 1, 0), End: at(time.UTC, 1, 6, 2, 0)}},
+		},
+		{
+			name: "weekly on several days with TZID",
+			data: feed("BEGIN:VEVENT", "UID:2", "SUMMARY:Standup", "DTSTART;TZID=Asia/Tokyo:20250106T100000", "DURATION:PT30M",
+				"RRULE:FREQ=WEEKLY;BYDAY=MO,WE", "END:VEVENT"),
+			from: at(tokyo, 1, 13, 0, 0), to: at(tokyo, 1, 16, 0, 0),
+			want: []Event{
+				{Summary: "Standup", Start: at(tokyo, 1, 13, 10, 0), End: at(tokyo, 1, 13, 10, 30)},
+				{Summary: "Standup", Start: at(tokyo, 1, 15, 10, 0), End: at(tokyo, 1, 15, 10, 30)},
+			},
+		},
+		{
+			name: "daily keeps the wall clock across daylight saving time",
+			data: feed("BEGIN:VEVENT", "UID:3", "DTSTART;TZID=/mozilla.org/20050126_1/Europe/Berlin:20250328T090000",
+				"DTEND;TZID=/mozilla.org/20050126_1/Europe/Berlin:20250328T100000", "RRULE:FREQ=DAILY;COUNT=3", "END:VEVENT"),
+			from: at(berlin, 3, 1, 0, 0), to: at(berlin, 4, 1, 0, 0),
+			want: []Event{
+				{Start: at(berlin, 3, 28, 9, 0), End: at(berlin, 3, 28, 10, 0)},
+				{Start: at(berlin, 3, 29, 9, 0), End: at(berlin, 3, 29, 10, 0)},
+				{Start: at(berlin, 3, 30, 9, 0), End: at(berlin, 3, 30, 10, 0)},
+			},
+		},
+		{
+			name: "exceptions, moved and cancelled occurrences",
+			data: feed(
+				"BEGIN:VEVENT", "UID:4", "SUMMARY:Sync", "DTSTART:20250106T090000Z", "DTEND:20250106T100000Z",
+				"RRULE:FREQ=DAILY;UNTIL=20250110", "EXDATE:20250107T090000Z", "END:VEVENT",
+				"BEGIN:VEVENT", "UID:4", "SUMMARY:Sync (moved)", "RECURRENCE-ID:20250108T090000Z",
+				"DTSTART:20250108T140000Z", "DTEND:20250108T150000Z", "END:VEVENT",
+				"BEGIN:VEVENT", "UID:4", "RECURRENCE-ID:20250109T090000Z", "STATUS:CANCELLED",
+				"DTSTART:20250109T090000Z", "DTEND:20250109T100000Z", "END:VEVENT",
+			),
+			from: at(time.UTC, 1, 1, 0, 0), to: at(time.UTC, 2, 1, 0, 0),
+			want: []Event{
+				{Summary: "Sync", Start: at(time.UTC, 1, 6, 9, 0), End: at(time.UTC, 1, 6, 10, 0)},
+				{Summary: "Sync (moved)", Start: at(time.UTC, 1, 8, 14, 0), End: at(time.UTC, 1, 8, 15, 0)},
+				{Summary: "Sync", Start: at(time.UTC, 1, 10, 9, 0), End: at(time.UTC, 1, 10, 10, 0)},
+			},
+		},
+		{
+			name: "monthly on the last Friday",
+			data: feed("BEGIN:VEVENT", "UID:5", "DTSTART:20250131T090000Z", "DURATION:PT1H", "RRULE:FREQ=MONTHLY;BYDAY=-1FR", "END:VEVENT"),
+			from: at(time.UTC, 2, 1, 0, 0), to: at(time.UTC, 4, 1, 0, 0),
+			want: []Event{
+				{Start: at(time.UTC, 2, 28, 9, 0), End: at(time.UTC, 2, 28, 10, 0)},
+				{Start: at(time.UTC, 3, 28, 9, 0), End: at(time.UTC, 3, 28, 10, 0)},
+			},
+		},
+		{
+			name: "all-day event in the default location",
+			data: feed("BEGIN:VEVENT", "UID:6", "DTSTART;VALUE=DATE:20250210", "DTEND;VALUE=DATE:20250212", "END:VEVENT"),
+			from: at(tokyo, 2, 11, 12, 0), to: at(tokyo, 2, 11, 13, 0),
+			want: []Event{{Start: at(tokyo, 2, 10, 0, 0), End: at(tokyo, 2, 12, 0, 0)}},
+		},
+		{
+			name: "transparent events are free",
+			data: feed("BEGIN:VEVENT", "UID:7", "TRANSP:TRANSPARENT", "DTSTART:20250106T010000Z", "DTEND:20250106T020000Z", "END:VEVENT"),
+			from: at(time.UTC, 1, 6, 0, 0), to: at(time.UTC, 1, 7, 0, 0),
+		},
+		{
+			name: "time zone defined by the feed",
+			data: feed("BEGIN:VTIMEZONE", "TZID:Tokyo Standard Time", "BEGIN:STANDARD", "DTSTART:16010101T000000",
+				"TZOFFSETFROM:+0900", "TZOFFSETTO:+0900", "END:STANDARD", "END:VTIMEZONE",
+				"BEGIN:VEVENT", "UID:8", "DTSTART;TZID=\"Tokyo Standard Time\":20250106T100000",
+				"DTEND;TZID=\"Tokyo Standard Time\":20250106T110000", "END:VEVENT"),
+			from: at(tokyo, 1, 6, 0, 0), to: at(tokyo, 1, 7, 0, 0),
+			want: []Event{{Start: at(tokyo, 1, 6, 10, 0), End: at(tokyo, 1, 6, 11, 0)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendar, err := Parse(tt.data, tokyo)
+			require.NoError(t, err)
+			got := calendar.Between(tt.from, tt.to)
+			require.Len(t, got, len(tt.want))
+			for i := range tt.want {
+				assert.Equal(t, tt.want[i].Summary, got[i].Summary)
+				assert.True(t, tt.want[i].Start.Equal(got[i].Start), "start %s, want %s", got[i].Start, tt.want[i].Start)
+				assert.True(t, tt.want[i].End.Equal(got[i].End), "end %s, want %s", got[i].End, tt.want[i].End)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "not a calendar", data: "hello"},
+		{name: "unclosed event", data: "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VCALENDAR\r\n"},
+		{name: "missing start", data: feed("BEGIN:VEVENT", "UID:1", "END:VEVENT")},
+		{name: "unknown time zone", data: feed("BEGIN:VEVENT", "UID:1", "DTSTART;TZID=Nowhere:20250106T100000", "END:VEVENT")},
+		{name: "unsupported rule", data: feed("BEGIN:VEVENT", "UID:1", "DTSTART:20250106T100000Z", "RRULE:FREQ=HOURLY", "END:VEVENT")},
+		{name: "unsupported rule part", data: feed("BEGIN:VEVENT", "UID:1", "DTSTART:20250106T100000Z", "RRULE:FREQ=DAILY;BYSETPOS=1", "END:VEVENT")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.data, time.UTC)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestExpand_SkipsPeriodsBeforeSince(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	start := time.Date(2000, 2, 29, 9, 0, 0, 0, tokyo)
+	since, to := time.Date(2025, 3, 30, 15, 0, 0, 0, time.UTC), time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	for _, value := range []string{
+		"FREQ=DAILY",
+		"FREQ=DAILY;INTERVAL=3",
+		"FREQ=WEEKLY;BYDAY=MO,TH;WKST=SU",
+		"FREQ=WEEKLY;INTERVAL=2",
+		"FREQ=MONTHLY;BYDAY=-1FR",
+		"FREQ=MONTHLY;INTERVAL=2",
+		"FREQ=YEARLY;BYMONTH=4,5;BYMONTHDAY=1",
+		"FREQ=YEARLY;UNTIL=20250101",
+	} {
+		t.Run(value, func(t *testing.T) {
+			r, err := parseRule(value, tokyo)
+			require.NoError(t, err)
+			var want, got []time.Time
+			r.expand(start, start, to, func(t time.Time) {
+				if !t.Before(since) {
+					want = append(want, t)
+				}
+			})
+			r.expand(start, since, to, func(t time.Time) {
+				if !t.Before(since) {
+					got = append(got, t)
+				}
+			})
+			assert.Equal(t, want, got)
+		})
+	}
+}
@@ -0,0 +1,316 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPeriods bounds the expansion of a rule counted from a start long ago that
+// matches no date, such as the 30th of February.
+const maxPeriods = 100000
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// weekdayNum is a BYDAY entry: a weekday, and for monthly and yearly rules
+// optionally which one of the month or year, counted from the end when negative.
+type weekdayNum struct {
+	n   int
+	day time.Weekday
+}
+
+// rule is a recurrence rule. FREQ DAILY, WEEKLY, MONTHLY and YEARLY are
+// supported, with INTERVAL, COUNT, UNTIL, BYDAY, BYMONTHDAY, BYMONTH and WKST.
+type rule struct {
+	freq       string
+	interval   int
+	count      int
+	until      time.Time
+	byDay      []weekdayNum
+	byMonthDay []int
+	byMonth    []time.Month
+	weekStart  time.Weekday
+}
+
+// parseRule parses an RRULE value of an event starting in location.
+func parseRule(value string, location *time.Location) (*rule, error) {
+	r := &rule{interval: 1, weekStart: time.Monday}
+	for _, part := range strings.Split(value, ";") {
+		name, arg, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid part %q", part)
+		}
+		var err error
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			r.freq = strings.ToUpper(arg)
+			if !slices.Contains([]string{"DAILY", "WEEKLY", "MONTHLY", "YEARLY"}, r.freq) {
+				return nil, fmt.Errorf("unsupported FREQ %s", arg)
+			}
+		case "INTERVAL":
+			if r.interval, err = strconv.Atoi(arg); err != nil || r.interval < 1 {
+				return nil, fmt.Errorf("invalid INTERVAL %s", arg)
+			}
+		case "COUNT":
+			if r.count, err = strconv.Atoi(arg); err != nil || r.count < 1 {
+				return nil, fmt.Errorf("invalid COUNT %s", arg)
+			}
+		case "UNTIL":
+			if r.until, err = parseUntil(arg, location); err != nil {
+				return nil, err
+			}
+		case "BYDAY":
+			for _, entry := range strings.Split(arg, ",") {
+				entry = strings.ToUpper(entry)
+				if len(entry) < 2 {
+					return nil, fmt.Errorf("invalid BYDAY %s", arg)
+				}
+				day, ok := weekdays[entry[len(entry)-2:]]
+				if !ok {
+					return nil, fmt.Errorf("invalid BYDAY %s", arg)
+				}
+				var n int
+				if ordinal := entry[:len(entry)-2]; ordinal != "" {
+					if n, err = strconv.Atoi(ordinal); err != nil || n == 0 || n < -53 || n > 53 {
+						return nil, fmt.Errorf("invalid BYDAY %s", arg)
+					}
+				}
+				r.byDay = append(r.byDay, weekdayNum{n: n, day: day})
+			}
+		case "BYMONTHDAY":
+			for _, entry := range strings.Split(arg, ",") {
+				day, err := strconv.Atoi(entry)
+				if err != nil || day == 0 || day < -31 || day > 31 {
+					return nil, fmt.Errorf("invalid BYMONTHDAY %s", arg)
+				}
+				r.byMonthDay = append(r.byMonthDay, day)
+			}
+		case "BYMONTH":
+			for _, entry := range strings.Split(arg, ",") {
+				month, err := strconv.Atoi(entry)
+				if err != nil || month < 1 || month > 12 {
+					return nil, fmt.Errorf("invalid BYMONTH %s", arg)
+				}
+				r.byMonth = append(r.byMonth, time.Month(month))
+			}
+			slices.Sort(r.byMonth)
+		case "WKST":
+			if r.weekStart, ok = weekdays[strings.ToUpper(arg)]; !ok {
+				return nil, fmt.Errorf("invalid WKST %s", arg)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported part %s", name)
+		}
+	}
+	if r.freq == "" {
+		return nil, errors.New("FREQ is missing")
+	}
+	if r.count > 0 && !r.until.IsZero() {
+		return nil, errors.New("COUNT and UNTIL are exclusive")
+	}
+	return r, nil
+}
+
+// parseUntil parses UNTIL. A date includes the whole day.
+func parseUntil(value string, location *time.Location) (time.Time, error) {
+	var until time.Time
+	var err error
+	switch {
+	case strings.HasSuffix(value, "Z"):
+		until, err = time.Parse("20060102T150405Z", value)
+	case strings.Contains(value, "T"):
+		until, err = time.ParseInLocation("20060102T150405", value, location)
+	default:
+		until, err = time.ParseInLocation("20060102", value, location)
+		until = until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid UNTIL %s", value)
+	}
+	return until, nil
+}
+
+// expand calls yield with every occurrence of the rule for an event starting at
+// start, in order, until to. Unless the rule counts its occurrences, the periods
+// before the one containing since are skipped, so that the cost does not grow
+// with the age of the event.
+func (r *rule) expand(start, since, to time.Time, yield func(time.Time)) {
+	location := start.Location()
+	hour, minute, second := start.Clock()
+	first := civil(start)
+	occurrences := 0
+	skip := 0
+	if r.count == 0 {
+		skip = max(0, r.periodsBefore(first, civil(since.In(location)))-1)
+	}
+	for period := skip; period < skip+maxPeriods; period++ {
+		periodStart, dates := r.period(first, period)
+		periodTime := time.Date(periodStart.Year(), periodStart.Month(), periodStart.Day(), 0, 0, 0, 0, location)
+		if !periodTime.Before(to) || (!r.until.IsZero() && periodTime.After(r.until)) {
+			return
+		}
+		for _, date := range dates {
+			t := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, second, start.Nanosecond(), location)
+			if t.Before(start) {
+				continue
+			}
+			if (!r.until.IsZero() && t.After(r.until)) || !t.Before(to) {
+				return
+			}
+			occurrences++
+			if r.count > 0 && occurrences > r.count {
+				return
+			}
+			yield(t)
+		}
+	}
+}
+
+// periodsBefore returns the number of whole periods of the rule from first to
+// day, both dates.
+func (r *rule) periodsBefore(first, day time.Time) int {
+	if !day.After(first) {
+		return 0
+	}
+	var periods int
+	switch r.freq {
+	case "DAILY":
+		periods = int(day.Sub(first).Hours() / 24)
+	case "WEEKLY":
+		weekStart := first.AddDate(0, 0, -int((first.Weekday()-r.weekStart+7)%7))
+		periods = int(day.Sub(weekStart).Hours()/24) / 7
+	case "MONTHLY":
+		periods = (day.Year()-first.Year())*12 + int(day.Month()-first.Month())
+	default:
+		periods = day.Year() - first.Year()
+	}
+	return periods / r.interval
+}
+
+// period returns the first day of the period-th period of the rule from first,
+// the date the event starts, and the dates of the period matching the rule.
+func (r *rule) period(first time.Time, period int) (time.Time, []time.Time) {
+	var dates []time.Time
+	switch r.freq {
+	case "DAILY":
+		day := first.AddDate(0, 0, period*r.interval)
+		if r.matchesMonth(day) && r.matchesMonthDay(day) && r.matchesWeekday(day, time.Time{}, time.Time{}) {
+			dates = append(dates, day)
+		}
+		return day, dates
+	case "WEEKLY":
+		weekStart := first.AddDate(0, 0, -int((first.Weekday()-r.weekStart+7)%7)+7*period*r.interval)
+		for i := range 7 {
+			day := weekStart.AddDate(0, 0, i)
+			matches := day.Weekday() == first.Weekday()
+			if len(r.byDay) > 0 {
+				matches = r.matchesWeekday(day, time.Time{}, time.Time{})
+			}
+			if matches && r.matchesMonth(day) {
+				dates = append(dates, day)
+			}
+		}
+		return weekStart, dates
+	case "MONTHLY":
+		month := time.Date(first.Year(), first.Month()+time.Month(period*r.interval), 1, 0, 0, 0, 0, time.UTC)
+		if r.matchesMonth(month) {
+			dates = r.monthDates(month, first)
+		}
+		return month, dates
+	default:
+		year := time.Date(first.Year()+period*r.interval, time.January, 1, 0, 0, 0, 0, time.UTC)
+		switch {
+		case len(r.byMonth) > 0:
+			for _, month := range r.byMonth {
+				dates = append(dates, r.monthDates(time.Date(year.Year(), month, 1, 0, 0, 0, 0, time.UTC), first)...)
+			}
+		case len(r.byMonthDay) > 0:
+			for month := time.January; month <= time.December; month++ {
+				dates = append(dates, r.monthDates(time.Date(year.Year(), month, 1, 0, 0, 0, 0, time.UTC), first)...)
+			}
+		case len(r.byDay) > 0:
+			last := year.AddDate(1, 0, -1)
+			for day := year; !day.After(last); day = day.AddDate(0, 0, 1) {
+				if r.matchesWeekday(day, year, last) {
+					dates = append(dates, day)
+				}
+			}
+		default:
+			// the anniversary of the start, skipped in years without it
+			if day := time.Date(year.Year(), first.Month(), first.Day(), 0, 0, 0, 0, time.UTC); day.Month() == first.Month() {
+				dates = append(dates, day)
+			}
+		}
+		return year, dates
+	}
+}
+
+// monthDates returns the dates of the month starting at month matching BYMONTHDAY
+// and BYDAY, or the day of the month the event starts when the rule has neither.
+func (r *rule) monthDates(month, first time.Time) []time.Time {
+	last := month.AddDate(0, 1, -1)
+	if len(r.byMonthDay) == 0 && len(r.byDay) == 0 {
+		if first.Day() > last.Day() {
+			return nil
+		}
+		return []time.Time{month.AddDate(0, 0, first.Day()-1)}
+	}
+	var dates []time.Time
+	for day := month; !day.After(last); day = day.AddDate(0, 0, 1) {
+		if r.matchesMonthDay(day) && r.matchesWeekday(day, month, last) {
+			dates = append(dates, day)
+		}
+	}
+	return dates
+}
+
+func (r *rule) matchesMonth(day time.Time) bool {
+	return len(r.byMonth) == 0 || slices.Contains(r.byMonth, day.Month())
+}
+
+func (r *rule) matchesMonthDay(day time.Time) bool {
+	if len(r.byMonthDay) == 0 {
+		return true
+	}
+	daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, monthDay := range r.byMonthDay {
+		if monthDay == day.Day() || (monthDay < 0 && daysInMonth+monthDay+1 == day.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesWeekday reports whether day matches BYDAY, ordinals counting within
+// [first, last]. Ordinals are ignored when first is zero.
+func (r *rule) matchesWeekday(day, first, last time.Time) bool {
+	if len(r.byDay) == 0 {
+		return true
+	}
+	for _, weekday := range r.byDay {
+		if weekday.day != day.Weekday() {
+			continue
+		}
+		switch {
+		case weekday.n == 0 || first.IsZero():
+			return true
+		case weekday.n > 0 && int(day.Sub(first).Hours()/24)/7+1 == weekday.n:
+			return true
+		case weekday.n < 0 && int(last.Sub(day).Hours()/24)/7+1 == -weekday.n:
+			return true
+		}
+	}
+	return false
+}
+
+// civil returns the date of t as midnight UTC, so that date arithmetic is not
+// affected by daylight saving time.
+func civil(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,107 @@
+package calendar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	// TZID parameters name IANA zones, which slim images do not ship
+	_ "time/tzdata"
+)
+
+// zones resolves the TZID parameters of a feed.
+type zones struct {
+	// defaultLocation is the location of floating times and dates
+	defaultLocation *time.Location
+	// definitions are the VTIMEZONE components of the feed by TZID
+	definitions map[string]*component
+	resolved    map[string]*time.Location
+}
+
+// parseTime parses a DATE or DATE-TIME property value, reporting whether it is a date.
+func (z *zones) parseTime(p property) (time.Time, bool, error) {
+	value := p.value
+	if strings.EqualFold(p.params["VALUE"], "DATE") || (len(value) == len("20060102") && !strings.Contains(value, "T")) {
+		t, err := time.ParseInLocation("20060102", value, z.defaultLocation)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+	location := z.defaultLocation
+	if tzid := p.params["TZID"]; tzid != "" {
+		var err error
+		if location, err = z.location(tzid); err != nil {
+			return time.Time{}, false, err
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, location)
+	return t, false, err
+}
+
+// location resolves tzid: as an IANA name, as an IANA name prefixed by a vendor
+// path such as /mozilla.org/20050126_1/Europe/Berlin, or else from a VTIMEZONE
+// of the feed whose observances all have the same offset.
+func (z *zones) location(tzid string) (*time.Location, error) {
+	if location, ok := z.resolved[tzid]; ok {
+		return location, nil
+	}
+	location, err := z.resolve(tzid)
+	if err != nil {
+		return nil, err
+	}
+	z.resolved[tzid] = location
+	return location, nil
+}
+
+func (z *zones) resolve(tzid string) (*time.Location, error) {
+	name := strings.TrimPrefix(tzid, "/")
+	for {
+		if location, err := time.LoadLocation(name); err == nil && name != "" && name != "Local" {
+			return location, nil
+		}
+		_, rest, ok := strings.Cut(name, "/")
+		if !ok {
+			break
+		}
+		name = rest
+	}
+
+	definition, ok := z.definitions[tzid]
+	if !ok {
+		return nil, fmt.Errorf("unknown time zone %q", tzid)
+	}
+	var offset string
+	for _, observance := range definition.children {
+		to := observance.value("TZOFFSETTO")
+		if offset != "" && to != offset {
+			return nil, fmt.Errorf("time zone %q is not an IANA time zone and has daylight saving time", tzid)
+		}
+		offset = to
+	}
+	seconds, err := parseOffset(offset)
+	if err != nil {
+		return nil, fmt.Errorf("time zone %q: %w", tzid, err)
+	}
+	return time.FixedZone(tzid, seconds), nil
+}
+
+// parseOffset parses a UTC-OFFSET value such as +0900 or -053000 into seconds.
+func parseOffset(value string) (int, error) {
+	if (len(value) != 5 && len(value) != 7) || (value[0] != '+' && value[0] != '-') {
+		return 0, fmt.Errorf("invalid offset %q", value)
+	}
+	digits := value[1:] + strings.Repeat("0", 7-len(value))
+	hours, errHours := strconv.Atoi(digits[0:2])
+	minutes, errMinutes := strconv.Atoi(digits[2:4])
+	seconds, errSeconds := strconv.Atoi(digits[4:6])
+	if errHours != nil || errMinutes != nil || errSeconds != nil {
+		return 0, fmt.Errorf("invalid offset %q", value)
+	}
+	total := hours*3600 + minutes*60 + seconds
+	if value[0] == '-' {
+		total = -total
+	}
+	return total, nil
+}
@@ -0,0 +1,61 @@
+// Package cli holds the helpers shared by the command line tools: access to the
+// cluster and output formatting.
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+// KubeFlags select the cluster, context and namespace, like kubectl does.
+type KubeFlags struct {
+	Kubeconfig string
+	Context    string
+	Namespace  string
+}
+
+// AddFlags registers the flags on fs.
+func (f *KubeFlags) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&f.Kubeconfig, "kubeconfig", "", "Path to the kubeconfig file. Defaults to $KUBECONFIG or ~/.kube/config.")
+	fs.StringVar(&f.Context, "context", "", "The kubeconfig context to use.")
+	fs.StringVarP(&f.Namespace, "namespace", "n", "", "The namespace to use. Defaults to the namespace of the context.")
+}
+
+// Client returns a client for the selected cluster together with the namespace to use.
+func (f *KubeFlags) Client() (client.Client, string, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = f.Kubeconfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: f.Context}
+	overrides.Context.Namespace = f.Namespace
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
+
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to resolve namespace: %w", err)
+	}
+	c, err := client.New(restConfig, client.Options{Scheme: Scheme()})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create client: %w", err)
+	}
+	return c, namespace, nil
+}
+
+// Scheme returns a scheme with the core and thermo-pilot types registered.
+func Scheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(thermopilotv1.AddToScheme(scheme))
+	return scheme
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Output formats understood by the tools.
+const (
+	OutputTable = "table"
+	OutputWide  = "wide"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+// IsStructured reports whether format prints objects rather than a table.
+func IsStructured(format string) bool {
+	return format == OutputJSON || format == OutputYAML
+}
+
+// PrintStructured writes v as indented JSON or as YAML.
+func PrintStructured(w io.Writer, format string, v any) error {
+	switch format {
+	case OutputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case OutputYAML:
+		data, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// NewTable returns a writer aligning tab-separated columns like kubectl.
+func NewTable(w io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(w, 0, 4, 3, ' ', 0)
+}
+
+// ValueOrNone returns s, or "<none>" when it is empty.
+func ValueOrNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+// Health values shown for a ThermoPilot whose Ready condition is not False.
+const (
+	HealthHealthy = "Healthy"
+	HealthUnknown = "Unknown"
+)
+
+// ThermoPilotSummary is the overview of a room shown in the status tables.
+type ThermoPilotSummary struct {
+	Namespace string
+	Name      string
+	// Current is the temperature decisions are based on, after filtering
+	Current string
+	// Raw is the last reading as reported by the sensor when readings are
+	// calibrated or filtered
+	Raw string
+	// Target and Mode are the ones in force, taking an active override into account
+	Target string
+	Mode   string
+	// Humidity is the last relative humidity read from the sensor, empty when unknown
+	Humidity string
+	// Decision is the action chosen by the last reconcile, or the one equipment
+	// protection withheld
+	Decision string
+	// LastAction is the last command sent and how long ago it was
+	LastAction string
+	Setpoint   string
+	// Health is Healthy, Unknown, or the reason the ThermoPilot is not ready
+	Health    string
+	Message   string
+	Suspended bool
+	// Override describes the active override and when it expires
+	Override string
+	// Sensor is the source of the reading in use, its battery level and, while no
+	// sensor can be read, how stale it is
+	Sensor string
+}
+
+// Summarize returns the overview of thermoPilot as of now.
+func Summarize(thermoPilot *thermopilotv1.ThermoPilot, now time.Time) ThermoPilotSummary {
+	status := &thermoPilot.Status
+	summary := ThermoPilotSummary{
+		Namespace: thermoPilot.Namespace,
+		Name:      thermoPilot.Name,
+		Current:   status.CurrentTemperature,
+		Target:    thermoPilot.Spec.TargetTemperature,
+		Mode:      thermoPilot.Spec.Mode,
+		Suspended: thermoPilot.Spec.Suspend,
+		Health:    HealthUnknown,
+	}
+	if status.Filter != nil {
+		summary.Raw = status.Filter.RawTemperature
+		if status.Filter.Rejected {
+			summary.Raw += " (rejected)"
+		}
+	}
+	if sensor := status.Sensor; sensor != nil && sensor.CalibrationOffset != "" {
+		summary.Raw = fmt.Sprintf("%s (%s)", sensor.ReportedTemperature, sensor.CalibrationOffset)
+		if status.Filter != nil && status.Filter.Rejected {
+			summary.Raw += " (rejected)"
+		}
+	}
+	if status.CurrentHumidity != nil {
+		summary.Humidity = strconv.Itoa(int(*status.CurrentHumidity)) + "%"
+	}
+	if decision := status.LastDecision; decision != nil {
+		summary.Decision = decision.Action
+		if decision.BlockedAction != "" {
+			summary.Decision = fmt.Sprintf("%s blocked", decision.BlockedAction)
+		}
+		summary.Setpoint = decision.Setpoint
+		if decision.TargetTemperature != "" {
+			summary.Target = decision.TargetTemperature
+		}
+		if decision.Mode != "" {
+			summary.Mode = decision.Mode
+		}
+	}
+	if command := status.LastCommand; command != nil {
+		summary.LastAction = fmt.Sprintf("%s (%s ago)", command.Action, Age(command.Time, now))
+		if command.DryRun {
+			summary.LastAction += " dry-run"
+		}
+	}
+	if sensor := status.Sensor; sensor != nil {
+		summary.Sensor = sensor.Source
+		if sensor.Staleness != "" {
+			summary.Sensor += fmt.Sprintf(" (stale %s)", sensor.Staleness)
+		}
+		if sensor.Battery != nil {
+			summary.Sensor += fmt.Sprintf(" battery %d%%", *sensor.Battery)
+		}
+	}
+	if override := status.ActiveOverride; override != nil {
+		summary.Override = fmt.Sprintf("until %s", override.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	if ready := meta.FindStatusCondition(status.Conditions, "Ready"); ready != nil {
+		switch ready.Status {
+		case metav1.ConditionTrue:
+			summary.Health = HealthHealthy
+		case metav1.ConditionFalse:
+			summary.Health = ready.Reason
+			summary.Message = ready.Message
+		}
+	}
+	return summary
+}
+
+// Age formats the time elapsed since t like kubectl does.
+func Age(t metav1.Time, now time.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(now.Sub(t.Time))
+}
+
+// PrintThermoPilots writes items as a table, a wide table, or as a ThermoPilotList
+// in JSON or YAML. The namespace column is shown when withNamespace is set.
+func PrintThermoPilots(w io.Writer, format string, items []thermopilotv1.ThermoPilot, withNamespace bool, now time.Time) error {
+	if IsStructured(format) {
+		list := thermopilotv1.ThermoPilotList{
+			TypeMeta: metav1.TypeMeta{APIVersion: thermopilotv1.GroupVersion.String(), Kind: "ThermoPilotList"},
+			Items:    make([]thermopilotv1.ThermoPilot, len(items)),
+		}
+		for i := range items {
+			list.Items[i] = *items[i].DeepCopy()
+			list.Items[i].APIVersion, list.Items[i].Kind = thermopilotv1.GroupVersion.String(), "ThermoPilot"
+			list.Items[i].ManagedFields = nil
+		}
+		return PrintStructured(w, format, list)
+	}
+
+	wide := format == OutputWide
+	table := NewTable(w)
+	header := "NAME\tCURRENT\tTARGET\tHUMIDITY\tMODE\tLAST ACTION\tHEALTH"
+	if withNamespace {
+		header = "NAMESPACE\t" + header
+	}
+	if wide {
+		header += "\tRAW\tDECISION\tSETPOINT\tSENSOR\tSUSPENDED\tOVERRIDE\tMESSAGE"
+	}
+	_, _ = fmt.Fprintln(table, header)
+	for i := range items {
+		s := Summarize(&items[i], now)
+		row := fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\t%s",
+			s.Name, ValueOrNone(s.Current), s.Target, ValueOrNone(s.Humidity), s.Mode, ValueOrNone(s.LastAction), s.Health)
+		if withNamespace {
+			row = s.Namespace + "\t" + row
+		}
+		if wide {
+			row += fmt.Sprintf("\t%s\t%s\t%s\t%s\t%t\t%s\t%s",
+				ValueOrNone(s.Raw), ValueOrNone(s.Decision), ValueOrNone(s.Setpoint), ValueOrNone(s.Sensor), s.Suspended, ValueOrNone(s.Override), s.Message)
+		}
+		_, _ = fmt.Fprintln(table, row)
+	}
+	return table.Flush()
+}
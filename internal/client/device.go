@@ -50,6 +50,32 @@ func (c *Client) listDevice(ctx context.Context) (*ListDeviceResponse, error) {
 	return &data, nil
 }
 
+// ListDevices returns the physical devices and infrared remotes registered in the account.
+func (c Client) ListDevices(ctx context.Context) (*ListDeviceResponse, error) {
+	return c.listDevice(ctx)
+}
+
+// GetDeviceStatus returns the raw status body reported by a physical device.
+func (c Client) GetDeviceStatus(ctx context.Context, deviceID string) (json.RawMessage, error) {
+	path := fmt.Sprintf("/devices/%s/status", deviceID)
+	res, err := c.get(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed get device status %w", err)
+	}
+	var data struct {
+		StatusCode int             `json:"statusCode"`
+		Body       json.RawMessage `json:"body"`
+		Message    string          `json:"message"`
+	}
+	if err := json.Unmarshal(res, &data); err != nil {
+		return nil, err
+	}
+	if data.StatusCode != 100 {
+		return nil, fmt.Errorf("unexpected status code: %d, message: %s", data.StatusCode, data.Message)
+	}
+	return data.Body, nil
+}
+
 func (c Client) MultiGetAirConditioners(ctx context.Context) ([]*infraredRemote, error) {
 	var res []*infraredRemote
 	devices, err := c.listDevice(ctx)
@@ -111,11 +111,7 @@ func TestClient_GetAirConditioner(t *testing.T) {
 			}))
 			defer server.Close()
 
-			client := NewClient("test-token", "test-secret")
-			client.HttpClient = server.Client()
-			oldAPI := switchBotAPI
-			switchBotAPI = server.URL + "/v1.1"
-			defer func() { switchBotAPI = oldAPI }()
+			client := NewClient("test-token", "test-secret", WithBaseURL(server.URL+"/v1.1"), WithTransport(server.Client().Transport))
 
 			ctx := context.Background()
 			got, err := client.MultiGetAirConditioners(ctx)
@@ -235,12 +231,7 @@ func TestClient_GetMeterPro(t *testing.T) {
 			}))
 			defer server.Close()
 
-			client := NewClient("test-token", "test-secret")
-			client.HttpClient = server.Client()
-
-			oldAPI := switchBotAPI
-			switchBotAPI = server.URL + "/v1.1"
-			defer func() { switchBotAPI = oldAPI }()
+			client := NewClient("test-token", "test-secret", WithBaseURL(server.URL+"/v1.1"), WithTransport(server.Client().Transport))
 
 			ctx := context.Background()
 			got, err := client.GetMeterPro(ctx)
@@ -322,12 +313,7 @@ func TestClient_listDevice(t *testing.T) {
 			}))
 			defer server.Close()
 
-			client := NewClient("test-token", "test-secret")
-			client.HttpClient = server.Client()
-
-			oldAPI := switchBotAPI
-			switchBotAPI = server.URL + "/v1.1"
-			defer func() { switchBotAPI = oldAPI }()
+			client := NewClient("test-token", "test-secret", WithBaseURL(server.URL+"/v1.1"), WithTransport(server.Client().Transport))
 
 			ctx := context.Background()
 			got, err := client.listDevice(ctx)
@@ -348,3 +334,56 @@ func TestClient_listDevice(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_GetDeviceStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		response   string
+		statusCode int
+		want       string
+		wantErr    bool
+		wantErrMsg string
+	}{
+		{
+			name:       "success",
+			response:   `{"statusCode":100,"body":{"deviceId":"device1","temperature":22.5},"message":"success"}`,
+			statusCode: 200,
+			want:       `{"deviceId":"device1","temperature":22.5}`,
+		},
+		{
+			name:       "error - device offline",
+			response:   `{"statusCode":161,"body":{},"message":"device offline"}`,
+			statusCode: 200,
+			wantErr:    true,
+			wantErrMsg: "unexpected status code: 161",
+		},
+		{
+			name:       "error - API error",
+			statusCode: 500,
+			wantErr:    true,
+			wantErrMsg: "unexpected status code: 500",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v1.1/devices/device1/status", r.URL.Path)
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			client := NewClient("test-token", "test-secret", WithBaseURL(server.URL+"/v1.1"), WithTransport(server.Client().Transport))
+
+			got, err := client.GetDeviceStatus(context.Background(), "device1")
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErrMsg)
+			} else {
+				require.NoError(t, err)
+				assert.JSONEq(t, tt.want, string(got))
+			}
+		})
+	}
+}
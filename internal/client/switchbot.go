@@ -5,36 +5,145 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-var (
-	switchBotAPI = "https://api.switch-bot.com/v1.1"
+// APIError is returned when the SwitchBot API responds with a non-2xx status code.
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d, body: %s", e.StatusCode, e.Body)
+}
+
+// IsUnauthorized reports whether err was caused by the API rejecting the credentials.
+func IsUnauthorized(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden
+	}
+	return false
+}
+
+const (
+	// DefaultBaseURL is the SwitchBot API v1.1 endpoint.
+	DefaultBaseURL = "https://api.switch-bot.com/v1.1"
+	// DefaultTimeout bounds every request so that a hung connection cannot block a caller forever.
+	DefaultTimeout = 30 * time.Second
+	// DefaultUserAgent is sent with every request unless overridden.
+	DefaultUserAgent = "thermo-pilot-controller"
 )
 
 type Client struct {
 	HttpClient *http.Client
 
-	token  string
-	secret string
+	token     string
+	secret    string
+	baseURL   string
+	userAgent string
+	now       func() time.Time
+	nonce     func() string
+}
+
+// Option configures a Client.
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	baseURL   string
+	timeout   time.Duration
+	transport http.RoundTripper
+	proxy     *url.URL
+	userAgent string
+	now       func() time.Time
+	nonce     func() string
+}
+
+// WithBaseURL sets the API endpoint, for example a local mock server.
+func WithBaseURL(baseURL string) Option {
+	return func(o *clientOptions) { o.baseURL = strings.TrimSuffix(baseURL, "/") }
+}
+
+// WithTimeout sets the timeout of a single request. Zero disables the timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *clientOptions) { o.timeout = timeout }
+}
+
+// WithTransport sets the transport used to send requests.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *clientOptions) { o.transport = transport }
 }
 
-func NewClient(token, secret string) *Client {
+// WithProxy routes requests through the given HTTP proxy. It only applies when the
+// transport is an *http.Transport.
+func WithProxy(proxy *url.URL) Option {
+	return func(o *clientOptions) { o.proxy = proxy }
+}
+
+// WithUserAgent sets the User-Agent header.
+func WithUserAgent(userAgent string) Option {
+	return func(o *clientOptions) { o.userAgent = userAgent }
+}
+
+// WithClock sets the clock used to timestamp signed requests.
+func WithClock(now func() time.Time) Option {
+	return func(o *clientOptions) { o.now = now }
+}
+
+// WithNonce sets the generator of the nonce included in signed requests.
+func WithNonce(nonce func() string) Option {
+	return func(o *clientOptions) { o.nonce = nonce }
+}
+
+func NewClient(token, secret string, opts ...Option) *Client {
+	o := clientOptions{
+		baseURL:   DefaultBaseURL,
+		timeout:   DefaultTimeout,
+		userAgent: DefaultUserAgent,
+		now:       time.Now,
+		nonce:     func() string { return uuid.New().String() },
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	transport := o.transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if o.proxy != nil {
+		if t, ok := transport.(*http.Transport); ok {
+			t = t.Clone()
+			t.Proxy = http.ProxyURL(o.proxy)
+			transport = t
+		}
+	}
+
 	return &Client{
-		HttpClient: &http.Client{},
-		token:      token,
-		secret:     secret,
+		HttpClient: &http.Client{
+			Transport: transport,
+			Timeout:   o.timeout,
+		},
+		token:     token,
+		secret:    secret,
+		baseURL:   o.baseURL,
+		userAgent: o.userAgent,
+		now:       o.now,
+		nonce:     o.nonce,
 	}
 }
 
 func (c *Client) get(ctx context.Context, path string) ([]byte, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, switchBotAPI+path, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
 	if err != nil {
 		return nil, fmt.Errorf("creating request: %w", err)
 	}
@@ -42,7 +151,7 @@ func (c *Client) get(ctx context.Context, path string) ([]byte, error) {
 }
 
 func (c *Client) post(ctx context.Context, path string, body io.Reader) ([]byte, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, switchBotAPI+path, body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, body)
 	if err != nil {
 		return nil, fmt.Errorf("creating request: %w", err)
 	}
@@ -50,8 +159,8 @@ func (c *Client) post(ctx context.Context, path string, body io.Reader) ([]byte,
 }
 
 func (c *Client) Do(req *http.Request) ([]byte, error) {
-	nonce := uuid.New().String()
-	timestamp := time.Now().UnixMilli()
+	nonce := c.nonce()
+	timestamp := c.now().UnixMilli()
 	data := fmt.Sprintf("%s%d%s", c.token, timestamp, nonce)
 	mac := hmac.New(sha256.New, []byte(c.secret))
 	mac.Write([]byte(data))
@@ -63,6 +172,9 @@ func (c *Client) Do(req *http.Request) ([]byte, error) {
 	req.Header.Set("nonce", nonce)
 	req.Header.Set("t", fmt.Sprintf("%d", timestamp))
 	req.Header.Set("Content-Type", "application/json")
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
 
 	resp, err := c.HttpClient.Do(req)
 	if err != nil {
@@ -80,7 +192,7 @@ func (c *Client) Do(req *http.Request) ([]byte, error) {
 		return nil, fmt.Errorf("reading response body: %w", err)
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 	return body, nil
 }
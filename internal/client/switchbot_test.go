@@ -1 +1,103 @@
 package client
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsUnauthorized(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		want       bool
+	}{
+		{name: "unauthorized", statusCode: http.StatusUnauthorized, want: true},
+		{name: "forbidden", statusCode: http.StatusForbidden, want: true},
+		{name: "server error", statusCode: http.StatusInternalServerError, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer server.Close()
+
+			client := NewClient("test-token", "test-secret", WithBaseURL(server.URL+"/v1.1"), WithTransport(server.Client().Transport))
+
+			_, err := client.ListDevices(context.Background())
+			require.Error(t, err)
+			assert.Equal(t, tt.want, IsUnauthorized(err))
+			assert.Equal(t, tt.want, IsUnauthorized(fmt.Errorf("wrapped: %w", err)))
+		})
+	}
+}
+
+func TestNewClient_Options(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	now := time.UnixMilli(1700000000000)
+	client := NewClient("test-token", "test-secret",
+		WithBaseURL(server.URL+"/v1.1/"),
+		WithTransport(server.Client().Transport),
+		WithTimeout(5*time.Second),
+		WithUserAgent("test-agent"),
+		WithClock(func() time.Time { return now }),
+		WithNonce(func() string { return "test-nonce" }),
+	)
+	assert.Equal(t, 5*time.Second, client.HttpClient.Timeout)
+
+	_, err := client.get(context.Background(), "/devices")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+
+	mac := hmac.New(sha256.New, []byte("test-secret"))
+	mac.Write([]byte("test-token1700000000000test-nonce"))
+	wantSign := strings.ToUpper(base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+
+	assert.Equal(t, "/v1.1/devices", got.URL.Path)
+	assert.Equal(t, "test-token", got.Header.Get("Authorization"))
+	assert.Equal(t, "1700000000000", got.Header.Get("t"))
+	assert.Equal(t, "test-nonce", got.Header.Get("nonce"))
+	assert.Equal(t, wantSign, got.Header.Get("sign"))
+	assert.Equal(t, "test-agent", got.Header.Get("User-Agent"))
+}
+
+func TestNewClient_Defaults(t *testing.T) {
+	client := NewClient("test-token", "test-secret")
+	assert.Equal(t, DefaultBaseURL, client.baseURL)
+	assert.Equal(t, DefaultTimeout, client.HttpClient.Timeout)
+	assert.Equal(t, DefaultUserAgent, client.userAgent)
+}
+
+func TestNewClient_Proxy(t *testing.T) {
+	proxy, err := url.Parse("http://proxy.example.com:3128")
+	require.NoError(t, err)
+
+	client := NewClient("test-token", "test-secret", WithProxy(proxy))
+	transport, ok := client.HttpClient.Transport.(*http.Transport)
+	require.True(t, ok)
+	req, err := http.NewRequest(http.MethodGet, DefaultBaseURL+"/devices", nil)
+	require.NoError(t, err)
+	got, err := transport.Proxy(req)
+	require.NoError(t, err)
+	assert.Equal(t, proxy.String(), got.String())
+	assert.NotSame(t, http.DefaultTransport, client.HttpClient.Transport)
+}
@@ -5,6 +5,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
+	"strconv"
 )
 
 type AirConditionerMode int
@@ -17,37 +19,91 @@ const (
 	ModeHeat
 )
 
+// FanSpeed is the fan speed of an infrared air conditioner.
+type FanSpeed int
+
+const (
+	FanAuto FanSpeed = iota + 1
+	FanLow
+	FanMedium
+	FanHigh
+)
+
+// MeterStatus is a reading reported by a thermo-hygrometer such as the MeterPro.
+type MeterStatus struct {
+	// Temperature in °C
+	Temperature float64 `json:"temperature"`
+	// Humidity is the relative humidity in percent
+	Humidity int `json:"humidity"`
+	// Battery is the battery level in percent, nil for mains-powered devices
+	Battery *int `json:"battery,omitempty"`
+}
+
+// GetNowTemperature returns the temperature currently measured by a sensor.
 func (c Client) GetNowTemperature(ctx context.Context, deviceID string) (float64, error) {
+	status, err := c.GetMeterStatus(ctx, deviceID)
+	if err != nil {
+		return 0, err
+	}
+	return status.Temperature, nil
+}
+
+// GetMeterStatus returns the temperature, humidity and battery level currently reported by a sensor.
+func (c Client) GetMeterStatus(ctx context.Context, deviceID string) (*MeterStatus, error) {
 	path := fmt.Sprintf("/devices/%s/status", deviceID)
 	res, err := c.get(ctx, path)
 	if err != nil {
-		return 0, fmt.Errorf("failed get device status %w", err)
+		return nil, fmt.Errorf("failed get device status %w", err)
 	}
 	var data struct {
-		StatusCode int `json:"statusCode"`
-		Body       struct {
-			Temperature float64 `json:"temperature"`
-		} `json:"body"`
-		Message string `json:"message"`
+		StatusCode int         `json:"statusCode"`
+		Body       MeterStatus `json:"body"`
+		Message    string      `json:"message"`
 	}
 	if err := json.Unmarshal(res, &data); err != nil {
-		return 0, err
+		return nil, err
 	}
 	if data.StatusCode != 100 {
-		return 0, fmt.Errorf("unexpected status code: %d, message: %s", data.StatusCode, data.Message)
+		return nil, fmt.Errorf("unexpected status code: %d, message: %s", data.StatusCode, data.Message)
 	}
-	return data.Body.Temperature, nil
+	return &data.Body, nil
 }
 
+// SetTemperature turns an infrared air conditioner on at temperature in mode, with
+// the fan on auto.
 func (c Client) SetTemperature(ctx context.Context, deviceID string, temperature float64, mode AirConditionerMode) error {
+	return c.SetAll(ctx, deviceID, temperature, mode, FanAuto)
+}
+
+// SetAll turns an infrared air conditioner on at temperature in mode and fan speed.
+// The temperature is sent with up to one decimal, as given; fitting it to what the
+// air conditioner accepts is up to the caller.
+func (c Client) SetAll(ctx context.Context, deviceID string, temperature float64, mode AirConditionerMode, fan FanSpeed) error {
+	setpoint := strconv.FormatFloat(math.Round(temperature*10)/10, 'f', -1, 64)
+	parameter := fmt.Sprintf("%s,%d,%d,on", setpoint, mode, fan)
+	if err := c.sendCommand(ctx, deviceID, "setAll", parameter); err != nil {
+		return fmt.Errorf("failed post set temperature command: %w", err)
+	}
+	return nil
+}
+
+// TurnOff switches an infrared air conditioner off.
+func (c Client) TurnOff(ctx context.Context, deviceID string) error {
+	if err := c.sendCommand(ctx, deviceID, "turnOff", "default"); err != nil {
+		return fmt.Errorf("failed post turn off command: %w", err)
+	}
+	return nil
+}
+
+func (c Client) sendCommand(ctx context.Context, deviceID, command, parameter string) error {
 	path := fmt.Sprintf("/devices/%s/commands", deviceID)
 	payload := struct {
 		Command     string `json:"command"`
 		Parameter   string `json:"parameter"`
 		CommandType string `json:"commandType"`
 	}{
-		Command:     "setAll",
-		Parameter:   fmt.Sprintf("%.0f,%d,1,on", temperature, mode),
+		Command:     command,
+		Parameter:   parameter,
 		CommandType: "command",
 	}
 	body, err := json.Marshal(payload)
@@ -56,7 +112,7 @@ func (c Client) SetTemperature(ctx context.Context, deviceID string, temperature
 	}
 	res, err := c.post(ctx, path, bytes.NewReader(body))
 	if err != nil {
-		return fmt.Errorf("failed post set temperature command: %w", err)
+		return err
 	}
 	var data struct {
 		StatusCode int    `json:"statusCode"`
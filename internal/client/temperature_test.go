@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_GetMeterStatus(t *testing.T) {
+	battery := 90
+	tests := []struct {
+		name       string
+		response   string
+		statusCode int
+		want       *MeterStatus
+		wantErrMsg string
+	}{
+		{
+			name:       "success",
+			response:   `{"statusCode":100,"body":{"deviceId":"meter1","temperature":24.3,"humidity":58,"battery":90},"message":"success"}`,
+			statusCode: 200,
+			want:       &MeterStatus{Temperature: 24.3, Humidity: 58, Battery: &battery},
+		},
+		{
+			name:       "success - no battery",
+			response:   `{"statusCode":100,"body":{"deviceId":"meter1","temperature":24.3,"humidity":58},"message":"success"}`,
+			statusCode: 200,
+			want:       &MeterStatus{Temperature: 24.3, Humidity: 58},
+		},
+		{
+			name:       "error - hub offline",
+			response:   `{"statusCode":171,"body":{},"message":"hub device is offline"}`,
+			statusCode: 200,
+			wantErrMsg: "unexpected status code: 171",
+		},
+		{
+			name:       "error - API error",
+			statusCode: 500,
+			wantErrMsg: "unexpected status code: 500",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v1.1/devices/meter1/status", r.URL.Path)
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			client := NewClient("test-token", "test-secret", WithBaseURL(server.URL+"/v1.1"), WithTransport(server.Client().Transport))
+
+			got, err := client.GetMeterStatus(context.Background(), "meter1")
+			if tt.wantErrMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			temperature, err := client.GetNowTemperature(context.Background(), "meter1")
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want.Temperature, temperature, 1e-9)
+		})
+	}
+}
+
+func TestClient_SetAll(t *testing.T) {
+	tests := []struct {
+		name          string
+		temperature   float64
+		mode          AirConditionerMode
+		fan           FanSpeed
+		wantParameter string
+	}{
+		{name: "whole degrees", temperature: 24, mode: ModeCool, fan: FanAuto, wantParameter: "24,2,1,on"},
+		{name: "half degrees are kept", temperature: 24.5, mode: ModeHeat, fan: FanLow, wantParameter: "24.5,5,2,on"},
+		{name: "rounded to one decimal", temperature: 24.5000001, mode: ModeCool, fan: FanHigh, wantParameter: "24.5,2,4,on"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v1.1/devices/ac1/commands", r.URL.Path)
+				var payload struct {
+					Command   string `json:"command"`
+					Parameter string `json:"parameter"`
+				}
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+				assert.Equal(t, "setAll", payload.Command)
+				assert.Equal(t, tt.wantParameter, payload.Parameter)
+				_, _ = w.Write([]byte(`{"statusCode":100,"body":{},"message":"success"}`))
+			}))
+			defer server.Close()
+
+			client := NewClient("test-token", "test-secret", WithBaseURL(server.URL+"/v1.1"), WithTransport(server.Client().Transport))
+			require.NoError(t, client.SetAll(context.Background(), "ac1", tt.temperature, tt.mode, tt.fan))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+	"github.com/seipan/thermo-pilot-controller/internal/planner"
+)
+
+// +kubebuilder:rbac:groups=thermo-pilot.yadon3141.com,resources=clusterawayswitches,verbs=get;list;watch
+
+// awaySwitches returns the ClusterAwaySwitches selecting thermoPilot, sorted by
+// name so that the first active one wins consistently. A switch with an invalid
+// selector selects nothing.
+func (r *ThermoPilotReconciler) awaySwitches(ctx context.Context, thermoPilot *thermopilotv1.ThermoPilot) ([]thermopilotv1.ClusterAwaySwitch, error) {
+	var list thermopilotv1.ClusterAwaySwitchList
+	if err := r.List(ctx, &list); err != nil {
+		return nil, fmt.Errorf("failed to list ClusterAwaySwitches: %w", err)
+	}
+	var selected []thermopilotv1.ClusterAwaySwitch
+	for _, awaySwitch := range list.Items {
+		selector, err := metav1.LabelSelectorAsSelector(&awaySwitch.Spec.Selector)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "invalid ClusterAwaySwitch selector", "clusterAwaySwitch", awaySwitch.Name)
+			continue
+		}
+		if selector.Matches(labels.Set(thermoPilot.Labels)) {
+			selected = append(selected, awaySwitch)
+		}
+	}
+	slices.SortFunc(selected, func(a, b thermopilotv1.ClusterAwaySwitch) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return selected, nil
+}
+
+// thermoPilotsForAwaySwitch enqueues the ThermoPilots a ClusterAwaySwitch selects.
+// ThermoPilots that only the previous selector of an updated switch selected
+// notice the change at their next periodic reconcile.
+func (r *ThermoPilotReconciler) thermoPilotsForAwaySwitch(ctx context.Context, obj client.Object) []reconcile.Request {
+	awaySwitch, ok := obj.(*thermopilotv1.ClusterAwaySwitch)
+	if !ok {
+		return nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&awaySwitch.Spec.Selector)
+	if err != nil {
+		return nil
+	}
+	var list thermopilotv1.ThermoPilotList
+	if err := r.List(ctx, &list, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list ThermoPilots for ClusterAwaySwitch", "clusterAwaySwitch", awaySwitch.Name)
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, thermoPilot := range list.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: thermoPilot.Namespace, Name: thermoPilot.Name},
+		})
+	}
+	return requests
+}
+
+// awayEvents records an Event when an away period starts and when it ends.
+func (r *ThermoPilotReconciler) awayEvents(thermoPilot *thermopilotv1.ThermoPilot, previous *thermopilotv1.AwayStatus) {
+	current := thermoPilot.Status.Away
+	if previous != nil && (current == nil || current.Source != previous.Source || !current.Start.Equal(previous.Start)) {
+		r.event(thermoPilot, corev1.EventTypeNormal, "AwayEnded",
+			fmt.Sprintf("Away period from %s ended, resuming normal control", previous.Source))
+	}
+	if current != nil && (previous == nil || current.Source != previous.Source || !current.Start.Equal(previous.Start)) {
+		until := "until the switch is removed"
+		if current.End != nil {
+			until = "until " + current.End.UTC().Format(time.RFC3339)
+		}
+		what := "turning the air conditioners off"
+		if !current.Off {
+			what = fmt.Sprintf("target %s%s, mode %s", current.TargetTemperature,
+				planner.UnitOf(thermoPilot.Spec).Symbol(), current.Mode)
+		}
+		r.event(thermoPilot, corev1.EventTypeNormal, "AwayStarted",
+			fmt.Sprintf("Away period from %s %s: %s", current.Source, until, what))
+	}
+}
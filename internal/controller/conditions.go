@@ -0,0 +1,213 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+	switchbotclient "github.com/seipan/thermo-pilot-controller/internal/client"
+	"github.com/seipan/thermo-pilot-controller/internal/planner"
+)
+
+// Condition types reported on a ThermoPilot.
+const (
+	// ConditionReady summarizes the other conditions; it is True when the last
+	// reconcile read the temperature and controlled the air conditioners without error.
+	ConditionReady = "Ready"
+	// ConditionSensorHealthy reports whether the temperature sensor could be read and
+	// its battery and readings look healthy.
+	ConditionSensorHealthy = "SensorHealthy"
+	// ConditionActuatorsHealthy reports whether the air conditioners accepted the commands.
+	ConditionActuatorsHealthy = "ActuatorsHealthy"
+	// ConditionCredentialsValid reports whether the SwitchBot credentials could be
+	// resolved and were accepted by the API.
+	ConditionCredentialsValid = "CredentialsValid"
+	// ConditionProgressing is True while the room is being driven towards the target.
+	ConditionProgressing = "Progressing"
+)
+
+// Condition reasons reported on a ThermoPilot.
+const (
+	ReasonReconciled                 = "Reconciled"
+	ReasonNotChecked                 = "NotChecked"
+	ReasonCredentialsError           = "CredentialsError"
+	ReasonUnauthorized               = "Unauthorized"
+	ReasonCredentialsResolved        = "CredentialsResolved"
+	ReasonConfigError                = "ConfigError"
+	ReasonTemperatureSensorNotFound  = "TemperatureSensorNotFound"
+	ReasonTemperatureSensorError     = "TemperatureSensorError"
+	ReasonTemperatureRead            = "TemperatureRead"
+	ReasonFallbackSensorActive       = "FallbackSensorActive"
+	ReasonSensorReadingHeld          = "SensorReadingHeld"
+	ReasonSensorStale                = "SensorStale"
+	ReasonSensorFrozen               = "SensorFrozen"
+	ReasonLowBattery                 = "LowBattery"
+	ReasonAirConditionerListError    = "AirConditionerListError"
+	ReasonAirConditionerControlError = "AirConditionerControlError"
+	ReasonCommandsSucceeded          = "CommandsSucceeded"
+	ReasonNoCommandsSent             = "NoCommandsSent"
+	ReasonSuspended                  = "Suspended"
+	ReasonDryRun                     = "DryRun"
+	ReasonTemperatureAdjusting       = "TemperatureAdjusting"
+	ReasonTemperatureStable          = "TemperatureStable"
+	ReasonFreezeProtection           = "FreezeProtection"
+	ReasonOverheatGuard              = "OverheatGuard"
+	ReasonEquipmentProtection        = "EquipmentProtection"
+	ReasonAway                       = "Away"
+)
+
+// legacyConditions were reported by earlier releases and are removed on the next reconcile.
+var legacyConditions = []string{"Available", "Degraded"}
+
+// reconcileReport collects what a reconcile observed so that every condition can be
+// computed in one place once the reconcile is over. Only the first failing step is
+// recorded; the steps after it were not attempted.
+type reconcileReport struct {
+	credentialsErr error
+	sensorErr      error
+	// sensorWarning is set when the primary sensor failed but the sensor failure
+	// policy let the reconcile go on with a fallback, held or no reading
+	sensorWarning error
+	sensorReason  string
+	// sensorIssue is set when the reading was used but the sensor reports the same
+	// values for too long or runs low on battery
+	sensorIssue       error
+	sensorIssueReason string
+	configErr         error
+	actuatorErr       error
+	actuatorReason    string
+	// plan is the decision made, nil when the reconcile failed before deciding
+	plan *planner.Plan
+}
+
+// setConditions computes the full condition set of thermoPilot from report and
+// records the generation it was computed for.
+func setConditions(thermoPilot *thermopilotv1.ThermoPilot, report reconcileReport) {
+	generation := thermoPilot.Generation
+	status := &thermoPilot.Status
+	if status.Conditions == nil {
+		status.Conditions = []metav1.Condition{}
+	}
+	set := func(conditionType string, conditionStatus metav1.ConditionStatus, reason, message string) {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               conditionType,
+			Status:             conditionStatus,
+			Reason:             reason,
+			Message:            message,
+			ObservedGeneration: generation,
+		})
+	}
+	notChecked := func(conditionType, step string) {
+		set(conditionType, metav1.ConditionUnknown, ReasonNotChecked, fmt.Sprintf("Not checked because %s failed", step))
+	}
+
+	// CredentialsValid
+	switch {
+	case report.credentialsErr != nil:
+		set(ConditionCredentialsValid, metav1.ConditionFalse, ReasonCredentialsError, report.credentialsErr.Error())
+	case switchbotclient.IsUnauthorized(report.sensorErr):
+		set(ConditionCredentialsValid, metav1.ConditionFalse, ReasonUnauthorized, report.sensorErr.Error())
+	case switchbotclient.IsUnauthorized(report.actuatorErr):
+		set(ConditionCredentialsValid, metav1.ConditionFalse, ReasonUnauthorized, report.actuatorErr.Error())
+	default:
+		set(ConditionCredentialsValid, metav1.ConditionTrue, ReasonCredentialsResolved, "SwitchBot credentials are available")
+	}
+
+	// SensorHealthy
+	switch {
+	case report.credentialsErr != nil:
+		notChecked(ConditionSensorHealthy, "resolving credentials")
+	case report.sensorErr != nil:
+		set(ConditionSensorHealthy, metav1.ConditionFalse, report.sensorReason, report.sensorErr.Error())
+	case report.sensorWarning != nil:
+		set(ConditionSensorHealthy, metav1.ConditionFalse, report.sensorReason, report.sensorWarning.Error())
+	case report.sensorIssue != nil:
+		set(ConditionSensorHealthy, metav1.ConditionFalse, report.sensorIssueReason, report.sensorIssue.Error())
+	default:
+		set(ConditionSensorHealthy, metav1.ConditionTrue, ReasonTemperatureRead,
+			fmt.Sprintf("Current temperature is %s", status.CurrentTemperature))
+	}
+
+	// ActuatorsHealthy
+	switch {
+	case report.actuatorErr != nil:
+		set(ConditionActuatorsHealthy, metav1.ConditionFalse, report.actuatorReason, report.actuatorErr.Error())
+	case report.plan == nil:
+		notChecked(ConditionActuatorsHealthy, "deciding the action")
+	case report.plan.Actuate():
+		set(ConditionActuatorsHealthy, metav1.ConditionTrue, ReasonCommandsSucceeded,
+			fmt.Sprintf("%d air conditioners accepted the command", len(report.plan.Commands)))
+	default:
+		set(ConditionActuatorsHealthy, metav1.ConditionTrue, ReasonNoCommandsSent, "No command had to be sent")
+	}
+
+	// Progressing
+	plan := report.plan
+	switch {
+	case plan == nil:
+		set(ConditionProgressing, metav1.ConditionFalse, ReasonNotChecked, "No decision was made")
+	case plan.Stale:
+		set(ConditionProgressing, metav1.ConditionFalse, ReasonSensorStale,
+			fmt.Sprintf("No temperature reading is available: %s", plan.Reasons[len(plan.Reasons)-1]))
+	case plan.Suspended:
+		set(ConditionProgressing, metav1.ConditionFalse, ReasonSuspended,
+			fmt.Sprintf("Control is suspended: current=%.1f, target=%.1f", plan.CurrentTemperature, plan.TargetTemperature))
+	case plan.NeedsAction() && plan.DryRun:
+		set(ConditionProgressing, metav1.ConditionFalse, ReasonDryRun,
+			fmt.Sprintf("Dry run, would adjust temperature: current=%.1f, target=%.1f", plan.CurrentTemperature, plan.TargetTemperature))
+	case plan.Blocked != "":
+		set(ConditionProgressing, metav1.ConditionFalse, ReasonEquipmentProtection,
+			fmt.Sprintf("Equipment protection withholds %s until %s: current=%.1f, target=%.1f",
+				plan.Blocked, plan.BlockedUntil.UTC().Format(time.RFC3339), plan.CurrentTemperature, plan.TargetTemperature))
+	case plan.SafetyLimit && report.actuatorErr == nil:
+		set(ConditionProgressing, metav1.ConditionTrue, safetyReason(plan.Action),
+			fmt.Sprintf("Safety limit enforced by %s: current=%.1f, setpoint=%.1f", plan.Action, plan.CurrentTemperature, plan.Setpoint))
+	case plan.AwayOff() && report.actuatorErr == nil:
+		set(ConditionProgressing, metav1.ConditionFalse, ReasonAway,
+			fmt.Sprintf("Away (%s), the air conditioners are off", plan.Target.Away.Source))
+	case plan.NeedsAction() && report.actuatorErr == nil:
+		set(ConditionProgressing, metav1.ConditionTrue, ReasonTemperatureAdjusting,
+			fmt.Sprintf("Adjusting temperature: current=%.1f, target=%.1f", plan.CurrentTemperature, plan.TargetTemperature))
+	case plan.NeedsAction():
+		set(ConditionProgressing, metav1.ConditionFalse, report.actuatorReason,
+			fmt.Sprintf("Could not adjust temperature: current=%.1f, target=%.1f", plan.CurrentTemperature, plan.TargetTemperature))
+	default:
+		set(ConditionProgressing, metav1.ConditionFalse, ReasonTemperatureStable,
+			fmt.Sprintf("Temperature is within threshold: current=%.1f, target=%.1f", plan.CurrentTemperature, plan.TargetTemperature))
+	}
+
+	// Ready
+	switch {
+	case report.credentialsErr != nil:
+		set(ConditionReady, metav1.ConditionFalse, ReasonCredentialsError, report.credentialsErr.Error())
+	case report.sensorErr != nil:
+		set(ConditionReady, metav1.ConditionFalse, report.sensorReason, report.sensorErr.Error())
+	case report.sensorWarning != nil && report.sensorReason != ReasonFallbackSensorActive:
+		set(ConditionReady, metav1.ConditionFalse, report.sensorReason, report.sensorWarning.Error())
+	case report.sensorIssue != nil && report.sensorIssueReason == ReasonSensorFrozen:
+		set(ConditionReady, metav1.ConditionFalse, report.sensorIssueReason, report.sensorIssue.Error())
+	case report.configErr != nil:
+		set(ConditionReady, metav1.ConditionFalse, ReasonConfigError, report.configErr.Error())
+	case report.actuatorErr != nil:
+		set(ConditionReady, metav1.ConditionFalse, report.actuatorReason, report.actuatorErr.Error())
+	default:
+		set(ConditionReady, metav1.ConditionTrue, ReasonReconciled,
+			fmt.Sprintf("Action %q: current=%.1f, target=%.1f", plan.Action, plan.CurrentTemperature, plan.TargetTemperature))
+	}
+
+	for _, conditionType := range legacyConditions {
+		meta.RemoveStatusCondition(&status.Conditions, conditionType)
+	}
+	status.ObservedGeneration = generation
+}
+
+// safetyReason returns the condition and event reason of a safety action.
+func safetyReason(action string) string {
+	if action == planner.ActionFreezeProtection {
+		return ReasonFreezeProtection
+	}
+	return ReasonOverheatGuard
+}
@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+	switchbotclient "github.com/seipan/thermo-pilot-controller/internal/client"
+	"github.com/seipan/thermo-pilot-controller/internal/planner"
+)
+
+var _ = Describe("ThermoPilot conditions", func() {
+	var thermoPilot *thermopilotv1.ThermoPilot
+
+	BeforeEach(func() {
+		thermoPilot = &thermopilotv1.ThermoPilot{
+			ObjectMeta: metav1.ObjectMeta{Generation: 3},
+			Status: thermopilotv1.ThermoPilotStatus{
+				Conditions: []metav1.Condition{
+					{Type: "Available", Status: metav1.ConditionTrue, Reason: "Reconciling"},
+					{Type: "Degraded", Status: metav1.ConditionFalse, Reason: "Healthy"},
+				},
+			},
+		}
+	})
+
+	condition := func(conditionType string) *metav1.Condition {
+		return meta.FindStatusCondition(thermoPilot.Status.Conditions, conditionType)
+	}
+
+	It("reports Ready when the reconcile succeeded", func() {
+		plan := planner.Plan{Action: planner.ActionCooling, Commands: []planner.Command{{DeviceID: "ac1"}}}
+		setConditions(thermoPilot, reconcileReport{plan: &plan})
+
+		Expect(thermoPilot.Status.ObservedGeneration).To(Equal(int64(3)))
+		Expect(condition(ConditionReady).Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition(ConditionReady).ObservedGeneration).To(Equal(int64(3)))
+		Expect(condition(ConditionActuatorsHealthy).Reason).To(Equal(ReasonCommandsSucceeded))
+		Expect(condition(ConditionProgressing).Reason).To(Equal(ReasonTemperatureAdjusting))
+		Expect(condition("Available")).To(BeNil())
+		Expect(condition("Degraded")).To(BeNil())
+	})
+
+	It("is not Ready after a partial air conditioner failure", func() {
+		plan := planner.Plan{Action: planner.ActionCooling, Commands: []planner.Command{{DeviceID: "ac1"}, {DeviceID: "ac2"}}}
+		setConditions(thermoPilot, reconcileReport{
+			plan:           &plan,
+			actuatorErr:    errors.New("failed to control 1/2 air conditioners"),
+			actuatorReason: ReasonAirConditionerControlError,
+		})
+
+		Expect(condition(ConditionReady).Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition(ConditionReady).Reason).To(Equal(ReasonAirConditionerControlError))
+		Expect(condition(ConditionActuatorsHealthy).Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition(ConditionSensorHealthy).Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("stays Ready on a fallback sensor but reports the primary as unhealthy", func() {
+		plan := planner.Plan{Action: planner.ActionNone}
+		setConditions(thermoPilot, reconcileReport{
+			plan:          &plan,
+			sensorWarning: errors.New("primary sensor meter1: hub offline"),
+			sensorReason:  ReasonFallbackSensorActive,
+		})
+
+		Expect(condition(ConditionSensorHealthy).Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition(ConditionSensorHealthy).Reason).To(Equal(ReasonFallbackSensorActive))
+		Expect(condition(ConditionReady).Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("is not Ready while the safe action is in force", func() {
+		plan := planner.Plan{
+			Action:   planner.ActionSafeOff,
+			Stale:    true,
+			Commands: []planner.Command{{DeviceID: "ac1"}},
+			Reasons:  []string{"no temperature reading is available"},
+		}
+		setConditions(thermoPilot, reconcileReport{
+			plan:          &plan,
+			sensorWarning: errors.New("primary sensor meter1: hub offline"),
+			sensorReason:  ReasonSensorStale,
+		})
+
+		Expect(condition(ConditionReady).Reason).To(Equal(ReasonSensorStale))
+		Expect(condition(ConditionProgressing).Reason).To(Equal(ReasonSensorStale))
+		Expect(condition(ConditionActuatorsHealthy).Reason).To(Equal(ReasonCommandsSucceeded))
+	})
+
+	It("stays Ready on a low battery but not on a frozen sensor", func() {
+		plan := planner.Plan{Action: planner.ActionNone}
+		setConditions(thermoPilot, reconcileReport{
+			plan:              &plan,
+			sensorIssue:       errors.New("sensor meter1 battery is at 10%"),
+			sensorIssueReason: ReasonLowBattery,
+		})
+		Expect(condition(ConditionSensorHealthy).Reason).To(Equal(ReasonLowBattery))
+		Expect(condition(ConditionReady).Status).To(Equal(metav1.ConditionTrue))
+
+		setConditions(thermoPilot, reconcileReport{
+			plan:              &plan,
+			sensorIssue:       errors.New("sensor meter1 has reported 26.0°C and 50% humidity since 2025-01-01T09:00:00Z"),
+			sensorIssueReason: ReasonSensorFrozen,
+		})
+		Expect(condition(ConditionSensorHealthy).Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition(ConditionReady).Reason).To(Equal(ReasonSensorFrozen))
+	})
+
+	It("reports an enforced safety limit as progressing with its own reason", func() {
+		plan := planner.Plan{
+			Action:             planner.ActionFreezeProtection,
+			CurrentTemperature: 4.5,
+			Setpoint:           8.0,
+			SafetyLimit:        true,
+			Commands:           []planner.Command{{DeviceID: "ac1"}},
+		}
+		setConditions(thermoPilot, reconcileReport{plan: &plan})
+
+		Expect(condition(ConditionProgressing).Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition(ConditionProgressing).Reason).To(Equal(ReasonFreezeProtection))
+		Expect(condition(ConditionReady).Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("reports an action withheld by equipment protection", func() {
+		plan := planner.Plan{
+			Action:       planner.ActionNone,
+			Blocked:      planner.ActionCooling,
+			BlockedUntil: time.Date(2025, 1, 1, 12, 5, 0, 0, time.UTC),
+		}
+		setConditions(thermoPilot, reconcileReport{plan: &plan})
+
+		Expect(condition(ConditionProgressing).Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition(ConditionProgressing).Reason).To(Equal(ReasonEquipmentProtection))
+		Expect(condition(ConditionProgressing).Message).To(ContainSubstring("2025-01-01T12:05:00Z"))
+	})
+
+	It("marks the steps after a credentials failure as not checked", func() {
+		setConditions(thermoPilot, reconcileReport{credentialsErr: errors.New("secret not found")})
+
+		Expect(condition(ConditionReady).Reason).To(Equal(ReasonCredentialsError))
+		Expect(condition(ConditionCredentialsValid).Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition(ConditionSensorHealthy).Status).To(Equal(metav1.ConditionUnknown))
+		Expect(condition(ConditionActuatorsHealthy).Status).To(Equal(metav1.ConditionUnknown))
+	})
+
+	It("reports rejected credentials from a sensor failure", func() {
+		setConditions(thermoPilot, reconcileReport{
+			sensorErr:    &switchbotclient.APIError{StatusCode: 401},
+			sensorReason: ReasonTemperatureSensorError,
+		})
+
+		Expect(condition(ConditionCredentialsValid).Reason).To(Equal(ReasonUnauthorized))
+		Expect(condition(ConditionSensorHealthy).Reason).To(Equal(ReasonTemperatureSensorError))
+		Expect(condition(ConditionReady).Status).To(Equal(metav1.ConditionFalse))
+	})
+})
@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+	switchbotclient "github.com/seipan/thermo-pilot-controller/internal/client"
+	"github.com/seipan/thermo-pilot-controller/internal/planner"
+)
+
+const thermoPilotFinalizer = "thermo-pilot.yadon3141.com/finalizer"
+
+const (
+	OnDeleteLeave    = "leave"
+	OnDeleteOff      = "off"
+	OnDeleteFallback = "fallback"
+)
+
+const (
+	defaultOnDeleteRetries = 3
+	defaultOnDeleteTimeout = 2 * time.Minute
+	onDeleteRetryBackoff   = time.Second
+)
+
+func onDeleteAction(thermoPilot *thermopilotv1.ThermoPilot) string {
+	if thermoPilot.Spec.OnDelete == nil || thermoPilot.Spec.OnDelete.Action == "" {
+		return OnDeleteLeave
+	}
+	return thermoPilot.Spec.OnDelete.Action
+}
+
+// ensureFinalizer adds the finalizer when the onDelete policy needs to act on
+// deletion, and removes it again when the policy is switched back to leave.
+func (r *ThermoPilotReconciler) ensureFinalizer(ctx context.Context, thermoPilot *thermopilotv1.ThermoPilot) error {
+	needsFinalizer := onDeleteAction(thermoPilot) != OnDeleteLeave
+	if needsFinalizer == controllerutil.ContainsFinalizer(thermoPilot, thermoPilotFinalizer) {
+		return nil
+	}
+	patch := client.MergeFromWithOptions(thermoPilot.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	if needsFinalizer {
+		controllerutil.AddFinalizer(thermoPilot, thermoPilotFinalizer)
+	} else {
+		controllerutil.RemoveFinalizer(thermoPilot, thermoPilotFinalizer)
+	}
+	return r.Patch(ctx, thermoPilot, patch)
+}
+
+// reconcileDelete puts the controlled air conditioners into the state requested by
+// the onDelete policy and then removes the finalizer. Failures are retried a bounded
+// number of times within the policy timeout; once that is exhausted the finalizer
+// is removed anyway so that an unreachable API cannot block deletion.
+func (r *ThermoPilotReconciler) reconcileDelete(ctx context.Context, thermoPilot *thermopilotv1.ThermoPilot) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	if !controllerutil.ContainsFinalizer(thermoPilot, thermoPilotFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.runOnDelete(ctx, thermoPilot); err != nil {
+		logger.Error(err, "onDelete action did not complete, removing finalizer anyway", "action", onDeleteAction(thermoPilot))
+		r.event(thermoPilot, corev1.EventTypeWarning, "OnDeleteFailed", err.Error())
+	} else {
+		r.event(thermoPilot, corev1.EventTypeNormal, "OnDeleteCompleted",
+			fmt.Sprintf("Air conditioners left in %q state", onDeleteAction(thermoPilot)))
+	}
+
+	patch := client.MergeFromWithOptions(thermoPilot.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	controllerutil.RemoveFinalizer(thermoPilot, thermoPilotFinalizer)
+	if err := r.Patch(ctx, thermoPilot, patch); err != nil {
+		logger.Error(err, "failed to remove finalizer")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *ThermoPilotReconciler) runOnDelete(ctx context.Context, thermoPilot *thermopilotv1.ThermoPilot) error {
+	logger := log.FromContext(ctx)
+	policy := thermoPilot.Spec.OnDelete
+	action := onDeleteAction(thermoPilot)
+	if action == OnDeleteLeave {
+		return nil
+	}
+
+	timeout := defaultOnDeleteTimeout
+	if policy.Timeout != nil && policy.Timeout.Duration > 0 {
+		timeout = policy.Timeout.Duration
+	}
+	deadline := thermoPilot.DeletionTimestamp.Add(timeout)
+	if time.Now().After(deadline) {
+		return fmt.Errorf("onDelete timeout of %s exceeded", timeout)
+	}
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	var command func(*switchbotclient.Client, string) error
+	switch action {
+	case OnDeleteOff:
+		command = func(c *switchbotclient.Client, deviceID string) error {
+			return c.TurnOff(ctx, deviceID)
+		}
+	case OnDeleteFallback:
+		temp, err := planner.ParseTemperature(policy.FallbackTemperature)
+		if err != nil {
+			return err
+		}
+		if _, err := parseMode(policy.FallbackMode); err != nil {
+			return err
+		}
+		unit := planner.UnitOf(thermoPilot.Spec)
+		command = func(c *switchbotclient.Client, deviceID string) error {
+			var offsets []thermopilotv1.CalibrationOffset
+			if thermoPilot.Spec.Calibration != nil {
+				offsets = thermoPilot.Spec.Calibration.AirConditioners
+			}
+			calibrated, _ := planner.CalibrateCommand(unit, offsets, planner.Command{
+				DeviceID: deviceID,
+				Setpoint: temp,
+				Mode:     policy.FallbackMode,
+				Power:    planner.PowerOn,
+			})
+			fitted, _, err := planner.FitCommand(unit, thermoPilot.Spec.CapabilityProfiles, calibrated)
+			if err != nil {
+				return err
+			}
+			return sendCommand(ctx, c, unit, fitted)
+		}
+	default:
+		return fmt.Errorf("unsupported onDelete action: %s", action)
+	}
+
+	if r.DryRun || thermoPilot.Spec.DryRun {
+		logger.Info("dry run, skipping onDelete action", "action", action)
+		return nil
+	}
+
+	creds, err := GetSwitchBotCredentials(ctx, r.Client, thermoPilot.Spec, thermoPilot.Namespace)
+	if err != nil {
+		return err
+	}
+	sbClient := newSwitchBotClient(creds, r.Quota, r.ClientOptions)
+	airConditionerIDs, err := r.airConditionerIDs(ctx, thermoPilot, sbClient)
+	if err != nil {
+		return err
+	}
+
+	retries := defaultOnDeleteRetries
+	if policy.MaxRetries > 0 {
+		retries = int(policy.MaxRetries)
+	}
+	var failed []string
+	for _, deviceID := range airConditionerIDs {
+		err := retry(ctx, retries, func() error { return command(sbClient, deviceID) })
+		if err != nil {
+			logger.Error(err, "failed to run onDelete action", "deviceId", deviceID, "action", action)
+			failed = append(failed, fmt.Sprintf("%s: %v", deviceID, err))
+			continue
+		}
+		logger.Info("ran onDelete action", "deviceId", deviceID, "action", action)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to run onDelete action on %d/%d air conditioners: %v", len(failed), len(airConditionerIDs), failed)
+	}
+	return nil
+}
+
+// retry calls fn until it succeeds, retries are exhausted or ctx is done.
+func retry(ctx context.Context, retries int, fn func() error) error {
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if attempt == retries {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+		case <-time.After(onDeleteRetryBackoff * time.Duration(attempt+1)):
+		}
+	}
+	return err
+}
@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+	"github.com/seipan/thermo-pilot-controller/internal/calendar"
+)
+
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+
+// calendarRefIndex indexes ThermoPilots by the name of the ConfigMap holding their
+// occupancy calendar.
+const calendarRefIndex = "spec.occupancy.calendarRef.name"
+
+// indexCalendarRef returns the calendar ConfigMap of a ThermoPilot for calendarRefIndex.
+func indexCalendarRef(obj client.Object) []string {
+	thermoPilot, ok := obj.(*thermopilotv1.ThermoPilot)
+	if !ok || thermoPilot.Spec.Occupancy == nil {
+		return nil
+	}
+	return []string{thermoPilot.Spec.Occupancy.CalendarRef.Name}
+}
+
+// calendarCache keeps the calendars parsed from ConfigMaps, so that a feed is only
+// read and parsed again once its ConfigMap changes.
+type calendarCache struct {
+	mu      sync.Mutex
+	entries map[calendarSource]cachedCalendar
+}
+
+// calendarSource is what a calendar is parsed from: a key of a ConfigMap, read in
+// a time zone.
+type calendarSource struct {
+	configMap types.NamespacedName
+	key       string
+	zone      string
+}
+
+type cachedCalendar struct {
+	resourceVersion string
+	calendar        *calendar.Calendar
+}
+
+// get returns the calendar parsed from source at resourceVersion, if cached.
+func (c *calendarCache) get(source calendarSource, resourceVersion string) (*calendar.Calendar, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[source]
+	if !ok || entry.resourceVersion != resourceVersion {
+		return nil, false
+	}
+	return entry.calendar, true
+}
+
+// put caches the calendar parsed from source at resourceVersion, replacing the one
+// parsed from an older version.
+func (c *calendarCache) put(source calendarSource, resourceVersion string, cal *calendar.Calendar) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = map[calendarSource]cachedCalendar{}
+	}
+	c.entries[source] = cachedCalendar{resourceVersion: resourceVersion, calendar: cal}
+}
+
+// occupancyCalendar returns the calendar of the occupancy schedule of thermoPilot,
+// parsed from its ConfigMap, or nil without an occupancy schedule. ConfigMaps are
+// only cached as metadata, so the ConfigMap is read from the API server, and only
+// when its resourceVersion differs from the one the cached calendar was parsed from.
+func (r *ThermoPilotReconciler) occupancyCalendar(ctx context.Context, thermoPilot *thermopilotv1.ThermoPilot) (*calendar.Calendar, error) {
+	schedule := thermoPilot.Spec.Occupancy
+	if schedule == nil {
+		return nil, nil
+	}
+	ref := schedule.CalendarRef
+	source := calendarSource{
+		configMap: types.NamespacedName{Name: ref.Name, Namespace: thermoPilot.Namespace},
+		key:       ref.Key,
+		zone:      schedule.TimeZone,
+	}
+	if source.key == "" {
+		source.key = "calendar.ics"
+	}
+	if source.zone == "" {
+		source.zone = "UTC"
+	}
+	location, err := time.LoadLocation(source.zone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid occupancy time zone %q", schedule.TimeZone)
+	}
+
+	metadata := &metav1.PartialObjectMetadata{}
+	metadata.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+	if err := r.Get(ctx, source.configMap, metadata); err != nil {
+		return nil, fmt.Errorf("failed to get calendar ConfigMap %s: %w", ref.Name, err)
+	}
+	if cal, ok := r.calendars.get(source, metadata.ResourceVersion); ok {
+		return cal, nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+	reader := r.APIReader
+	if reader == nil {
+		// reconcilers built without a manager read through their client
+		reader = r.Client
+	}
+	if err := reader.Get(ctx, source.configMap, configMap); err != nil {
+		return nil, fmt.Errorf("failed to get calendar ConfigMap %s: %w", ref.Name, err)
+	}
+	data, exists := configMap.Data[source.key]
+	if !exists {
+		binary, ok := configMap.BinaryData[source.key]
+		if !ok {
+			return nil, fmt.Errorf("calendar key '%s' not found in ConfigMap %s", source.key, ref.Name)
+		}
+		data = string(binary)
+	}
+	cal, err := calendar.Parse(data, location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid calendar in ConfigMap %s: %w", ref.Name, err)
+	}
+	r.calendars.put(source, configMap.ResourceVersion, cal)
+	return cal, nil
+}
+
+// thermoPilotsForConfigMap enqueues the ThermoPilots whose occupancy schedule
+// reads a ConfigMap, so that calendar changes apply without waiting for the next
+// periodic reconcile.
+func (r *ThermoPilotReconciler) thermoPilotsForConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	var list thermopilotv1.ThermoPilotList
+	if err := r.List(ctx, &list, client.InNamespace(obj.GetNamespace()), client.MatchingFields{calendarRefIndex: obj.GetName()}); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list ThermoPilots for ConfigMap", "configMap", obj.GetName())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, thermoPilot := range list.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: thermoPilot.Namespace, Name: thermoPilot.Name},
+		})
+	}
+	return requests
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+	"github.com/seipan/thermo-pilot-controller/internal/planner"
+)
+
+const defaultRequeueInterval = planner.DefaultRequeueInterval
+
+// overrideStatus reports the override in force at now, or nil when there is none.
+func overrideStatus(thermoPilot *thermopilotv1.ThermoPilot, now time.Time) *thermopilotv1.OverrideStatus {
+	override := planner.ActiveOverride(thermoPilot.Spec, now)
+	if override == nil {
+		return nil
+	}
+	return &thermopilotv1.OverrideStatus{
+		TargetTemperature: override.TargetTemperature,
+		Mode:              override.Mode,
+		ExpiresAt:         metav1.Time{Time: override.ExpiresAt.Time},
+		Remaining:         override.ExpiresAt.Sub(now).Round(time.Second).String(),
+	}
+}
+
+// requeueAfter returns the delay until the next reconcile, waking up early when
+// an active override expires before the regular interval, to retry the sensors
+// while none can be read, when equipment protection allows a blocked action, or
+// when the effective target is due to move.
+func requeueAfter(thermoPilot *thermopilotv1.ThermoPilot, now time.Time) time.Duration {
+	interval := planner.RequeueAfter(thermoPilot.Spec, now)
+	if sensor := thermoPilot.Status.Sensor; sensor != nil && sensor.FailingSince != nil {
+		interval = min(interval, planner.SensorRetryInterval)
+	}
+	if decision := thermoPilot.Status.LastDecision; decision != nil && decision.BlockedUntil != nil {
+		if untilAllowed := decision.BlockedUntil.Sub(now); untilAllowed > 0 {
+			interval = min(interval, untilAllowed)
+		}
+	}
+	if target := thermoPilot.Status.EffectiveTarget; target != nil && target.NextChange != nil {
+		if untilChange := target.NextChange.Sub(now); untilChange > 0 {
+			interval = min(interval, untilChange)
+		}
+	}
+	return interval
+}
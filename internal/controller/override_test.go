@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+var _ = Describe("Manual override", func() {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	newThermoPilot := func(override *thermopilotv1.Override) *thermopilotv1.ThermoPilot {
+		return &thermopilotv1.ThermoPilot{
+			Spec: thermopilotv1.ThermoPilotSpec{
+				TargetTemperature: "22.0",
+				Mode:              "cool",
+				Override:          override,
+			},
+		}
+	}
+
+	It("reports nothing when there is no override", func() {
+		thermoPilot := newThermoPilot(nil)
+		Expect(overrideStatus(thermoPilot, now)).To(BeNil())
+		Expect(requeueAfter(thermoPilot, now)).To(Equal(defaultRequeueInterval))
+	})
+
+	It("reports the remaining time and wakes up at expiry", func() {
+		thermoPilot := newThermoPilot(&thermopilotv1.Override{
+			TargetTemperature: "25.0",
+			Mode:              "heat",
+			ExpiresAt:         metav1.NewTime(now.Add(90 * time.Second)),
+		})
+		Expect(overrideStatus(thermoPilot, now).Remaining).To(Equal("1m30s"))
+		Expect(requeueAfter(thermoPilot, now)).To(Equal(90 * time.Second))
+	})
+
+	It("reports nothing once expired", func() {
+		thermoPilot := newThermoPilot(&thermopilotv1.Override{
+			TargetTemperature: "25.0",
+			ExpiresAt:         metav1.NewTime(now.Add(-time.Minute)),
+		})
+		Expect(overrideStatus(thermoPilot, now)).To(BeNil())
+		Expect(requeueAfter(thermoPilot, now)).To(Equal(defaultRequeueInterval))
+	})
+
+	It("retries the sensors sooner while none can be read", func() {
+		thermoPilot := newThermoPilot(nil)
+		thermoPilot.Status.Sensor = &thermopilotv1.SensorStatus{
+			Source:       "held",
+			FailingSince: &metav1.Time{Time: now.Add(-2 * time.Minute)},
+		}
+		Expect(requeueAfter(thermoPilot, now)).To(Equal(time.Minute))
+	})
+
+	It("wakes up when the ramp moves the effective target", func() {
+		thermoPilot := newThermoPilot(nil)
+		thermoPilot.Status.EffectiveTarget = &thermopilotv1.EffectiveTargetStatus{
+			Temperature: "23.0",
+			Desired:     "22.0",
+			Since:       metav1.NewTime(now.Add(-13 * time.Minute)),
+			NextChange:  &metav1.Time{Time: now.Add(2 * time.Minute)},
+		}
+		Expect(requeueAfter(thermoPilot, now)).To(Equal(2 * time.Minute))
+	})
+})
@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	switchbotclient "github.com/seipan/thermo-pilot-controller/internal/client"
+)
+
+// SwitchBotDailyLimit is the number of API requests SwitchBot allows per account and day.
+const SwitchBotDailyLimit = 10000
+
+// QuotaTracker counts SwitchBot API requests per account so that every
+// ThermoPilot sharing an account contributes to the same daily budget. Counters
+// live in memory; the account reconcilers persist them in the account status and
+// restore them after a restart or a leader change.
+type QuotaTracker struct {
+	mu       sync.Mutex
+	now      func() time.Time
+	counters map[string]*quotaCounter
+}
+
+type quotaCounter struct {
+	day      time.Time
+	requests int64
+	// restored is set once the count persisted for the day has been added
+	restored bool
+}
+
+func NewQuotaTracker() *QuotaTracker {
+	return &QuotaTracker{
+		now:      time.Now,
+		counters: map[string]*quotaCounter{},
+	}
+}
+
+// Record counts a single request against the account identified by key.
+func (q *QuotaTracker) Record(key string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	c := q.counter(key)
+	c.requests++
+}
+
+// Restore adds the requests persisted for key, counted by a previous process
+// until resetTime, to the requests recorded since. Only the first call of the day
+// has an effect, and counts persisted for a previous day are ignored.
+func (q *QuotaTracker) Restore(key string, requests int64, resetTime time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	c := q.counter(key)
+	if c.restored {
+		return
+	}
+	c.restored = true
+	if resetTime.Equal(c.day.AddDate(0, 0, 1)) {
+		c.requests += requests
+	}
+}
+
+// Usage returns the requests recorded today for key and the time the counter resets.
+func (q *QuotaTracker) Usage(key string) (int64, time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	c := q.counter(key)
+	return c.requests, c.day.AddDate(0, 0, 1)
+}
+
+func (q *QuotaTracker) counter(key string) *quotaCounter {
+	day := q.now().UTC().Truncate(24 * time.Hour)
+	c, ok := q.counters[key]
+	if !ok || !c.day.Equal(day) {
+		c = &quotaCounter{day: day}
+		q.counters[key] = c
+	}
+	return c
+}
+
+// Transport wraps base so that every request is recorded against key.
+func (q *QuotaTracker) Transport(key string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &quotaTransport{tracker: q, key: key, base: base}
+}
+
+type quotaTransport struct {
+	tracker *QuotaTracker
+	key     string
+	base    http.RoundTripper
+}
+
+func (t *quotaTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.tracker.Record(t.key)
+	return t.base.RoundTrip(req)
+}
+
+// newSwitchBotClient builds an API client whose requests are counted against the account quota.
+func newSwitchBotClient(creds *SwitchBotCredentials, quota *QuotaTracker, opts []switchbotclient.Option) *switchbotclient.Client {
+	sbClient := switchbotclient.NewClient(creds.Token, creds.Secret, opts...)
+	if quota != nil {
+		sbClient.HttpClient.Transport = quota.Transport(creds.AccountKey, sbClient.HttpClient.Transport)
+	}
+	return sbClient
+}
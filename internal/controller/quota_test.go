@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("QuotaTracker", func() {
+	var (
+		tracker *QuotaTracker
+		now     time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		tracker = NewQuotaTracker()
+		tracker.now = func() time.Time { return now }
+	})
+
+	It("should add the persisted count of the day once", func() {
+		tracker.Record("home")
+		tracker.Restore("home", 100, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC))
+		tracker.Restore("home", 100, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC))
+
+		requests, _ := tracker.Usage("home")
+		Expect(requests).To(Equal(int64(101)))
+	})
+
+	It("should ignore a count persisted for a previous day", func() {
+		tracker.Restore("home", 100, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+		requests, _ := tracker.Usage("home")
+		Expect(requests).To(BeZero())
+	})
+})
@@ -11,13 +11,66 @@ import (
 	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
 )
 
+const (
+	KindSwitchBotAccount        = "SwitchBotAccount"
+	KindClusterSwitchBotAccount = "ClusterSwitchBotAccount"
+)
+
 type SwitchBotCredentials struct {
 	Token  string
 	Secret string
+	// AccountKey identifies the SwitchBot account for quota accounting
+	AccountKey string
 }
 
 func GetSwitchBotCredentials(ctx context.Context, c client.Client, spec thermopilotv1.ThermoPilotSpec, namespace string) (*SwitchBotCredentials, error) {
-	secretRef := spec.SecretRef
+	if spec.AccountRef == nil {
+		creds, err := getSecretCredentials(ctx, c, spec.SecretRef, namespace)
+		if err != nil {
+			return nil, err
+		}
+		creds.AccountKey = fmt.Sprintf("Secret/%s/%s", namespace, spec.SecretRef.Name)
+		return creds, nil
+	}
+
+	accountRef := spec.AccountRef
+	switch accountRef.Kind {
+	case KindSwitchBotAccount, "":
+		account := &thermopilotv1.SwitchBotAccount{}
+		if err := c.Get(ctx, types.NamespacedName{Name: accountRef.Name, Namespace: namespace}, account); err != nil {
+			return nil, fmt.Errorf("failed to get SwitchBotAccount %s: %w", accountRef.Name, err)
+		}
+		return GetAccountCredentials(ctx, c, account)
+	case KindClusterSwitchBotAccount:
+		account := &thermopilotv1.ClusterSwitchBotAccount{}
+		if err := c.Get(ctx, types.NamespacedName{Name: accountRef.Name}, account); err != nil {
+			return nil, fmt.Errorf("failed to get ClusterSwitchBotAccount %s: %w", accountRef.Name, err)
+		}
+		return GetClusterAccountCredentials(ctx, c, account)
+	default:
+		return nil, fmt.Errorf("unsupported account kind: %s", accountRef.Kind)
+	}
+}
+
+func GetAccountCredentials(ctx context.Context, c client.Client, account *thermopilotv1.SwitchBotAccount) (*SwitchBotCredentials, error) {
+	creds, err := getSecretCredentials(ctx, c, account.Spec.SecretRef, account.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	creds.AccountKey = fmt.Sprintf("%s/%s/%s", KindSwitchBotAccount, account.Namespace, account.Name)
+	return creds, nil
+}
+
+func GetClusterAccountCredentials(ctx context.Context, c client.Client, account *thermopilotv1.ClusterSwitchBotAccount) (*SwitchBotCredentials, error) {
+	creds, err := getSecretCredentials(ctx, c, account.Spec.SecretRef.SecretReference, account.Spec.SecretRef.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	creds.AccountKey = fmt.Sprintf("%s/%s", KindClusterSwitchBotAccount, account.Name)
+	return creds, nil
+}
+
+func getSecretCredentials(ctx context.Context, c client.Client, secretRef thermopilotv1.SecretReference, namespace string) (*SwitchBotCredentials, error) {
 	tokenKey := secretRef.TokenKey
 	if tokenKey == "" {
 		tokenKey = "token"
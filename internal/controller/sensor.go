@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+	switchbotclient "github.com/seipan/thermo-pilot-controller/internal/client"
+	"github.com/seipan/thermo-pilot-controller/internal/planner"
+)
+
+// sensorReading is a reading together with the sensor it came from.
+type sensorReading struct {
+	*switchbotclient.MeterStatus
+	source   string
+	deviceID string
+}
+
+// readSensors reads the primary sensor and then the fallback sensors of the
+// policy in order, and returns the first reading obtained. The error describes
+// every sensor that failed before it, and is set together with a reading when a
+// fallback was used. reason classifies the failure of the primary sensor.
+func (r *ThermoPilotReconciler) readSensors(ctx context.Context, thermoPilot *thermopilotv1.ThermoPilot, sbClient *switchbotclient.Client) (*sensorReading, string, error) {
+	logger := log.FromContext(ctx)
+
+	sensorID, reason, err := r.primarySensorID(ctx, thermoPilot, sbClient)
+	if err == nil {
+		var status *switchbotclient.MeterStatus
+		if status, err = sbClient.GetMeterStatus(ctx, sensorID); err == nil {
+			return &sensorReading{MeterStatus: status, source: planner.SourcePrimary, deviceID: sensorID}, "", nil
+		}
+		reason = ReasonTemperatureSensorError
+		err = fmt.Errorf("primary sensor %s: %w", sensorID, err)
+	}
+	if reason == ReasonConfigError {
+		return nil, reason, err
+	}
+	failures := []error{err}
+
+	if policy := thermoPilot.Spec.SensorFailurePolicy; policy != nil {
+		for i, source := range policy.FallbackSensors {
+			deviceID := source.DeviceID
+			if source.DeviceRef != "" {
+				if deviceID, err = ResolveDeviceID(ctx, r.Client, thermoPilot, source.DeviceRef); err != nil {
+					failures = append(failures, fmt.Errorf("fallback sensor %d: %w", i, err))
+					continue
+				}
+			}
+			status, err := sbClient.GetMeterStatus(ctx, deviceID)
+			if err != nil {
+				failures = append(failures, fmt.Errorf("fallback sensor %s: %w", deviceID, err))
+				continue
+			}
+			logger.Info("using fallback temperature sensor", "deviceId", deviceID, "errors", failures)
+			return &sensorReading{MeterStatus: status, source: planner.SourceFallback, deviceID: deviceID}, reason, errors.Join(failures...)
+		}
+	}
+	return nil, reason, errors.Join(failures...)
+}
+
+// primarySensorID returns the device ID of the temperature sensor configured in the spec.
+func (r *ThermoPilotReconciler) primarySensorID(ctx context.Context, thermoPilot *thermopilotv1.ThermoPilot, sbClient *switchbotclient.Client) (string, string, error) {
+	logger := log.FromContext(ctx)
+	switch {
+	case thermoPilot.Spec.TemperatureSensorRef != "":
+		sensorID, err := ResolveDeviceID(ctx, r.Client, thermoPilot, thermoPilot.Spec.TemperatureSensorRef)
+		if err != nil {
+			return "", ReasonTemperatureSensorNotFound, err
+		}
+		logger.Info("using referenced temperature sensor", "device", thermoPilot.Spec.TemperatureSensorRef, "deviceId", sensorID)
+		return sensorID, "", nil
+	case thermoPilot.Spec.TemperatureSensorType == "MeterPro":
+		meterPro, err := sbClient.GetMeterPro(ctx)
+		if err != nil {
+			return "", ReasonTemperatureSensorNotFound, err
+		}
+		logger.Info("found temperature sensor", "type", thermoPilot.Spec.TemperatureSensorType, "deviceId", meterPro.DeviceID, "name", meterPro.DeviceName)
+		return meterPro.DeviceID, "", nil
+	default:
+		return "", ReasonConfigError, fmt.Errorf("unsupported temperature sensor type: %s", thermoPilot.Spec.TemperatureSensorType)
+	}
+}
+
+// sensorStatus describes the source of the reading in use. failingSince is kept
+// from previous while no sensor can be read.
+func sensorStatus(previous *thermopilotv1.SensorStatus, source, deviceID string, now time.Time) *thermopilotv1.SensorStatus {
+	status := &thermopilotv1.SensorStatus{Source: source, DeviceID: deviceID}
+	if source == planner.SourcePrimary || source == planner.SourceFallback {
+		return status
+	}
+	failingSince := metav1.NewTime(now)
+	if previous != nil && previous.FailingSince != nil {
+		failingSince = *previous.FailingSince
+	}
+	status.FailingSince = &failingSince
+	status.Staleness = now.Sub(failingSince.Time).Round(time.Second).String()
+	return status
+}
+
+// checkSensorHealth records the battery level of reading and since when it has not
+// changed, and reports a frozen or low battery sensor in report. A Warning event is
+// emitted when the sensor turns unhealthy for either reason.
+func (r *ThermoPilotReconciler) checkSensorHealth(thermoPilot *thermopilotv1.ThermoPilot, previous thermopilotv1.ThermoPilotStatus, reading *sensorReading, report *reconcileReport, now time.Time) {
+	health := planner.CheckSensor(thermoPilot.Spec, previous, planner.Reading{
+		DeviceID:    reading.deviceID,
+		Temperature: reading.Temperature,
+		Humidity:    reading.Humidity,
+		Battery:     reading.Battery,
+	}, now)
+	unchangedSince := metav1.NewTime(health.UnchangedSince)
+	thermoPilot.Status.Sensor.UnchangedSince = &unchangedSince
+	thermoPilot.Status.Sensor.Battery = health.Battery
+
+	var issues []string
+	switch {
+	case health.Frozen != "":
+		report.sensorIssueReason = ReasonSensorFrozen
+		issues = append(issues, health.Frozen)
+		if health.LowBattery != "" {
+			issues = append(issues, health.LowBattery)
+		}
+	case health.LowBattery != "":
+		report.sensorIssueReason = ReasonLowBattery
+		issues = append(issues, health.LowBattery)
+	default:
+		return
+	}
+	report.sensorIssue = errors.New(strings.Join(issues, "; "))
+
+	// while a sensor warning is reported the issue does not show in the condition
+	if report.sensorWarning != nil {
+		return
+	}
+	if condition := meta.FindStatusCondition(previous.Conditions, ConditionSensorHealthy); condition == nil || condition.Reason != report.sensorIssueReason {
+		r.event(thermoPilot, corev1.EventTypeWarning, report.sensorIssueReason, fmt.Sprintf("Sensor looks unhealthy: %v", report.sensorIssue))
+	}
+}
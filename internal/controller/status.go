@@ -0,0 +1,35 @@
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+// patchStatus writes the status of obj as a merge patch computed against original.
+// Only the changed fields are sent and no resourceVersion precondition is set, so
+// the write does not fail when the object was modified since it was read.
+func patchStatus(ctx context.Context, c client.Client, obj, original client.Object) error {
+	return c.Status().Patch(ctx, obj, client.MergeFrom(original))
+}
+
+// thermoPilotStatusChanged reports whether current differs from original in
+// anything but the fields that change on every reconcile by construction: the
+// decision timestamp and the remaining override time.
+func thermoPilotStatusChanged(original, current *thermopilotv1.ThermoPilotStatus) bool {
+	normalize := func(status *thermopilotv1.ThermoPilotStatus) *thermopilotv1.ThermoPilotStatus {
+		status = status.DeepCopy()
+		if status.LastDecision != nil {
+			status.LastDecision.Time = metav1.Time{}
+		}
+		if status.ActiveOverride != nil {
+			status.ActiveOverride.Remaining = ""
+		}
+		return status
+	}
+	return !equality.Semantic.DeepEqual(normalize(original), normalize(current))
+}
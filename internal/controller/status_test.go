@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+	"github.com/seipan/thermo-pilot-controller/internal/planner"
+)
+
+var _ = Describe("ThermoPilot status changes", func() {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	newStatus := func(decisionTime time.Time, action string) *thermopilotv1.ThermoPilotStatus {
+		return &thermopilotv1.ThermoPilotStatus{
+			CurrentTemperature: "23.5",
+			LastDecision: &thermopilotv1.Decision{
+				Time:   metav1.NewTime(decisionTime),
+				Action: action,
+			},
+			ActiveOverride: &thermopilotv1.OverrideStatus{Remaining: "10m0s"},
+		}
+	}
+
+	It("ignores fields that change on every reconcile", func() {
+		current := newStatus(now.Add(5*time.Minute), "none")
+		current.ActiveOverride.Remaining = "5m0s"
+		Expect(thermoPilotStatusChanged(newStatus(now, "none"), current)).To(BeFalse())
+	})
+
+	It("detects a changed decision", func() {
+		Expect(thermoPilotStatusChanged(newStatus(now, "none"), newStatus(now, "cooling"))).To(BeTrue())
+	})
+
+	It("ignores an effective target holding still", func() {
+		spec := thermopilotv1.ThermoPilotSpec{TargetTemperature: "24.0", Mode: planner.ModeCool,
+			Ramp: &thermopilotv1.Ramp{Rate: "1.0"}}
+		decide := func(previous *thermopilotv1.ThermoPilotStatus, at time.Time) *thermopilotv1.ThermoPilotStatus {
+			plan, err := planner.Decide(planner.Input{Now: at, CurrentTemperature: 24.0, Spec: spec, Previous: *previous})
+			Expect(err).NotTo(HaveOccurred())
+			status := previous.DeepCopy()
+			plan.Record(status)
+			return status
+		}
+		first := decide(&thermopilotv1.ThermoPilotStatus{}, now)
+		second := decide(first, now.Add(5*time.Minute))
+		Expect(thermoPilotStatusChanged(first, second)).To(BeFalse())
+	})
+
+	It("detects a first decision", func() {
+		Expect(thermoPilotStatusChanged(&thermopilotv1.ThermoPilotStatus{}, newStatus(now, "none"))).To(BeTrue())
+	})
+})
@@ -0,0 +1,261 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+	switchbotclient "github.com/seipan/thermo-pilot-controller/internal/client"
+)
+
+const defaultAccountSyncInterval = time.Hour
+
+// secretRefIndex indexes accounts by the Secret holding their credentials, as
+// namespace/name.
+const secretRefIndex = "spec.secretRef"
+
+// SwitchBotAccountReconciler reconciles a SwitchBotAccount object
+type SwitchBotAccountReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Quota  *QuotaTracker
+	// ClientOptions configure every SwitchBot API client created by the reconciler
+	ClientOptions []switchbotclient.Option
+}
+
+// +kubebuilder:rbac:groups=thermo-pilot.yadon3141.com,resources=switchbotaccounts,verbs=get;list;watch
+// +kubebuilder:rbac:groups=thermo-pilot.yadon3141.com,resources=switchbotaccounts/status,verbs=get;update;patch
+
+// Reconcile validates the account credentials, syncs the device inventory and
+// reports quota usage in the status.
+func (r *SwitchBotAccountReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	var account thermopilotv1.SwitchBotAccount
+	if err := r.Get(ctx, req.NamespacedName, &account); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "unable to fetch SwitchBotAccount")
+		return ctrl.Result{}, err
+	}
+	original := account.DeepCopy()
+
+	creds, err := GetAccountCredentials(ctx, r.Client, &account)
+	inventory, sbClient, syncErr := syncAccount(ctx, creds, err, r.Quota, r.ClientOptions, &account.Status, account.Generation)
+	if syncErr == nil {
+		accountRef := thermopilotv1.AccountReference{Kind: KindSwitchBotAccount, Name: account.Name}
+		if syncErr = syncDevices(ctx, r.Client, r.Scheme, &account, accountRef, account.Namespace, sbClient, inventory); syncErr != nil {
+			logger.Error(syncErr, "failed to sync SwitchBot devices")
+			setAccountCondition(&account.Status, "Ready", metav1.ConditionFalse, "DeviceSyncError", syncErr.Error(), account.Generation)
+		}
+	}
+	if err := patchStatus(ctx, r.Client, &account, original); err != nil {
+		logger.Error(err, "failed to update status")
+		return ctrl.Result{}, err
+	}
+	if syncErr != nil {
+		return ctrl.Result{}, syncErr
+	}
+	return ctrl.Result{RequeueAfter: accountSyncInterval(account.Spec.SyncInterval)}, nil
+}
+
+func (r *SwitchBotAccountReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &thermopilotv1.SwitchBotAccount{}, secretRefIndex,
+		func(obj client.Object) []string {
+			account := obj.(*thermopilotv1.SwitchBotAccount)
+			return []string{account.Namespace + "/" + account.Spec.SecretRef.Name}
+		}); err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&thermopilotv1.SwitchBotAccount{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// Fixed credentials take effect at once instead of at the next sync.
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.accountsForSecret)).
+		Named("switchbotaccount").
+		Complete(r)
+}
+
+// ClusterSwitchBotAccountReconciler reconciles a ClusterSwitchBotAccount object
+type ClusterSwitchBotAccountReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Quota  *QuotaTracker
+	// ClientOptions configure every SwitchBot API client created by the reconciler
+	ClientOptions []switchbotclient.Option
+}
+
+// +kubebuilder:rbac:groups=thermo-pilot.yadon3141.com,resources=clusterswitchbotaccounts,verbs=get;list;watch
+// +kubebuilder:rbac:groups=thermo-pilot.yadon3141.com,resources=clusterswitchbotaccounts/status,verbs=get;update;patch
+
+// Reconcile validates the account credentials, syncs the device inventory and
+// reports quota usage in the status.
+func (r *ClusterSwitchBotAccountReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	var account thermopilotv1.ClusterSwitchBotAccount
+	if err := r.Get(ctx, req.NamespacedName, &account); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "unable to fetch ClusterSwitchBotAccount")
+		return ctrl.Result{}, err
+	}
+	original := account.DeepCopy()
+
+	creds, err := GetClusterAccountCredentials(ctx, r.Client, &account)
+	inventory, sbClient, syncErr := syncAccount(ctx, creds, err, r.Quota, r.ClientOptions, &account.Status, account.Generation)
+	if syncErr == nil && account.Spec.DeviceNamespace != "" {
+		accountRef := thermopilotv1.AccountReference{Kind: KindClusterSwitchBotAccount, Name: account.Name}
+		if syncErr = syncDevices(ctx, r.Client, r.Scheme, &account, accountRef, account.Spec.DeviceNamespace, sbClient, inventory); syncErr != nil {
+			logger.Error(syncErr, "failed to sync SwitchBot devices")
+			setAccountCondition(&account.Status, "Ready", metav1.ConditionFalse, "DeviceSyncError", syncErr.Error(), account.Generation)
+		}
+	}
+	if err := patchStatus(ctx, r.Client, &account, original); err != nil {
+		logger.Error(err, "failed to update status")
+		return ctrl.Result{}, err
+	}
+	if syncErr != nil {
+		return ctrl.Result{}, syncErr
+	}
+	return ctrl.Result{RequeueAfter: accountSyncInterval(account.Spec.SyncInterval)}, nil
+}
+
+func (r *ClusterSwitchBotAccountReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &thermopilotv1.ClusterSwitchBotAccount{}, secretRefIndex,
+		func(obj client.Object) []string {
+			ref := obj.(*thermopilotv1.ClusterSwitchBotAccount).Spec.SecretRef
+			return []string{ref.Namespace + "/" + ref.Name}
+		}); err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&thermopilotv1.ClusterSwitchBotAccount{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.accountsForSecret)).
+		Named("clusterswitchbotaccount").
+		Complete(r)
+}
+
+// accountsForSecret enqueues the SwitchBotAccounts whose credentials a Secret holds.
+func (r *SwitchBotAccountReconciler) accountsForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	var list thermopilotv1.SwitchBotAccountList
+	if err := r.List(ctx, &list, client.InNamespace(obj.GetNamespace()),
+		client.MatchingFields{secretRefIndex: obj.GetNamespace() + "/" + obj.GetName()}); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list SwitchBotAccounts for Secret", "secret", obj.GetName())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, account := range list.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: account.Namespace, Name: account.Name},
+		})
+	}
+	return requests
+}
+
+// accountsForSecret enqueues the ClusterSwitchBotAccounts whose credentials a
+// Secret holds.
+func (r *ClusterSwitchBotAccountReconciler) accountsForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	var list thermopilotv1.ClusterSwitchBotAccountList
+	if err := r.List(ctx, &list, client.MatchingFields{secretRefIndex: obj.GetNamespace() + "/" + obj.GetName()}); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list ClusterSwitchBotAccounts for Secret", "secret", obj.GetName())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, account := range list.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: account.Name}})
+	}
+	return requests
+}
+
+// syncAccount validates the credentials against the SwitchBot API, refreshes the
+// inventory counters and records the outcome in status. credsErr is the error
+// returned while resolving the credentials, if any. On success it returns the
+// inventory and the client used to fetch it.
+func syncAccount(ctx context.Context, creds *SwitchBotCredentials, credsErr error, quota *QuotaTracker, opts []switchbotclient.Option, status *thermopilotv1.SwitchBotAccountStatus, generation int64) (*switchbotclient.ListDeviceResponse, *switchbotclient.Client, error) {
+	logger := log.FromContext(ctx)
+	if credsErr != nil {
+		logger.Error(credsErr, "failed to get SwitchBot credentials")
+		setAccountCondition(status, "CredentialsValid", metav1.ConditionFalse, "SecretError", credsErr.Error(), generation)
+		setAccountCondition(status, "Ready", metav1.ConditionFalse, "CredentialsError", credsErr.Error(), generation)
+		return nil, nil, credsErr
+	}
+
+	if quota != nil && status.Quota != nil && status.Quota.ResetTime != nil {
+		quota.Restore(creds.AccountKey, status.Quota.RequestsToday, status.Quota.ResetTime.Time)
+	}
+	sbClient := newSwitchBotClient(creds, quota, opts)
+	devices, err := sbClient.ListDevices(ctx)
+	if quota != nil {
+		requests, resetTime := quota.Usage(creds.AccountKey)
+		status.Quota = &thermopilotv1.QuotaStatus{
+			RequestsToday: requests,
+			DailyLimit:    SwitchBotDailyLimit,
+			ResetTime:     &metav1.Time{Time: resetTime},
+		}
+	}
+	if err != nil {
+		logger.Error(err, "failed to list SwitchBot devices")
+		if switchbotclient.IsUnauthorized(err) {
+			setAccountCondition(status, "CredentialsValid", metav1.ConditionFalse, "Unauthorized", err.Error(), generation)
+		}
+		setAccountCondition(status, "Ready", metav1.ConditionFalse, "SyncError", err.Error(), generation)
+		return nil, nil, err
+	}
+
+	status.DeviceCount = int32(len(devices.Body.DeviceList))
+	status.InfraredRemoteCount = int32(len(devices.Body.InfraredRemoteList))
+	status.LastSyncTime = &metav1.Time{Time: time.Now()}
+	setAccountCondition(status, "CredentialsValid", metav1.ConditionTrue, "Authenticated", "SwitchBot API accepted the credentials", generation)
+	setAccountCondition(status, "Ready", metav1.ConditionTrue, "Synced",
+		fmt.Sprintf("Synced %d devices and %d infrared remotes", status.DeviceCount, status.InfraredRemoteCount), generation)
+	logger.Info("synced SwitchBot account", "devices", status.DeviceCount, "remotes", status.InfraredRemoteCount)
+	return devices, sbClient, nil
+}
+
+func setAccountCondition(status *thermopilotv1.SwitchBotAccountStatus, conditionType string, conditionStatus metav1.ConditionStatus, reason, message string, generation int64) {
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: generation,
+	})
+}
+
+func accountSyncInterval(interval *metav1.Duration) time.Duration {
+	if interval == nil || interval.Duration <= 0 {
+		return defaultAccountSyncInterval
+	}
+	return interval.Duration
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+var _ = Describe("SwitchBotAccount Controller", func() {
+	Context("When the referenced Secret does not exist", func() {
+		const resourceName = "test-account"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind SwitchBotAccount")
+			account := &thermopilotv1.SwitchBotAccount{}
+			err := k8sClient.Get(ctx, typeNamespacedName, account)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &thermopilotv1.SwitchBotAccount{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      resourceName,
+						Namespace: "default",
+					},
+					Spec: thermopilotv1.SwitchBotAccountSpec{
+						SecretRef: thermopilotv1.SecretReference{
+							Name: "missing-secret",
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			resource := &thermopilotv1.SwitchBotAccount{}
+			err := k8sClient.Get(ctx, typeNamespacedName, resource)
+			if err == nil {
+				By("Cleanup the specific resource instance SwitchBotAccount")
+				Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			}
+		})
+
+		It("should report invalid credentials", func() {
+			By("Reconciling the created resource")
+			controllerReconciler := &SwitchBotAccountReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+				Quota:  NewQuotaTracker(),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).To(HaveOccurred())
+
+			account := &thermopilotv1.SwitchBotAccount{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, account)).To(Succeed())
+			Expect(meta.IsStatusConditionFalse(account.Status.Conditions, "CredentialsValid")).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(account.Status.Conditions, "Ready")).To(BeTrue())
+		})
+	})
+})
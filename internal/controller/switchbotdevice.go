@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+	switchbotclient "github.com/seipan/thermo-pilot-controller/internal/client"
+)
+
+const (
+	accountKindLabel = "thermo-pilot.yadon3141.com/account-kind"
+	accountNameLabel = "thermo-pilot.yadon3141.com/account"
+)
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// +kubebuilder:rbac:groups=thermo-pilot.yadon3141.com,resources=switchbotdevices,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=thermo-pilot.yadon3141.com,resources=switchbotdevices/status,verbs=get;update;patch
+
+// syncDevices materializes every entry of the inventory as a SwitchBotDevice owned by
+// the account, and deletes the objects of devices that were removed from the account.
+func syncDevices(ctx context.Context, c client.Client, scheme *runtime.Scheme, owner client.Object, accountRef thermopilotv1.AccountReference, namespace string, sbClient *switchbotclient.Client, inventory *switchbotclient.ListDeviceResponse) error {
+	logger := log.FromContext(ctx)
+	now := metav1.Time{Time: time.Now()}
+	seen := map[string]bool{}
+
+	specs := make([]thermopilotv1.SwitchBotDeviceSpec, 0, len(inventory.Body.DeviceList)+len(inventory.Body.InfraredRemoteList))
+	for _, d := range inventory.Body.DeviceList {
+		specs = append(specs, thermopilotv1.SwitchBotDeviceSpec{
+			AccountRef:         accountRef,
+			DeviceID:           d.DeviceID,
+			DeviceName:         d.DeviceName,
+			DeviceType:         d.DeviceType,
+			HubDeviceID:        d.HubDeviceID,
+			EnableCloudService: d.EnableCloudService,
+		})
+	}
+	for _, d := range inventory.Body.InfraredRemoteList {
+		specs = append(specs, thermopilotv1.SwitchBotDeviceSpec{
+			AccountRef:  accountRef,
+			DeviceID:    d.DeviceID,
+			DeviceName:  d.DeviceName,
+			DeviceType:  d.RemoteType,
+			Infrared:    true,
+			HubDeviceID: d.HubDeviceID,
+		})
+	}
+
+	for _, spec := range specs {
+		device := &thermopilotv1.SwitchBotDevice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      DeviceObjectName(accountRef, spec.DeviceID),
+				Namespace: namespace,
+			},
+		}
+		seen[device.Name] = true
+		if _, err := controllerutil.CreateOrUpdate(ctx, c, device, func() error {
+			if device.Labels == nil {
+				device.Labels = map[string]string{}
+			}
+			device.Labels[accountKindLabel] = accountRef.Kind
+			device.Labels[accountNameLabel] = accountRef.Name
+			device.Spec = spec
+			return controllerutil.SetControllerReference(owner, device, scheme)
+		}); err != nil {
+			return fmt.Errorf("failed to apply SwitchBotDevice %s: %w", device.Name, err)
+		}
+
+		original := device.DeepCopy()
+		device.Status.LastSeenTime = &now
+		if !spec.Infrared && spec.EnableCloudService {
+			status, err := sbClient.GetDeviceStatus(ctx, spec.DeviceID)
+			if err != nil {
+				logger.Error(err, "failed to get device status", "deviceId", spec.DeviceID)
+			} else {
+				device.Status.DeviceStatus = &runtime.RawExtension{Raw: status}
+			}
+		}
+		if err := patchStatus(ctx, c, device, original); err != nil {
+			return fmt.Errorf("failed to update SwitchBotDevice %s status: %w", device.Name, err)
+		}
+	}
+
+	var existing thermopilotv1.SwitchBotDeviceList
+	if err := c.List(ctx, &existing, client.InNamespace(namespace), client.MatchingLabels{
+		accountKindLabel: accountRef.Kind,
+		accountNameLabel: accountRef.Name,
+	}); err != nil {
+		return fmt.Errorf("failed to list SwitchBotDevices: %w", err)
+	}
+	for i := range existing.Items {
+		device := &existing.Items[i]
+		if seen[device.Name] {
+			continue
+		}
+		logger.Info("deleting removed SwitchBot device", "name", device.Name, "deviceId", device.Spec.DeviceID)
+		if err := client.IgnoreNotFound(c.Delete(ctx, device)); err != nil {
+			return fmt.Errorf("failed to delete SwitchBotDevice %s: %w", device.Name, err)
+		}
+	}
+	return nil
+}
+
+// ResolveDeviceID returns the SwitchBot device ID mirrored by the named SwitchBotDevice.
+// The device must have been mirrored by the account thermoPilot uses, so that the ID
+// is never sent with the credentials of another account.
+func ResolveDeviceID(ctx context.Context, c client.Client, thermoPilot *thermopilotv1.ThermoPilot, name string) (string, error) {
+	accountRef := thermoPilot.Spec.AccountRef
+	if accountRef == nil {
+		return "", fmt.Errorf("SwitchBotDevice %s can only be referenced with accountRef", name)
+	}
+	kind := accountRef.Kind
+	if kind == "" {
+		kind = KindSwitchBotAccount
+	}
+	device := &thermopilotv1.SwitchBotDevice{}
+	if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: thermoPilot.Namespace}, device); err != nil {
+		return "", fmt.Errorf("failed to get SwitchBotDevice %s: %w", name, err)
+	}
+	owner := metav1.GetControllerOf(device)
+	if device.Spec.AccountRef.Kind != kind || device.Spec.AccountRef.Name != accountRef.Name ||
+		owner == nil || owner.Kind != kind || owner.Name != accountRef.Name {
+		return "", fmt.Errorf("SwitchBotDevice %s belongs to %s %s, not to %s %s",
+			name, device.Spec.AccountRef.Kind, device.Spec.AccountRef.Name, kind, accountRef.Name)
+	}
+	return device.Spec.DeviceID, nil
+}
+
+// DeviceObjectName returns the name of the SwitchBotDevice mirroring deviceID in the
+// account accountRef. The readable part is lowercased and loses the characters not
+// allowed in names, so a hash of the account and the device ID keeps names of
+// different devices apart.
+func DeviceObjectName(accountRef thermopilotv1.AccountReference, deviceID string) string {
+	name := strings.ToLower(accountRef.Name + "-" + deviceID)
+	name = strings.Trim(invalidNameChars.ReplaceAllString(name, "-"), "-")
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(accountRef.Kind + "/" + accountRef.Name + "/" + deviceID))
+	return fmt.Sprintf("%s-%08x", name, hash.Sum32())
+}
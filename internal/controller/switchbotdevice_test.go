@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+var _ = Describe("DeviceObjectName", func() {
+	account := func(name string) thermopilotv1.AccountReference {
+		return thermopilotv1.AccountReference{Kind: KindSwitchBotAccount, Name: name}
+	}
+
+	DescribeTable("builds a valid object name",
+		func(account thermopilotv1.AccountReference, deviceID, want string) {
+			Expect(DeviceObjectName(account, deviceID)).To(Equal(want))
+		},
+		Entry("physical device", account("home"), "C271111EC0AB", "home-c271111ec0ab-4ee19ca7"),
+		Entry("infrared remote", account("home"), "02-202509160308-19770636", "home-02-202509160308-19770636-93d0c22b"),
+		Entry("dotted account name", account("my.home"), "ABC", "my-home-abc-bb786572"),
+	)
+
+	It("keeps devices apart when their readable names collide", func() {
+		Expect(DeviceObjectName(account("my.home"), "ABC")).NotTo(Equal(DeviceObjectName(account("my-home"), "ABC")))
+		Expect(DeviceObjectName(account("home"), "abc")).NotTo(Equal(DeviceObjectName(account("home"), "ABC")))
+		Expect(DeviceObjectName(account("home"), "ABC")).NotTo(Equal(DeviceObjectName(
+			thermopilotv1.AccountReference{Kind: KindClusterSwitchBotAccount, Name: "home"}, "ABC")))
+	})
+})
+
+var _ = Describe("ResolveDeviceID", func() {
+	ctx := context.Background()
+
+	var device *thermopilotv1.SwitchBotDevice
+
+	thermoPilotWith := func(accountRef *thermopilotv1.AccountReference) *thermopilotv1.ThermoPilot {
+		return &thermopilotv1.ThermoPilot{
+			ObjectMeta: metav1.ObjectMeta{Name: "bedroom", Namespace: "default"},
+			Spec:       thermopilotv1.ThermoPilotSpec{AccountRef: accountRef},
+		}
+	}
+
+	BeforeEach(func() {
+		controller := true
+		device = &thermopilotv1.SwitchBotDevice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "home-c271111ec0ab-4ee19ca7",
+				Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: thermopilotv1.GroupVersion.String(),
+					Kind:       KindSwitchBotAccount,
+					Name:       "home",
+					UID:        "0b7a4c5e-1f6d-4d8e-9a55-3c2a3b3f4d11",
+					Controller: &controller,
+				}},
+			},
+			Spec: thermopilotv1.SwitchBotDeviceSpec{
+				AccountRef: thermopilotv1.AccountReference{Kind: KindSwitchBotAccount, Name: "home"},
+				DeviceID:   "C271111EC0AB",
+				DeviceType: "MeterPro",
+			},
+		}
+		Expect(k8sClient.Create(ctx, device)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, device))).To(Succeed())
+	})
+
+	It("returns the device ID for the account that mirrored the device", func() {
+		deviceID, err := ResolveDeviceID(ctx, k8sClient, thermoPilotWith(&thermopilotv1.AccountReference{Name: "home"}), device.Name)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deviceID).To(Equal("C271111EC0AB"))
+	})
+
+	It("rejects a device mirrored by another account", func() {
+		_, err := ResolveDeviceID(ctx, k8sClient, thermoPilotWith(&thermopilotv1.AccountReference{Name: "office"}), device.Name)
+		Expect(err).To(MatchError(ContainSubstring("belongs to SwitchBotAccount home, not to SwitchBotAccount office")))
+
+		_, err = ResolveDeviceID(ctx, k8sClient, thermoPilotWith(&thermopilotv1.AccountReference{
+			Kind: KindClusterSwitchBotAccount, Name: "home",
+		}), device.Name)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rejects device references without accountRef", func() {
+		_, err := ResolveDeviceID(ctx, k8sClient, thermoPilotWith(nil), device.Name)
+		Expect(err).To(MatchError(ContainSubstring("can only be referenced with accountRef")))
+	})
+
+	It("keeps the account and the device ID immutable", func() {
+		moved := device.DeepCopy()
+		moved.Spec.AccountRef.Name = "office"
+		Expect(k8sClient.Update(ctx, moved)).To(MatchError(ContainSubstring("accountRef is immutable")))
+
+		moved = device.DeepCopy()
+		moved.Spec.DeviceID = "D000000000AA"
+		Expect(k8sClient.Update(ctx, moved)).To(MatchError(ContainSubstring("deviceId is immutable")))
+
+		renamed := device.DeepCopy()
+		renamed.Spec.DeviceName = "Bedroom"
+		Expect(k8sClient.Update(ctx, renamed)).To(Succeed())
+	})
+})
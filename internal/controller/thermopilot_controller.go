@@ -21,31 +21,48 @@ import (
 	"fmt"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/meta"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
 	switchbotclient "github.com/seipan/thermo-pilot-controller/internal/client"
+	"github.com/seipan/thermo-pilot-controller/internal/planner"
 )
 
 // ThermoPilotReconciler reconciles a ThermoPilot object
 type ThermoPilotReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	// APIReader reads the objects the manager only caches as metadata
+	APIReader client.Reader
+	Scheme    *runtime.Scheme
+	Quota     *QuotaTracker
+	Recorder  record.EventRecorder
+	// ClientOptions configure every SwitchBot API client created by the reconciler
+	ClientOptions []switchbotclient.Option
+	// DryRun computes decisions for every ThermoPilot without sending commands
+	DryRun bool
+
+	calendars calendarCache
 }
 
 // +kubebuilder:rbac:groups=thermo-pilot.yadon3141.com,resources=thermopilots,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=thermo-pilot.yadon3141.com,resources=thermopilots/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=thermo-pilot.yadon3141.com,resources=thermopilots/finalizers,verbs=update
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
+// Conditions are computed from what the reconcile observed once it is over, see
+// setConditions.
 //
 //nolint:gocyclo // This function handles multiple cases and error scenarios, refactoring would reduce readability
 func (r *ThermoPilotReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -59,209 +76,312 @@ func (r *ThermoPilotReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, err
 	}
 
-	if thermoPilot.Status.Conditions == nil {
-		thermoPilot.Status.Conditions = []metav1.Condition{}
+	if !thermoPilot.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, &thermoPilot)
+	}
+	if err := r.ensureFinalizer(ctx, &thermoPilot); err != nil {
+		logger.Error(err, "failed to update finalizer")
+		return ctrl.Result{}, err
 	}
 
+	original := thermoPilot.DeepCopy()
+	now := time.Now()
+	var report reconcileReport
+
 	creds, err := GetSwitchBotCredentials(ctx, r.Client, thermoPilot.Spec, thermoPilot.Namespace)
 	if err != nil {
 		logger.Error(err, "failed to get SwitchBot credentials")
-		r.setCondition(&thermoPilot, "Available", metav1.ConditionFalse, "CredentialsError", err.Error())
-		if statusErr := r.Status().Update(ctx, &thermoPilot); statusErr != nil {
-			logger.Error(statusErr, "failed to update status")
-		}
-		return ctrl.Result{}, err
+		report.credentialsErr = err
+		return r.finish(ctx, &thermoPilot, original, report, now, err)
 	}
 
-	sbClient := switchbotclient.NewClient(creds.Token, creds.Secret)
+	sbClient := newSwitchBotClient(creds, r.Quota, r.ClientOptions)
 
-	// Get temperature sensor device
-	var sensorID string
-	switch thermoPilot.Spec.TemperatureSensorType {
-	case "MeterPro":
-		meterPro, err := sbClient.GetMeterPro(ctx)
-		if err != nil {
-			logger.Error(err, "failed to get MeterPro device")
-			r.setCondition(&thermoPilot, "Available", metav1.ConditionFalse, "TemperatureSensorNotFound", err.Error())
-			if statusErr := r.Status().Update(ctx, &thermoPilot); statusErr != nil {
-				logger.Error(statusErr, "failed to update status")
-			}
-			return ctrl.Result{}, err
-		}
-		sensorID = meterPro.DeviceID
-		logger.Info("found temperature sensor", "type", thermoPilot.Spec.TemperatureSensorType, "deviceId", sensorID, "name", meterPro.DeviceName)
-	default:
-		err := fmt.Errorf("unsupported temperature sensor type: %s", thermoPilot.Spec.TemperatureSensorType)
-		logger.Error(err, "invalid sensor type")
-		r.setCondition(&thermoPilot, "Available", metav1.ConditionFalse, "ConfigError", err.Error())
-		if statusErr := r.Status().Update(ctx, &thermoPilot); statusErr != nil {
-			logger.Error(statusErr, "failed to update status")
-		}
-		return ctrl.Result{}, err
+	// Read the temperature, falling back on the policy of the spec when the
+	// primary sensor cannot be read
+	reading, sensorReason, sensorErr := r.readSensors(ctx, &thermoPilot, sbClient)
+	previousSensor := thermoPilot.Status.Sensor
+	policy := thermoPilot.Spec.SensorFailurePolicy
+	if reading == nil && (policy == nil || sensorReason == ReasonConfigError) {
+		logger.Error(sensorErr, "failed to get current temperature")
+		thermoPilot.Status.Sensor = sensorStatus(previousSensor, planner.SourceNone, "", now)
+		report.sensorErr, report.sensorReason = sensorErr, sensorReason
+		return r.finish(ctx, &thermoPilot, original, report, now, sensorErr)
 	}
 
-	// Get current temperature from sensor
-	currentTemp, err := sbClient.GetNowTemperature(ctx, sensorID)
-	if err != nil {
-		logger.Error(err, "failed to get current temperature", "sensorId", sensorID)
-		r.setCondition(&thermoPilot, "Available", metav1.ConditionFalse, "TemperatureSensorError", err.Error())
-		if statusErr := r.Status().Update(ctx, &thermoPilot); statusErr != nil {
-			logger.Error(statusErr, "failed to update status")
-		}
-		return ctrl.Result{}, err
+	activeOverrideStatus := overrideStatus(&thermoPilot, now)
+	if thermoPilot.Status.ActiveOverride != nil && activeOverrideStatus == nil {
+		r.event(&thermoPilot, corev1.EventTypeNormal, "OverrideExpired", "Manual override expired, resuming normal control")
 	}
+	thermoPilot.Status.ActiveOverride = activeOverrideStatus
 
-	thermoPilot.Status.CurrentTemperature = FormatTemperature(currentTemp)
-
-	targetTemp, err := ParseTemperature(thermoPilot.Spec.TargetTemperature)
+	awaySwitches, err := r.awaySwitches(ctx, &thermoPilot)
 	if err != nil {
-		logger.Error(err, "failed to parse target temperature")
-		r.setCondition(&thermoPilot, "Available", metav1.ConditionFalse, "ConfigError", err.Error())
-		if statusErr := r.Status().Update(ctx, &thermoPilot); statusErr != nil {
-			logger.Error(statusErr, "failed to update status")
-		}
-		return ctrl.Result{}, err
+		logger.Error(err, "failed to get away switches")
+		report.configErr = err
+		return r.finish(ctx, &thermoPilot, original, report, now, err)
 	}
-	threshold := 1.0
-	if thermoPilot.Spec.Threshold != "" {
-		threshold, err = ParseTemperature(thermoPilot.Spec.Threshold)
-		if err != nil {
-			logger.Error(err, "failed to parse threshold")
-			threshold = 1.0
-		}
+	occupancyCalendar, err := r.occupancyCalendar(ctx, &thermoPilot)
+	if err != nil {
+		logger.Error(err, "failed to load the occupancy calendar")
+		report.configErr = err
+		return r.finish(ctx, &thermoPilot, original, report, now, err)
 	}
 
-	tempDiff := currentTemp - targetTemp
-	logger.Info("temperature status",
-		"current", currentTemp,
-		"target", targetTemp,
-		"difference", tempDiff,
-		"threshold", threshold)
-
-	needsAction := false
-	var action string
-	var mode switchbotclient.AirConditionerMode
-
-	switch thermoPilot.Spec.Mode {
-	case "cool":
-		if tempDiff > threshold {
-			needsAction = true
-			action = "cooling"
-			mode = switchbotclient.ModeCool
-		} else if tempDiff < -threshold {
-			needsAction = true
-			action = "adjusting up (too cold)"
-			mode = switchbotclient.ModeCool
+	input := planner.Input{
+		Now:          now,
+		Spec:         thermoPilot.Spec,
+		Previous:     thermoPilot.Status,
+		DryRun:       r.DryRun,
+		AwaySwitches: awaySwitches,
+		Calendar:     occupancyCalendar,
+	}
+	var plan planner.Plan
+	switch {
+	case reading != nil:
+		if sensorErr != nil {
+			logger.Error(sensorErr, "primary temperature sensor failed, using fallback", "deviceId", reading.deviceID)
+			report.sensorWarning, report.sensorReason = sensorErr, ReasonFallbackSensorActive
+		}
+		thermoPilot.Status.Sensor = sensorStatus(previousSensor, reading.source, reading.deviceID, now)
+		// the SwitchBot API reports Celsius, everything past this point is in the unit of the spec
+		reading.Temperature = planner.UnitOf(thermoPilot.Spec).FromCelsius(reading.Temperature)
+		r.checkSensorHealth(&thermoPilot, original.Status, reading, &report, now)
+		calibrated, offset, calibrationReason := planner.CalibrateReading(thermoPilot.Spec, reading.deviceID, reading.Temperature)
+		thermoPilot.Status.Sensor.ReportedTemperature = planner.FormatTemperature(reading.Temperature)
+		if offset != 0 {
+			thermoPilot.Status.Sensor.CalibrationOffset = planner.FormatOffset(offset)
+		}
+		if calibrationReason != "" {
+			input.Reasons = append(input.Reasons, calibrationReason)
 		}
-	case "heat":
-		if tempDiff < -threshold {
-			needsAction = true
-			action = "heating"
-			mode = switchbotclient.ModeHeat
-		} else if tempDiff > threshold {
-			needsAction = true
-			action = "adjusting down (too warm)"
-			mode = switchbotclient.ModeHeat
+		temperature, filterStatus, filterReason := planner.FilterReading(thermoPilot.Spec, thermoPilot.Status.Filter, calibrated)
+		thermoPilot.Status.Filter = filterStatus
+		if filterReason != "" {
+			input.Reasons = append(input.Reasons, filterReason)
 		}
+		thermoPilot.Status.CurrentTemperature = planner.FormatTemperature(temperature)
+		humidity := int32(reading.Humidity)
+		thermoPilot.Status.CurrentHumidity = &humidity
+		input.CurrentTemperature = temperature
+		plan, err = planner.Decide(input)
 	default:
-		err := fmt.Errorf("unsupported mode: %s", thermoPilot.Spec.Mode)
-		logger.Error(err, "invalid mode in spec")
-		r.setCondition(&thermoPilot, "Available", metav1.ConditionFalse, "ConfigError", err.Error())
-		if statusErr := r.Status().Update(ctx, &thermoPilot); statusErr != nil {
-			logger.Error(statusErr, "failed to update status")
+		var deviceID string
+		if previousSensor != nil {
+			deviceID = previousSensor.DeviceID
 		}
-		return ctrl.Result{}, err
+		thermoPilot.Status.Sensor = sensorStatus(previousSensor, planner.SourceHeld, deviceID, now)
+		input.Previous.Sensor = thermoPilot.Status.Sensor
+		if held, ok := planner.HeldReading(thermoPilot.Spec, thermoPilot.Status, thermoPilot.Status.Sensor.FailingSince.Time, now); ok {
+			logger.Error(sensorErr, "no temperature sensor could be read, holding the last reading", "staleness", thermoPilot.Status.Sensor.Staleness)
+			report.sensorWarning, report.sensorReason = sensorErr, ReasonSensorReadingHeld
+			input.CurrentTemperature = held
+			plan, err = planner.Decide(input)
+		} else {
+			logger.Error(sensorErr, "no temperature reading is available, applying the safe action", "staleness", thermoPilot.Status.Sensor.Staleness)
+			thermoPilot.Status.Sensor.Source = planner.SourceNone
+			input.Previous.Sensor = thermoPilot.Status.Sensor
+			report.sensorWarning, report.sensorReason = sensorErr, ReasonSensorStale
+			plan, err = planner.DecideStale(input)
+		}
+	}
+	if source := thermoPilot.Status.Sensor.Source; previousSensor == nil || previousSensor.Source != source {
+		switch {
+		case source == planner.SourceFallback:
+			r.event(&thermoPilot, corev1.EventTypeWarning, ReasonFallbackSensorActive,
+				fmt.Sprintf("Using fallback sensor %s: %v", thermoPilot.Status.Sensor.DeviceID, sensorErr))
+		case source == planner.SourceHeld:
+			r.event(&thermoPilot, corev1.EventTypeWarning, ReasonSensorReadingHeld,
+				fmt.Sprintf("No sensor could be read, holding the last reading %s: %v", thermoPilot.Status.CurrentTemperature, sensorErr))
+		case source == planner.SourceNone:
+			r.event(&thermoPilot, corev1.EventTypeWarning, ReasonSensorStale,
+				fmt.Sprintf("No temperature reading is available since %s: %v",
+					thermoPilot.Status.Sensor.FailingSince.UTC().Format(time.RFC3339), sensorErr))
+		case source == planner.SourcePrimary && previousSensor != nil:
+			r.event(&thermoPilot, corev1.EventTypeNormal, "SensorRecovered", "Reading the primary sensor again")
+		}
+	}
+	if err != nil {
+		logger.Error(err, "failed to compute decision")
+		report.configErr = err
+		return r.finish(ctx, &thermoPilot, original, report, now, err)
 	}
+	logger.Info("temperature status",
+		"current", plan.CurrentTemperature,
+		"target", plan.TargetTemperature,
+		"difference", plan.CurrentTemperature-plan.TargetTemperature,
+		"upperThreshold", plan.UpperThreshold,
+		"lowerThreshold", plan.LowerThreshold,
+		"action", plan.Action)
 
-	if needsAction {
-		logger.Info("controlling air conditioner", "action", action, "mode", mode)
-		r.setCondition(&thermoPilot, "Progressing", metav1.ConditionTrue, "ControllingAirConditioner", fmt.Sprintf("Performing action: %s", action))
+	if plan.NeedsAction() && !plan.Suspended {
+		// Get air conditioner IDs
+		airConditionerIDs, err := r.airConditionerIDs(ctx, &thermoPilot, sbClient)
+		if err != nil {
+			logger.Error(err, "failed to get air conditioners")
+			report.plan = &plan
+			report.actuatorErr, report.actuatorReason = err, ReasonAirConditionerListError
+			return r.finish(ctx, &thermoPilot, original, report, now, err)
+		}
+		plan = plan.WithDevices(airConditionerIDs)
+	}
+	plan.Record(&thermoPilot.Status)
+	report.plan = &plan
+	r.safetyEvents(&thermoPilot, original.Status.LastDecision, plan)
+	r.awayEvents(&thermoPilot, original.Status.Away)
+
+	if plan.NeedsAction() && plan.Suspended {
+		logger.Info("control is suspended, skipping action", "action", plan.Action)
+	}
 
-		adjustedTemp := targetTemp
-		switch action {
-		case "adjusting up (too cold)":
-			adjustedTemp = targetTemp + 3.0 // Raise by 3 degrees if too cold
-		case "adjusting down (too warm)":
-			adjustedTemp = targetTemp - 3.0 // Lower by 3 degrees if too warm
+	var actuateErr error
+	if plan.NeedsAction() && !plan.Suspended {
+		logger.Info("controlling air conditioner", "action", plan.Action, "mode", plan.Mode)
+		if plan.DryRun {
+			msg := fmt.Sprintf("Dry run: would set %v to %s (%s) for action: %s",
+				thermoPilot.Status.LastCommand.AirConditionerIDs, thermoPilot.Status.LastCommand.Setpoint, plan.Mode, plan.Action)
+			logger.Info("dry run, not sending commands", "commands", len(plan.Commands))
+			r.event(&thermoPilot, corev1.EventTypeNormal, "DryRun", msg)
 		}
 
-		// Get air conditioner IDs
-		var airConditionerIDs []string
-		if thermoPilot.Spec.AirConditionerID != "" {
-			airConditionerIDs = []string{thermoPilot.Spec.AirConditionerID}
-		} else {
-			// Get all air conditioners
-			airConditioners, err := sbClient.MultiGetAirConditioners(ctx)
-			if err != nil {
-				logger.Error(err, "failed to get air conditioners")
-				r.setCondition(&thermoPilot, "Degraded", metav1.ConditionTrue, "AirConditionerListError", err.Error())
-				if statusErr := r.Status().Update(ctx, &thermoPilot); statusErr != nil {
-					logger.Error(statusErr, "failed to update status")
+		if plan.Actuate() {
+			errorMsg, failed := r.actuate(ctx, sbClient, plan)
+			if failed < len(plan.Commands) {
+				target := fmt.Sprintf("%s (%s)", thermoPilot.Status.LastCommand.Setpoint, plan.Mode)
+				if plan.Power == planner.PowerOff {
+					target = planner.PowerOff
 				}
-				return ctrl.Result{}, err
+				r.event(&thermoPilot, corev1.EventTypeNormal, "CommandSent",
+					fmt.Sprintf("Set %d/%d air conditioners to %s for action %s at %s",
+						len(plan.Commands)-failed, len(plan.Commands), target, plan.Action, thermoPilot.Status.CurrentTemperature))
 			}
-			for _, ac := range airConditioners {
-				airConditionerIDs = append(airConditionerIDs, ac.DeviceID)
+			if failed > 0 {
+				r.event(&thermoPilot, corev1.EventTypeWarning, "CommandFailed", errorMsg)
+				report.actuatorErr, report.actuatorReason = fmt.Errorf("%s", errorMsg), ReasonAirConditionerControlError
+				// A partial failure is reported in status but not retried
+				// immediately, as that would resend to the healthy devices too.
+				if failed == len(plan.Commands) {
+					actuateErr = report.actuatorErr
+				}
 			}
-			logger.Info("found air conditioners", "count", len(airConditionerIDs), "ids", airConditionerIDs)
-		}
-
-		// Control all air conditioners
-		var controlErrors []string
-		for _, deviceID := range airConditionerIDs {
-			err = sbClient.SetTemperature(ctx, deviceID, adjustedTemp, mode)
-			if err != nil {
-				logger.Error(err, "failed to control air conditioner", "deviceId", deviceID)
-				controlErrors = append(controlErrors, fmt.Sprintf("%s: %v", deviceID, err))
-			} else {
-				logger.Info("successfully controlled air conditioner", "deviceId", deviceID, "action", action)
+			if failed == len(plan.Commands) {
+				// Nothing was sent, so the compressor did not cycle and the
+				// commands are still due.
+				planner.RecordUnsent(&thermoPilot.Status, &original.Status)
 			}
 		}
+	}
 
-		if len(controlErrors) > 0 {
-			errorMsg := fmt.Sprintf("failed to control %d/%d air conditioners: %v", len(controlErrors), len(airConditionerIDs), controlErrors)
-			r.setCondition(&thermoPilot, "Degraded", metav1.ConditionTrue, "AirConditionerControlError", errorMsg)
-			if statusErr := r.Status().Update(ctx, &thermoPilot); statusErr != nil {
-				logger.Error(statusErr, "failed to update status")
-			}
-			if len(controlErrors) == len(airConditionerIDs) {
-				return ctrl.Result{}, fmt.Errorf("%s", errorMsg)
+	return r.finish(ctx, &thermoPilot, original, report, now, actuateErr)
+}
+
+// safetyEvents records an Event when plan starts enforcing a safety limit that
+// previous did not, and when the room is back within the limits.
+func (r *ThermoPilotReconciler) safetyEvents(thermoPilot *thermopilotv1.ThermoPilot, previous *thermopilotv1.Decision, plan planner.Plan) {
+	var previousAction string
+	if previous != nil {
+		previousAction = previous.Action
+	}
+	switch {
+	case plan.SafetyLimit && plan.Action != previousAction:
+		r.event(thermoPilot, corev1.EventTypeWarning, safetyReason(plan.Action),
+			plan.Unit.Sprintf("Room at %.1f°C is outside the safety limits, %s to %.1f°C in %s mode regardless of mode, override and suspend",
+				plan.CurrentTemperature, plan.Action, plan.Setpoint, plan.Mode))
+	case !plan.SafetyLimit && !plan.Stale && planner.IsSafetyAction(previousAction):
+		r.event(thermoPilot, corev1.EventTypeNormal, "SafetyLimitCleared",
+			plan.Unit.Sprintf("Room at %.1f°C is back within the safety limits, resuming normal control", plan.CurrentTemperature))
+	}
+}
+
+// finish records the conditions computed from report, patches the status when it
+// differs from original and returns err, or schedules the next reconcile when err
+// is nil.
+func (r *ThermoPilotReconciler) finish(ctx context.Context, thermoPilot, original *thermopilotv1.ThermoPilot, report reconcileReport, now time.Time, err error) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	setConditions(thermoPilot, report)
+	if thermoPilotStatusChanged(&original.Status, &thermoPilot.Status) {
+		if statusErr := patchStatus(ctx, r.Client, thermoPilot, original); statusErr != nil {
+			logger.Error(statusErr, "failed to update status")
+			if err == nil {
+				err = statusErr
 			}
 		}
-		logger.Info("air conditioner control completed", "total", len(airConditionerIDs), "errors", len(controlErrors))
 	}
-
-	if needsAction {
-		r.setCondition(&thermoPilot, "Progressing", metav1.ConditionTrue, "TemperatureAdjusting",
-			fmt.Sprintf("Adjusting temperature: current=%.1f, target=%.1f", currentTemp, targetTemp))
-	} else {
-		r.setCondition(&thermoPilot, "Progressing", metav1.ConditionFalse, "TemperatureStable",
-			fmt.Sprintf("Temperature is within threshold: current=%.1f, target=%.1f", currentTemp, targetTemp))
+	if err != nil {
+		return ctrl.Result{}, err
 	}
+	return ctrl.Result{RequeueAfter: requeueAfter(thermoPilot, now)}, nil
+}
 
-	r.setCondition(&thermoPilot, "Available", metav1.ConditionTrue, "Reconciling", "ThermoPilot is functioning normally")
-	r.setCondition(&thermoPilot, "Degraded", metav1.ConditionFalse, "Healthy", "No errors detected")
+// actuate sends the planned commands and returns an error summary together with
+// the number of air conditioners that could not be controlled.
+func (r *ThermoPilotReconciler) actuate(ctx context.Context, sbClient *switchbotclient.Client, plan planner.Plan) (string, int) {
+	logger := log.FromContext(ctx)
+	var controlErrors []string
+	for _, command := range plan.Commands {
+		if err := sendCommand(ctx, sbClient, plan.Unit, command); err != nil {
+			logger.Error(err, "failed to control air conditioner", "deviceId", command.DeviceID)
+			controlErrors = append(controlErrors, fmt.Sprintf("%s: %v", command.DeviceID, err))
+		} else {
+			logger.Info("successfully controlled air conditioner", "deviceId", command.DeviceID, "action", plan.Action)
+		}
+	}
+	logger.Info("air conditioner control completed", "total", len(plan.Commands), "errors", len(controlErrors))
+	if len(controlErrors) == 0 {
+		return "", 0
+	}
+	return fmt.Sprintf("failed to control %d/%d air conditioners: %v", len(controlErrors), len(plan.Commands), controlErrors), len(controlErrors)
+}
 
-	if err := r.Status().Update(ctx, &thermoPilot); err != nil {
-		logger.Error(err, "failed to update status")
-		return ctrl.Result{}, err
+// airConditionerIDs returns the IDs of the air conditioners controlled by thermoPilot.
+// When neither airConditionerRef nor airConditionerId is set, every air conditioner
+// in the account is controlled.
+func (r *ThermoPilotReconciler) airConditionerIDs(ctx context.Context, thermoPilot *thermopilotv1.ThermoPilot, sbClient *switchbotclient.Client) ([]string, error) {
+	logger := log.FromContext(ctx)
+	if thermoPilot.Spec.AirConditionerRef != "" {
+		deviceID, err := ResolveDeviceID(ctx, r.Client, thermoPilot, thermoPilot.Spec.AirConditionerRef)
+		if err != nil {
+			return nil, err
+		}
+		return []string{deviceID}, nil
+	}
+	if thermoPilot.Spec.AirConditionerID != "" {
+		return []string{thermoPilot.Spec.AirConditionerID}, nil
 	}
-	return ctrl.Result{RequeueAfter: 5 * time.Minute}, nil
+	airConditioners, err := sbClient.MultiGetAirConditioners(ctx)
+	if err != nil {
+		return nil, err
+	}
+	airConditionerIDs := make([]string, 0, len(airConditioners))
+	for _, ac := range airConditioners {
+		airConditionerIDs = append(airConditionerIDs, ac.DeviceID)
+	}
+	logger.Info("found air conditioners", "count", len(airConditionerIDs), "ids", airConditionerIDs)
+	return airConditionerIDs, nil
 }
 
-func (r *ThermoPilotReconciler) setCondition(thermoPilot *thermopilotv1.ThermoPilot, conditionType string, status metav1.ConditionStatus, reason, message string) {
-	meta.SetStatusCondition(&thermoPilot.Status.Conditions, metav1.Condition{
-		Type:               conditionType,
-		Status:             status,
-		Reason:             reason,
-		Message:            message,
-		ObservedGeneration: thermoPilot.Generation,
-	})
+func (r *ThermoPilotReconciler) event(thermoPilot *thermopilotv1.ThermoPilot, eventType, reason, message string) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Event(thermoPilot, eventType, reason, message)
 }
 
 func (r *ThermoPilotReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &thermopilotv1.ThermoPilot{},
+		calendarRefIndex, indexCalendarRef); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&thermopilotv1.ThermoPilot{}).
+		// Status writes do not bump the generation, so the controller is not
+		// triggered by its own status patches.
+		For(&thermopilotv1.ThermoPilot{}, builder.WithPredicates(
+			predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}, predicate.LabelChangedPredicate{}),
+		)).
+		Watches(&thermopilotv1.ClusterAwaySwitch{}, handler.EnqueueRequestsFromMapFunc(r.thermoPilotsForAwaySwitch)).
+		// Calendars are read when reconciling, so only the metadata of ConfigMaps is
+		// cached to notice changes.
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.thermoPilotsForConfigMap), builder.OnlyMetadata).
 		Named("thermopilot").
 		Complete(r)
 }
@@ -114,4 +114,98 @@ var _ = Describe("ThermoPilot Controller", func() {
 			Expect(result.RequeueAfter).To(Equal(time.Duration(0)))
 		})
 	})
+
+	Context("When an onDelete policy is configured", func() {
+		const resourceName = "test-ondelete"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			By("creating the custom resource with an onDelete policy")
+			resource := &thermopilotv1.ThermoPilot{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: thermopilotv1.ThermoPilotSpec{
+					SecretRef: thermopilotv1.SecretReference{
+						Name: "missing-secret",
+					},
+					TemperatureSensorType: "MeterPro",
+					TargetTemperature:     "25.0",
+					Mode:                  "cool",
+					OnDelete: &thermopilotv1.OnDeletePolicy{
+						Action:  OnDeleteOff,
+						Timeout: &metav1.Duration{Duration: time.Second},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		It("should add the finalizer and remove it on deletion", func() {
+			controllerReconciler := &ThermoPilotReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+
+			By("Reconciling the created resource")
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).To(HaveOccurred())
+
+			resource := &thermopilotv1.ThermoPilot{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Finalizers).To(ContainElement(thermoPilotFinalizer))
+
+			By("Deleting the resource while the API is unreachable")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Get(ctx, typeNamespacedName, resource)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Context("When capability profiles are validated", func() {
+		ctx := context.Background()
+
+		fahrenheitWithProfile := func(name string, cool *thermopilotv1.SetpointRange) *thermopilotv1.ThermoPilot {
+			return &thermopilotv1.ThermoPilot{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "default",
+				},
+				Spec: thermopilotv1.ThermoPilotSpec{
+					SecretRef: thermopilotv1.SecretReference{
+						Name: "test-secret",
+					},
+					TemperatureSensorType: "MeterPro",
+					TargetTemperature:     "77.0",
+					Mode:                  "cool",
+					Unit:                  "fahrenheit",
+					CapabilityProfiles: []thermopilotv1.CapabilityProfile{{
+						Name: "bedroom",
+						Cool: cool,
+					}},
+				},
+			}
+		}
+
+		It("should accept Celsius ranges in a Fahrenheit spec", func() {
+			resource := fahrenheitWithProfile("test-profile-celsius", &thermopilotv1.SetpointRange{Min: "16", Max: "30"})
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should reject Fahrenheit ranges", func() {
+			resource := fahrenheitWithProfile("test-profile-fahrenheit", &thermopilotv1.SetpointRange{Min: "61", Max: "86"})
+			Expect(k8sClient.Create(ctx, resource)).To(MatchError(ContainSubstring("setpoint range must be between 1 and 39 in celsius")))
+		})
+	})
 })
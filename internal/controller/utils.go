@@ -1,18 +1,52 @@
 package controller
 
 import (
+	"context"
 	"fmt"
-	"strconv"
+
+	switchbotclient "github.com/seipan/thermo-pilot-controller/internal/client"
+	"github.com/seipan/thermo-pilot-controller/internal/planner"
 )
 
-func ParseTemperature(temp string) (float64, error) {
-	value, err := strconv.ParseFloat(temp, 64)
-	if err != nil {
-		return 0, fmt.Errorf("invalid temperature format: %s", temp)
+func parseMode(mode string) (switchbotclient.AirConditionerMode, error) {
+	switch mode {
+	case "cool":
+		return switchbotclient.ModeCool, nil
+	case "heat":
+		return switchbotclient.ModeHeat, nil
+	default:
+		return 0, fmt.Errorf("unsupported mode: %s", mode)
+	}
+}
+
+func parseFanSpeed(speed string) (switchbotclient.FanSpeed, error) {
+	switch speed {
+	case "", planner.FanAuto:
+		return switchbotclient.FanAuto, nil
+	case planner.FanLow:
+		return switchbotclient.FanLow, nil
+	case planner.FanMedium:
+		return switchbotclient.FanMedium, nil
+	case planner.FanHigh:
+		return switchbotclient.FanHigh, nil
+	default:
+		return 0, fmt.Errorf("unsupported fan speed: %s", speed)
 	}
-	return value, nil
 }
 
-func FormatTemperature(temp float64) string {
-	return fmt.Sprintf("%.1f", temp)
+// sendCommand sends a planned command to its air conditioner, converting its
+// setpoint from unit to the Celsius the SwitchBot API expects.
+func sendCommand(ctx context.Context, sbClient *switchbotclient.Client, unit planner.Unit, command planner.Command) error {
+	if command.Power == planner.PowerOff {
+		return sbClient.TurnOff(ctx, command.DeviceID)
+	}
+	mode, err := parseMode(command.Mode)
+	if err != nil {
+		return err
+	}
+	fan, err := parseFanSpeed(command.FanSpeed)
+	if err != nil {
+		return err
+	}
+	return sbClient.SetAll(ctx, command.DeviceID, unit.ToCelsius(command.Setpoint), mode, fan)
 }
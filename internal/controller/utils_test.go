@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	switchbotclient "github.com/seipan/thermo-pilot-controller/internal/client"
+	"github.com/seipan/thermo-pilot-controller/internal/planner"
+)
+
+var _ = Describe("sendCommand", func() {
+	var (
+		server     *httptest.Server
+		sbClient   *switchbotclient.Client
+		parameters []string
+	)
+
+	BeforeEach(func() {
+		parameters = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var payload struct {
+				Parameter string `json:"parameter"`
+			}
+			Expect(json.NewDecoder(r.Body).Decode(&payload)).To(Succeed())
+			parameters = append(parameters, payload.Parameter)
+			_, _ = w.Write([]byte(`{"statusCode":100,"body":{},"message":"success"}`))
+		}))
+		DeferCleanup(server.Close)
+		sbClient = switchbotclient.NewClient("test-token", "test-secret",
+			switchbotclient.WithBaseURL(server.URL+"/v1.1"), switchbotclient.WithTransport(server.Client().Transport))
+	})
+
+	DescribeTable("sends the setpoint fitted in Celsius",
+		func(unit planner.Unit, setpoint float64, want string) {
+			plan := planner.Plan{Action: planner.ActionCooling, Mode: planner.ModeCool, Power: planner.PowerOn,
+				Setpoint: setpoint, Unit: unit}.WithDevices([]string{"ac1"})
+			Expect(plan.Commands).To(HaveLen(1))
+
+			Expect(sendCommand(context.Background(), sbClient, unit, plan.Commands[0])).To(Succeed())
+			Expect(parameters).To(Equal([]string{want}))
+		},
+		Entry("in Celsius", planner.Celsius, 24.0, "24,2,1,on"),
+		Entry("75°F is 23.9°C, sent in whole degrees", planner.Fahrenheit, 75.0, "24,2,1,on"),
+		Entry("above the range in Fahrenheit", planner.Fahrenheit, 90.0, "30,2,1,on"),
+	)
+})
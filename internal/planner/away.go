@@ -0,0 +1,176 @@
+package planner
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+const (
+	ActionAwayOff    = "away (off)"
+	ActionAwayResume = "resuming (away ended)"
+)
+
+// Away is an away period in force.
+type Away struct {
+	// Source is spec.away or the ClusterAwaySwitch/<name> the period comes from
+	Source string
+	Start  time.Time
+	// End is when normal control resumes, zero until the switch is removed
+	End time.Time
+	Off bool
+	// TargetTemperature is in the unit of the ThermoPilot; it and Mode are unset when Off
+	TargetTemperature float64
+	Mode              string
+}
+
+// ActiveAway returns the away period in force at now, the periods of spec taking
+// precedence over switches, together with when an away period starts or ends next.
+// switches are the ClusterAwaySwitches selecting the ThermoPilot; their target is
+// converted to the unit of spec.
+func ActiveAway(spec thermopilotv1.ThermoPilotSpec, switches []thermopilotv1.ClusterAwaySwitch, now time.Time) (*Away, time.Time, error) {
+	var active *Away
+	var next time.Time
+	consider := func(source string, start, end time.Time, settings thermopilotv1.AwaySettings, from Unit) error {
+		if start.After(now) {
+			next = earliest(next, start)
+			return nil
+		}
+		if !end.IsZero() && !now.Before(end) {
+			return nil
+		}
+		next = earliest(next, end)
+		if active != nil {
+			return nil
+		}
+		away := &Away{Source: source, Start: start, End: end, Off: settings.Off, Mode: settings.Mode}
+		if !settings.Off {
+			if away.Mode == "" {
+				away.Mode = spec.Mode
+			}
+			target, err := ParseTemperature(settings.TargetTemperature)
+			if err != nil {
+				return fmt.Errorf("invalid away target of %s: %w", source, err)
+			}
+			away.TargetTemperature = UnitOf(spec).FromCelsius(from.ToCelsius(target))
+		}
+		active = away
+		return nil
+	}
+
+	for _, period := range spec.Away {
+		if err := consider("spec.away", period.Start.Time, period.End.Time, period.AwaySettings, UnitOf(spec)); err != nil {
+			return nil, time.Time{}, err
+		}
+	}
+	for _, awaySwitch := range switches {
+		start := awaySwitch.CreationTimestamp.Time
+		if awaySwitch.Spec.Start != nil {
+			start = awaySwitch.Spec.Start.Time
+		}
+		var end time.Time
+		if awaySwitch.Spec.End != nil {
+			end = awaySwitch.Spec.End.Time
+		}
+		from := Celsius
+		if awaySwitch.Spec.Unit == string(Fahrenheit) {
+			from = Fahrenheit
+		}
+		if err := consider("ClusterAwaySwitch/"+awaySwitch.Name, start, end, awaySwitch.Spec.AwaySettings, from); err != nil {
+			return nil, time.Time{}, err
+		}
+	}
+	return active, next, nil
+}
+
+// decideAwayOff returns the plan turning the air conditioners off for the away
+// period of target, sent once per period.
+func decideAwayOff(in Input, target Target, stale bool) Plan {
+	away := target.Away
+	plan := Plan{
+		Time:               in.Now,
+		CurrentTemperature: in.CurrentTemperature,
+		TargetTemperature:  target.Temperature,
+		UpperThreshold:     defaultThreshold,
+		LowerThreshold:     defaultThreshold,
+		Action:             ActionAwayOff,
+		Power:              PowerOff,
+		Suspended:          in.Spec.Suspend,
+		DryRun:             in.DryRun || in.Spec.DryRun,
+		Stale:              stale,
+		Target:             &target,
+		Unit:               UnitOf(in.Spec),
+		Profiles:           in.Spec.CapabilityProfiles,
+		Offsets:            airConditionerOffsets(in.Spec),
+		Reasons:            append(append([]string(nil), in.Reasons...), target.Reasons...),
+	}
+	if stale {
+		plan.CurrentTemperature = 0
+		plan.reason("no temperature reading is available")
+	}
+
+	if applied := in.Previous.LastCommand; applied != nil && applied.Action == ActionAwayOff && !applied.DryRun &&
+		!applied.Time.Time.Before(away.Start) {
+		plan.Action = ActionNone
+		plan.reason("air conditioners turned off for the away period at %s", applied.Time.UTC().Format(time.RFC3339))
+		plan.trackEquipment(in.Spec, in.Previous.Equipment)
+		return plan
+	}
+	plan.reason("%s: turning the air conditioners off", plan.Action)
+	plan.protectEquipment(in.Spec, in.Previous.Equipment)
+	plan.trackEquipment(in.Spec, in.Previous.Equipment)
+	if plan.Suspended {
+		plan.reason("control is suspended, no command is sent")
+	}
+	if plan.DryRun {
+		plan.reason("dry run, commands are recorded but not sent")
+	}
+	return plan.WithDevices(in.AirConditionerIDs)
+}
+
+// resumeAfterAwayOff turns the air conditioners back on at the setpoint of the plan
+// when the last command turned them off for an away period, which has since ended,
+// and the room is close enough to the target for the band to leave them as they are.
+func (p *Plan) resumeAfterAwayOff(previous *thermopilotv1.CommandStatus) {
+	if p.Action != ActionNone || previous == nil || previous.Action != ActionAwayOff || previous.DryRun {
+		return
+	}
+	p.Action = ActionAwayResume
+	p.reason("away period ended, turning the air conditioners back on")
+}
+
+// AwayOff reports whether the plan keeps the air conditioners off for an away period.
+func (p Plan) AwayOff() bool {
+	return p.Target != nil && p.Target.Away != nil && p.Target.Away.Off && !p.SafetyLimit
+}
+
+// status returns the away period to record in status, nil when there is none.
+func (a *Away) status() *thermopilotv1.AwayStatus {
+	if a == nil {
+		return nil
+	}
+	status := &thermopilotv1.AwayStatus{Source: a.Source, Off: a.Off, Mode: a.Mode}
+	if !a.Start.IsZero() {
+		start := metav1.NewTime(a.Start)
+		status.Start = &start
+	}
+	if !a.End.IsZero() {
+		end := metav1.NewTime(a.End)
+		status.End = &end
+	}
+	if !a.Off {
+		status.TargetTemperature = FormatTemperature(a.TargetTemperature)
+	}
+	return status
+}
+
+// earliest returns the earliest of a and b, ignoring zero times.
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,197 @@
+package planner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+func TestActiveAway(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	period := func(start, end time.Duration, settings thermopilotv1.AwaySettings) thermopilotv1.AwayPeriod {
+		return thermopilotv1.AwayPeriod{Start: metav1.NewTime(now.Add(start)), End: metav1.NewTime(now.Add(end)), AwaySettings: settings}
+	}
+	awaySwitch := func(name, unit string, settings thermopilotv1.AwaySettings) thermopilotv1.ClusterAwaySwitch {
+		return thermopilotv1.ClusterAwaySwitch{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))},
+			Spec:       thermopilotv1.ClusterAwaySwitchSpec{Unit: unit, AwaySettings: settings},
+		}
+	}
+	tests := []struct {
+		name     string
+		spec     thermopilotv1.ThermoPilotSpec
+		switches []thermopilotv1.ClusterAwaySwitch
+		want     *Away
+		wantNext time.Time
+	}{
+		{
+			name:     "upcoming period",
+			spec:     thermopilotv1.ThermoPilotSpec{Mode: ModeCool, Away: []thermopilotv1.AwayPeriod{period(time.Hour, 2*time.Hour, thermopilotv1.AwaySettings{Off: true})}},
+			wantNext: now.Add(time.Hour),
+		},
+		{
+			name: "period in force",
+			spec: thermopilotv1.ThermoPilotSpec{Mode: ModeCool, Away: []thermopilotv1.AwayPeriod{
+				period(-time.Hour, time.Hour, thermopilotv1.AwaySettings{TargetTemperature: "28.0"}),
+			}},
+			want:     &Away{Source: "spec.away", Start: now.Add(-time.Hour), End: now.Add(time.Hour), TargetTemperature: 28.0, Mode: ModeCool},
+			wantNext: now.Add(time.Hour),
+		},
+		{
+			name: "ended period",
+			spec: thermopilotv1.ThermoPilotSpec{Mode: ModeCool, Away: []thermopilotv1.AwayPeriod{period(-2*time.Hour, -time.Hour, thermopilotv1.AwaySettings{Off: true})}},
+		},
+		{
+			name: "spec takes precedence over switches",
+			spec: thermopilotv1.ThermoPilotSpec{Mode: ModeCool, Away: []thermopilotv1.AwayPeriod{
+				period(-time.Hour, 3*time.Hour, thermopilotv1.AwaySettings{TargetTemperature: "28.0"}),
+			}},
+			switches: []thermopilotv1.ClusterAwaySwitch{awaySwitch("vacation", "", thermopilotv1.AwaySettings{Off: true})},
+			want:     &Away{Source: "spec.away", Start: now.Add(-time.Hour), End: now.Add(3 * time.Hour), TargetTemperature: 28.0, Mode: ModeCool},
+			wantNext: now.Add(3 * time.Hour),
+		},
+		{
+			name:     "switch starts when created",
+			spec:     thermopilotv1.ThermoPilotSpec{Mode: ModeCool},
+			switches: []thermopilotv1.ClusterAwaySwitch{awaySwitch("vacation", "", thermopilotv1.AwaySettings{Off: true})},
+			want:     &Away{Source: "ClusterAwaySwitch/vacation", Start: now.Add(-time.Hour), Off: true},
+		},
+		{
+			name:     "switch target converted to the unit of the ThermoPilot",
+			spec:     thermopilotv1.ThermoPilotSpec{Mode: ModeHeat, Unit: string(Fahrenheit)},
+			switches: []thermopilotv1.ClusterAwaySwitch{awaySwitch("frost", "celsius", thermopilotv1.AwaySettings{TargetTemperature: "10.0"})},
+			want:     &Away{Source: "ClusterAwaySwitch/frost", Start: now.Add(-time.Hour), TargetTemperature: 50.0, Mode: ModeHeat},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, next, err := ActiveAway(tt.spec, tt.switches, now)
+			require.NoError(t, err)
+			if tt.want == nil {
+				assert.Nil(t, got)
+			} else {
+				require.NotNil(t, got)
+				assert.InDelta(t, tt.want.TargetTemperature, got.TargetTemperature, 1e-9)
+				got.TargetTemperature = tt.want.TargetTemperature
+				assert.Equal(t, tt.want, got)
+			}
+			assert.True(t, tt.wantNext.Equal(next), "next %s", next)
+		})
+	}
+}
+
+func TestDecide_Away(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	start := now.Add(-time.Hour)
+	spec := thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", Threshold: "1.0", Mode: ModeCool, Away: []thermopilotv1.AwayPeriod{{
+		Start: metav1.NewTime(start), End: metav1.NewTime(now.Add(time.Hour)), AwaySettings: thermopilotv1.AwaySettings{Off: true},
+	}}}
+
+	t.Run("turns the air conditioners off once", func(t *testing.T) {
+		plan, err := Decide(Input{Now: now, CurrentTemperature: 29.0, Spec: spec, AirConditionerIDs: []string{"ac1"}})
+		require.NoError(t, err)
+		assert.Equal(t, ActionAwayOff, plan.Action)
+		assert.True(t, plan.AwayOff())
+		require.Len(t, plan.Commands, 1)
+		assert.Equal(t, PowerOff, plan.Commands[0].Power)
+
+		var status thermopilotv1.ThermoPilotStatus
+		plan.Record(&status)
+		require.NotNil(t, status.Away)
+		assert.Equal(t, "spec.away", status.Away.Source)
+		assert.True(t, status.Away.Off)
+
+		plan, err = Decide(Input{Now: now.Add(time.Minute), CurrentTemperature: 29.0, Spec: spec, Previous: status, AirConditionerIDs: []string{"ac1"}})
+		require.NoError(t, err)
+		assert.Equal(t, ActionNone, plan.Action)
+		assert.Empty(t, plan.Commands)
+	})
+
+	t.Run("turns them off again when nothing was sent", func(t *testing.T) {
+		plan, err := Decide(Input{Now: now, CurrentTemperature: 29.0, Spec: spec, AirConditionerIDs: []string{"ac1"}})
+		require.NoError(t, err)
+		require.Equal(t, ActionAwayOff, plan.Action)
+
+		var previous, status thermopilotv1.ThermoPilotStatus
+		plan.Record(&status)
+		RecordUnsent(&status, &previous)
+
+		plan, err = Decide(Input{Now: now.Add(time.Minute), CurrentTemperature: 29.0, Spec: spec, Previous: status, AirConditionerIDs: []string{"ac1"}})
+		require.NoError(t, err)
+		assert.Equal(t, ActionAwayOff, plan.Action)
+		require.Len(t, plan.Commands, 1)
+		assert.Equal(t, PowerOff, plan.Commands[0].Power)
+	})
+
+	t.Run("an earlier away command is not reused", func(t *testing.T) {
+		previous := thermopilotv1.ThermoPilotStatus{LastCommand: &thermopilotv1.CommandStatus{
+			Action: ActionAwayOff, Time: metav1.NewTime(start.Add(-24 * time.Hour)),
+		}}
+		plan, err := Decide(Input{Now: now, CurrentTemperature: 29.0, Spec: spec, Previous: previous})
+		require.NoError(t, err)
+		assert.Equal(t, ActionAwayOff, plan.Action)
+	})
+
+	t.Run("turns them back on once the period ends", func(t *testing.T) {
+		plan, err := Decide(Input{Now: now, CurrentTemperature: 25.5, Spec: spec, AirConditionerIDs: []string{"ac1"}})
+		require.NoError(t, err)
+		require.Equal(t, ActionAwayOff, plan.Action)
+		var status thermopilotv1.ThermoPilotStatus
+		plan.Record(&status)
+
+		after := now.Add(2 * time.Hour)
+		plan, err = Decide(Input{Now: after, CurrentTemperature: 25.5, Spec: spec, Previous: status, AirConditionerIDs: []string{"ac1"}})
+		require.NoError(t, err)
+		assert.Equal(t, ActionAwayResume, plan.Action)
+		assert.Equal(t, []Command{{DeviceID: "ac1", Setpoint: 25, Mode: ModeCool, Power: PowerOn, FanSpeed: FanAuto}}, plan.Commands)
+		plan.Record(&status)
+
+		plan, err = Decide(Input{Now: after.Add(time.Minute), CurrentTemperature: 25.5, Spec: spec, Previous: status, AirConditionerIDs: []string{"ac1"}})
+		require.NoError(t, err)
+		assert.Equal(t, ActionNone, plan.Action, "the resume is sent once")
+	})
+
+	t.Run("the band takes over when the room drifted", func(t *testing.T) {
+		previous := thermopilotv1.ThermoPilotStatus{LastCommand: &thermopilotv1.CommandStatus{
+			Action: ActionAwayOff, Time: metav1.NewTime(start),
+		}}
+		plan, err := Decide(Input{Now: now.Add(2 * time.Hour), CurrentTemperature: 29.0, Spec: spec, Previous: previous})
+		require.NoError(t, err)
+		assert.Equal(t, ActionCooling, plan.Action)
+	})
+
+	t.Run("override takes precedence", func(t *testing.T) {
+		spec := *spec.DeepCopy()
+		spec.Override = &thermopilotv1.Override{TargetTemperature: "24.0", ExpiresAt: metav1.NewTime(now.Add(time.Hour))}
+		plan, err := Decide(Input{Now: now, CurrentTemperature: 29.0, Spec: spec})
+		require.NoError(t, err)
+		assert.Equal(t, ActionCooling, plan.Action)
+		assert.InDelta(t, 24.0, plan.TargetTemperature, 1e-9)
+		assert.False(t, plan.AwayOff())
+	})
+
+	t.Run("safety limits still apply", func(t *testing.T) {
+		spec := *spec.DeepCopy()
+		spec.Safety = &thermopilotv1.SafetyLimits{MaxTemperature: "28.0"}
+		plan, err := Decide(Input{Now: now, CurrentTemperature: 29.0, Spec: spec})
+		require.NoError(t, err)
+		assert.Equal(t, ActionOverheatGuard, plan.Action)
+		assert.False(t, plan.AwayOff())
+	})
+
+	t.Run("away target", func(t *testing.T) {
+		spec := *spec.DeepCopy()
+		spec.Away[0].AwaySettings = thermopilotv1.AwaySettings{TargetTemperature: "28.0"}
+		plan, err := Decide(Input{Now: now, CurrentTemperature: 29.5, Spec: spec})
+		require.NoError(t, err)
+		assert.Equal(t, ActionCooling, plan.Action)
+		assert.InDelta(t, 28.0, plan.TargetTemperature, 1e-9)
+		require.NotNil(t, plan.Target)
+		assert.Equal(t, now.Add(time.Hour), plan.Target.NextChange)
+	})
+}
@@ -0,0 +1,77 @@
+package planner
+
+import (
+	"errors"
+	"fmt"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+// Band is the comfort band around the target and how far beyond the target the
+// setpoint is driven when the room overshoots it.
+type Band struct {
+	// Upper and Lower are how far above and below the target the room may drift
+	Upper float64
+	Lower float64
+	// CoolOffset is added to the target in cool mode when the room is too cold, and
+	// HeatOffset subtracted from it in heat mode when the room is too warm
+	CoolOffset float64
+	HeatOffset float64
+}
+
+// ParseBand returns the band configured in spec. The upper and lower thresholds
+// default to the threshold and the offsets to 3 degrees. An offset that is set must be
+// at least the threshold on the side it corrects. An invalid legacy threshold
+// falls back on the default and is explained in the returned reasons; any other
+// invalid or inconsistent value is an error.
+func ParseBand(spec thermopilotv1.ThermoPilotSpec) (Band, []string, error) {
+	var reasons []string
+	threshold := defaultThreshold
+	if spec.Threshold != "" {
+		if parsed, err := ParseTemperature(spec.Threshold); err != nil {
+			reasons = append(reasons, UnitOf(spec).Sprintf("invalid threshold %q, using %.1f°C", spec.Threshold, defaultThreshold))
+		} else {
+			threshold = parsed
+		}
+	}
+	band := Band{Upper: threshold, Lower: threshold, CoolOffset: defaultAggressiveOffset, HeatOffset: defaultAggressiveOffset}
+
+	parse := func(value, name string, into *float64) error {
+		if value == "" {
+			return nil
+		}
+		parsed, err := ParseTemperature(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+		if parsed <= 0 {
+			return fmt.Errorf("%s must be greater than 0, got %s", name, value)
+		}
+		*into = parsed
+		return nil
+	}
+	if err := parse(spec.UpperThreshold, "upper threshold", &band.Upper); err != nil {
+		return Band{}, nil, err
+	}
+	if err := parse(spec.LowerThreshold, "lower threshold", &band.Lower); err != nil {
+		return Band{}, nil, err
+	}
+	if offset := spec.AggressiveOffset; offset != nil {
+		if err := parse(offset.Cool, "cool aggressive offset", &band.CoolOffset); err != nil {
+			return Band{}, nil, err
+		}
+		if err := parse(offset.Heat, "heat aggressive offset", &band.HeatOffset); err != nil {
+			return Band{}, nil, err
+		}
+	}
+
+	// Driving the setpoint less far than the band it corrects leaves the room
+	// hovering at the edge of the band.
+	if offset := spec.AggressiveOffset; offset != nil && offset.Cool != "" && band.CoolOffset < band.Lower {
+		return Band{}, nil, errors.New(UnitOf(spec).Sprintf("cool aggressive offset %.1f°C is smaller than the lower threshold %.1f°C", band.CoolOffset, band.Lower))
+	}
+	if offset := spec.AggressiveOffset; offset != nil && offset.Heat != "" && band.HeatOffset < band.Upper {
+		return Band{}, nil, errors.New(UnitOf(spec).Sprintf("heat aggressive offset %.1f°C is smaller than the upper threshold %.1f°C", band.HeatOffset, band.Upper))
+	}
+	return band, reasons, nil
+}
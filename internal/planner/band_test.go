@@ -0,0 +1,108 @@
+package planner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+func TestParseBand(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        thermopilotv1.ThermoPilotSpec
+		want        Band
+		wantReasons int
+		wantErr     bool
+	}{
+		{
+			name: "defaults",
+			want: Band{Upper: 1.0, Lower: 1.0, CoolOffset: 3.0, HeatOffset: 3.0},
+		},
+		{
+			name: "legacy threshold",
+			spec: thermopilotv1.ThermoPilotSpec{Threshold: "4.0"},
+			want: Band{Upper: 4.0, Lower: 4.0, CoolOffset: 3.0, HeatOffset: 3.0},
+		},
+		{
+			name:        "invalid legacy threshold",
+			spec:        thermopilotv1.ThermoPilotSpec{Threshold: "wide"},
+			want:        Band{Upper: 1.0, Lower: 1.0, CoolOffset: 3.0, HeatOffset: 3.0},
+			wantReasons: 1,
+		},
+		{
+			name: "asymmetric",
+			spec: thermopilotv1.ThermoPilotSpec{Threshold: "1.0", UpperThreshold: "0.5", LowerThreshold: "2.0",
+				AggressiveOffset: &thermopilotv1.AggressiveOffset{Cool: "2.5", Heat: "1.5"}},
+			want: Band{Upper: 0.5, Lower: 2.0, CoolOffset: 2.5, HeatOffset: 1.5},
+		},
+		{
+			name:    "zero upper threshold",
+			spec:    thermopilotv1.ThermoPilotSpec{UpperThreshold: "0"},
+			wantErr: true,
+		},
+		{
+			name:    "cool offset within the lower band",
+			spec:    thermopilotv1.ThermoPilotSpec{LowerThreshold: "2.0", AggressiveOffset: &thermopilotv1.AggressiveOffset{Cool: "1.5"}},
+			wantErr: true,
+		},
+		{
+			name:    "heat offset within the upper band",
+			spec:    thermopilotv1.ThermoPilotSpec{Threshold: "2.0", AggressiveOffset: &thermopilotv1.AggressiveOffset{Heat: "1.0"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid offset",
+			spec:    thermopilotv1.ThermoPilotSpec{AggressiveOffset: &thermopilotv1.AggressiveOffset{Cool: "far"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reasons, err := ParseBand(tt.spec)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Len(t, reasons, tt.wantReasons)
+		})
+	}
+}
+
+func TestDecide_AsymmetricBand(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	spec := thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", UpperThreshold: "0.5", LowerThreshold: "2.0",
+		AggressiveOffset: &thermopilotv1.AggressiveOffset{Cool: "2.5", Heat: "4.0"}}
+	tests := []struct {
+		name         string
+		mode         string
+		current      float64
+		wantAction   string
+		wantSetpoint float64
+	}{
+		{name: "cool - tight upper band", mode: ModeCool, current: 25.6, wantAction: ActionCooling, wantSetpoint: 25.0},
+		{name: "cool - loose lower band", mode: ModeCool, current: 23.5, wantAction: ActionNone, wantSetpoint: 25.0},
+		{name: "cool - below the lower band", mode: ModeCool, current: 22.9, wantAction: ActionAdjustUp, wantSetpoint: 27.5},
+		{name: "heat - below the lower band", mode: ModeHeat, current: 22.9, wantAction: ActionHeating, wantSetpoint: 25.0},
+		{name: "heat - above the upper band", mode: ModeHeat, current: 25.6, wantAction: ActionAdjustDown, wantSetpoint: 21.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := spec
+			spec.Mode = tt.mode
+			plan, err := Decide(Input{Now: now, CurrentTemperature: tt.current, Spec: spec})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAction, plan.Action)
+			assert.InDelta(t, tt.wantSetpoint, plan.Setpoint, 1e-9)
+			assert.Equal(t, 0.5, plan.UpperThreshold)
+			assert.Equal(t, 2.0, plan.LowerThreshold)
+		})
+	}
+}
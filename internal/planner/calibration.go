@@ -0,0 +1,78 @@
+package planner
+
+import (
+	"fmt"
+	"strconv"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+// CalibrateReading returns temperature corrected by the calibration offset of the
+// sensor deviceID in spec, together with the offset applied. The explanation is
+// set when an offset is configured for the sensor; an invalid offset is not
+// applied and explained instead.
+func CalibrateReading(spec thermopilotv1.ThermoPilotSpec, deviceID string, temperature float64) (float64, float64, string) {
+	if spec.Calibration == nil {
+		return temperature, 0, ""
+	}
+	offset, ok, err := calibrationOffset(spec.Calibration.Sensors, deviceID)
+	switch {
+	case err != nil:
+		return temperature, 0, fmt.Sprintf("sensor %s: %v, not applied", deviceID, err)
+	case !ok:
+		return temperature, 0, ""
+	}
+	calibrated := temperature + offset
+	return calibrated, offset, UnitOf(spec).Sprintf("sensor %s reported %.1f°C, calibrated by %s°C to %.1f°C",
+		deviceID, temperature, FormatOffset(offset), calibrated)
+}
+
+// CalibrateCommand adds the calibration offset of its air conditioner among
+// offsets to the setpoint of command, in unit, before it is fitted to its
+// capabilities. The explanation is set when an offset applies.
+func CalibrateCommand(unit Unit, offsets []thermopilotv1.CalibrationOffset, command Command) (Command, string) {
+	if command.Power == PowerOff {
+		return command, ""
+	}
+	offset, ok, err := calibrationOffset(offsets, command.DeviceID)
+	switch {
+	case err != nil:
+		return command, fmt.Sprintf("%s: %v, not applied", command.DeviceID, err)
+	case !ok:
+		return command, ""
+	}
+	why := unit.Sprintf("%s: setpoint %.1f°C calibrated by %s°C to %.1f°C",
+		command.DeviceID, command.Setpoint, FormatOffset(offset), command.Setpoint+offset)
+	command.Setpoint += offset
+	command.Offset = offset
+	return command, why
+}
+
+// FormatOffset formats a calibration offset with its sign, such as +2.0 or -1.2.
+func FormatOffset(offset float64) string {
+	return fmt.Sprintf("%+.1f", offset)
+}
+
+// airConditionerOffsets returns the air conditioner calibration offsets of spec.
+func airConditionerOffsets(spec thermopilotv1.ThermoPilotSpec) []thermopilotv1.CalibrationOffset {
+	if spec.Calibration == nil {
+		return nil
+	}
+	return spec.Calibration.AirConditioners
+}
+
+// calibrationOffset returns the offset of deviceID among offsets. ok is false
+// when the device has none.
+func calibrationOffset(offsets []thermopilotv1.CalibrationOffset, deviceID string) (offset float64, ok bool, err error) {
+	for _, calibration := range offsets {
+		if calibration.DeviceID != deviceID {
+			continue
+		}
+		offset, err := strconv.ParseFloat(calibration.Offset, 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid calibration offset %q", calibration.Offset)
+		}
+		return offset, offset != 0, nil
+	}
+	return 0, false, nil
+}
@@ -0,0 +1,94 @@
+package planner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+func TestCalibrateReading(t *testing.T) {
+	calibration := &thermopilotv1.Calibration{
+		Sensors: []thermopilotv1.CalibrationOffset{
+			{DeviceID: "bedroom", Offset: "-1.2"},
+			{DeviceID: "broken", Offset: "a lot"},
+		},
+	}
+	tests := []struct {
+		name        string
+		calibration *thermopilotv1.Calibration
+		deviceID    string
+		want        float64
+		wantOffset  float64
+		wantWhy     string
+	}{
+		{
+			name:     "no calibration",
+			deviceID: "bedroom",
+			want:     26.4,
+		},
+		{
+			name:        "sensor without offset",
+			calibration: calibration,
+			deviceID:    "living",
+			want:        26.4,
+		},
+		{
+			name:        "offset applied",
+			calibration: calibration,
+			deviceID:    "bedroom",
+			want:        25.2,
+			wantOffset:  -1.2,
+			wantWhy:     "sensor bedroom reported 26.4°C, calibrated by -1.2°C to 25.2°C",
+		},
+		{
+			name:        "invalid offset is not applied",
+			calibration: calibration,
+			deviceID:    "broken",
+			want:        26.4,
+			wantWhy:     `sensor broken: invalid calibration offset "a lot", not applied`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := thermopilotv1.ThermoPilotSpec{Calibration: tt.calibration}
+			got, offset, why := CalibrateReading(spec, tt.deviceID, 26.4)
+			assert.InDelta(t, tt.want, got, 1e-9)
+			assert.InDelta(t, tt.wantOffset, offset, 1e-9)
+			assert.Equal(t, tt.wantWhy, why)
+		})
+	}
+}
+
+func TestPlan_WithDevices_Calibration(t *testing.T) {
+	plan := Plan{Action: ActionCooling, Setpoint: 25.0, Mode: ModeCool, Power: PowerOn,
+		Offsets: []thermopilotv1.CalibrationOffset{{DeviceID: "ac2", Offset: "2.0"}}}
+	plan = plan.WithDevices([]string{"ac1", "ac2"})
+	require.Len(t, plan.Commands, 2)
+	assert.InDelta(t, 25.0, plan.Commands[0].Setpoint, 1e-9)
+	assert.InDelta(t, 27.0, plan.Commands[1].Setpoint, 1e-9)
+	assert.Contains(t, plan.Reasons, "ac2: setpoint 25.0°C calibrated by +2.0°C to 27.0°C")
+
+	var status thermopilotv1.ThermoPilotStatus
+	plan.Record(&status)
+	require.Len(t, status.LastDecision.Commands, 2)
+	assert.Empty(t, status.LastDecision.Commands[0].CalibrationOffset)
+	assert.Equal(t, "27.0", status.LastDecision.Commands[1].Setpoint)
+	assert.Equal(t, "+2.0", status.LastDecision.Commands[1].CalibrationOffset)
+	assert.Equal(t, "25.0", status.LastDecision.Setpoint)
+
+	off := Plan{Action: ActionSafeOff, Power: PowerOff, Offsets: plan.Offsets}.WithDevices([]string{"ac2"})
+	require.Len(t, off.Commands, 1)
+	assert.Zero(t, off.Commands[0].Offset)
+}
+
+func TestPlan_WithDevices_CalibrationBeforeFit(t *testing.T) {
+	plan := Plan{Action: ActionCooling, Setpoint: 29.0, Mode: ModeCool, Power: PowerOn,
+		Offsets: []thermopilotv1.CalibrationOffset{{DeviceID: "ac1", Offset: "2.5"}}}
+	plan = plan.WithDevices([]string{"ac1"})
+	require.Len(t, plan.Commands, 1)
+	assert.InDelta(t, DefaultMaxSetpoint, plan.Commands[0].Setpoint, 1e-9)
+	assert.InDelta(t, 2.5, plan.Commands[0].Offset, 1e-9)
+}
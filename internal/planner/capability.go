@@ -0,0 +1,112 @@
+package planner
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+// Fan speeds of a command.
+const (
+	FanAuto   = "auto"
+	FanLow    = "low"
+	FanMedium = "medium"
+	FanHigh   = "high"
+)
+
+// Setpoint range, in Celsius, and step of an air conditioner without a capability
+// profile, as accepted by the setAll command of SwitchBot infrared remotes.
+const (
+	DefaultMinSetpoint = 16.0
+	DefaultMaxSetpoint = 30.0
+	defaultStep        = 1.0
+)
+
+// ProfileFor returns the capability profile of the air conditioner deviceID: the
+// profile listing it, or else the first profile without devices. It returns nil
+// when no profile applies.
+func ProfileFor(profiles []thermopilotv1.CapabilityProfile, deviceID string) *thermopilotv1.CapabilityProfile {
+	var fallback *thermopilotv1.CapabilityProfile
+	for i := range profiles {
+		profile := &profiles[i]
+		if slices.Contains(profile.Devices, deviceID) {
+			return profile
+		}
+		if len(profile.Devices) == 0 && fallback == nil {
+			fallback = profile
+		}
+	}
+	return fallback
+}
+
+// FitCommand fits command, whose setpoint is in unit, to the capability profile of
+// its air conditioner among profiles: the setpoint is quantized to the step and
+// clamped to the range of the mode, and the fan speed is chosen among the
+// supported ones. Steps and ranges are in Celsius, the unit the air conditioners
+// are commanded in, so the setpoint is fitted after converting it. The
+// explanation is set when the setpoint had to change. An error is returned when
+// the profile does not support the mode of the command.
+func FitCommand(unit Unit, profiles []thermopilotv1.CapabilityProfile, command Command) (Command, string, error) {
+	profile := ProfileFor(profiles, command.DeviceID)
+	low, high, step := DefaultMinSetpoint, DefaultMaxSetpoint, defaultStep
+	fanSpeed := FanAuto
+	if profile != nil {
+		command.Profile = profile.Name
+		if len(profile.Modes) > 0 && command.Power != PowerOff &&
+			!slices.Contains(profile.Modes, thermopilotv1.ProfileMode(command.Mode)) {
+			return Command{}, "", fmt.Errorf("profile %s does not support %s mode", profile.Name, command.Mode)
+		}
+		if len(profile.FanSpeeds) > 0 && !slices.Contains(profile.FanSpeeds, thermopilotv1.FanSpeed(FanAuto)) {
+			fanSpeed = string(profile.FanSpeeds[0])
+		}
+		if profile.Step != "" {
+			parsed, err := strconv.ParseFloat(profile.Step, 64)
+			if err != nil || parsed <= 0 {
+				return Command{}, "", fmt.Errorf("invalid step %q in profile %s", profile.Step, profile.Name)
+			}
+			step = parsed
+		}
+		setpointRange := profile.Cool
+		if command.Mode == ModeHeat {
+			setpointRange = profile.Heat
+		}
+		if setpointRange != nil && (command.Mode == ModeCool || command.Mode == ModeHeat) {
+			var err error
+			if low, err = ParseTemperature(setpointRange.Min); err != nil {
+				return Command{}, "", fmt.Errorf("invalid %s range in profile %s: %w", command.Mode, profile.Name, err)
+			}
+			if high, err = ParseTemperature(setpointRange.Max); err != nil {
+				return Command{}, "", fmt.Errorf("invalid %s range in profile %s: %w", command.Mode, profile.Name, err)
+			}
+		}
+	}
+	if command.Power == PowerOff {
+		return command, "", nil
+	}
+	command.FanSpeed = fanSpeed
+
+	// keep the bounds on the step so that clamping does not leave it
+	low, high = math.Ceil(low/step)*step, math.Floor(high/step)*step
+	celsius := unit.ToCelsius(command.Setpoint)
+	fitted := math.Min(math.Max(math.Round(celsius/step)*step, low), high)
+	if math.Abs(fitted-celsius) < 1e-9 {
+		return command, "", nil
+	}
+	source := "default capabilities"
+	if profile != nil {
+		source = "profile " + profile.Name
+	}
+	why := fmt.Sprintf("%s: setpoint %s sent as %s°C (%s: %s-%s°C in steps of %s°C)",
+		command.DeviceID, unit.Sprintf("%.1f°C", command.Setpoint), formatStep(fitted), source,
+		formatStep(low), formatStep(high), formatStep(step))
+	command.Setpoint = unit.FromCelsius(fitted)
+	return command, why, nil
+}
+
+// formatStep formats a temperature with the decimals it needs, 24 or 24.5.
+func formatStep(value float64) string {
+	return strconv.FormatFloat(math.Round(value*10)/10, 'f', -1, 64)
+}
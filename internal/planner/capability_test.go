@@ -0,0 +1,134 @@
+package planner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+func TestFitCommand(t *testing.T) {
+	profiles := []thermopilotv1.CapabilityProfile{
+		{
+			Name:      "bedroom",
+			Devices:   []string{"ac2"},
+			Cool:      &thermopilotv1.SetpointRange{Min: "18", Max: "30"},
+			Heat:      &thermopilotv1.SetpointRange{Min: "16", Max: "26"},
+			Step:      "0.5",
+			Modes:     []thermopilotv1.ProfileMode{"cool", "heat"},
+			FanSpeeds: []thermopilotv1.FanSpeed{"low", "high"},
+		},
+		{
+			Name:  "cool-only",
+			Modes: []thermopilotv1.ProfileMode{"cool"},
+		},
+	}
+	tests := []struct {
+		name         string
+		profiles     []thermopilotv1.CapabilityProfile
+		command      Command
+		wantSetpoint float64
+		wantFan      string
+		wantProfile  string
+		wantWhy      bool
+		wantErr      bool
+	}{
+		{
+			name:         "default capabilities round to whole degrees",
+			command:      Command{DeviceID: "ac1", Setpoint: 24.5, Mode: ModeCool, Power: PowerOn},
+			wantSetpoint: 25,
+			wantFan:      FanAuto,
+			wantWhy:      true,
+		},
+		{
+			name:         "default capabilities clamp",
+			command:      Command{DeviceID: "ac1", Setpoint: 35, Mode: ModeCool, Power: PowerOn},
+			wantSetpoint: 30,
+			wantFan:      FanAuto,
+			wantWhy:      true,
+		},
+		{
+			name:         "half degree steps",
+			profiles:     profiles,
+			command:      Command{DeviceID: "ac2", Setpoint: 24.3, Mode: ModeCool, Power: PowerOn},
+			wantSetpoint: 24.5,
+			wantFan:      FanLow,
+			wantProfile:  "bedroom",
+			wantWhy:      true,
+		},
+		{
+			name:         "range of the mode",
+			profiles:     profiles,
+			command:      Command{DeviceID: "ac2", Setpoint: 28, Mode: ModeHeat, Power: PowerOn},
+			wantSetpoint: 26,
+			wantFan:      FanLow,
+			wantProfile:  "bedroom",
+			wantWhy:      true,
+		},
+		{
+			name:         "unchanged",
+			profiles:     profiles,
+			command:      Command{DeviceID: "ac2", Setpoint: 22.5, Mode: ModeCool, Power: PowerOn},
+			wantSetpoint: 22.5,
+			wantFan:      FanLow,
+			wantProfile:  "bedroom",
+		},
+		{
+			name:        "unsupported mode",
+			profiles:    profiles,
+			command:     Command{DeviceID: "ac1", Setpoint: 22, Mode: ModeHeat, Power: PowerOn},
+			wantProfile: "cool-only",
+			wantErr:     true,
+		},
+		{
+			name:        "turning off needs no capabilities",
+			profiles:    profiles,
+			command:     Command{DeviceID: "ac1", Power: PowerOff},
+			wantProfile: "cool-only",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, why, err := FitCommand(Celsius, tt.profiles, tt.command)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.wantSetpoint, got.Setpoint, 1e-9)
+			assert.Equal(t, tt.wantFan, got.FanSpeed)
+			assert.Equal(t, tt.wantProfile, got.Profile)
+			assert.Equal(t, tt.wantWhy, why != "", why)
+		})
+	}
+}
+
+func TestPlan_WithDevices_Profiles(t *testing.T) {
+	plan := Plan{Action: ActionHeating, Setpoint: 21.0, Mode: ModeHeat, Power: PowerOn,
+		Profiles: []thermopilotv1.CapabilityProfile{{Name: "cool-only", Devices: []string{"ac2"}, Modes: []thermopilotv1.ProfileMode{"cool"}}}}
+	got := plan.WithDevices([]string{"ac1", "ac2"})
+	require.Len(t, got.Commands, 1)
+	assert.Equal(t, "ac1", got.Commands[0].DeviceID)
+	assert.Contains(t, got.Reasons, "ac2 skipped: profile cool-only does not support heat mode")
+	assert.Empty(t, plan.Reasons)
+}
+
+func TestPlan_Record_EffectiveSetpoint(t *testing.T) {
+	plan := Plan{Action: ActionCooling, Setpoint: 24.3, Mode: ModeCool, Power: PowerOn,
+		Profiles: []thermopilotv1.CapabilityProfile{{Name: "half", Step: "0.5"}}}
+	plan = plan.WithDevices([]string{"ac1", "ac2"})
+
+	var status thermopilotv1.ThermoPilotStatus
+	plan.Record(&status)
+	require.NotNil(t, status.LastCommand)
+	assert.Equal(t, "24.5", status.LastCommand.Setpoint)
+	assert.Equal(t, "24.3", status.LastCommand.RequestedSetpoint)
+	assert.Equal(t, "24.3", status.LastDecision.Setpoint)
+	require.Len(t, status.LastDecision.Commands, 2)
+	assert.Equal(t, "24.5", status.LastDecision.Commands[1].Setpoint)
+	assert.Equal(t, "half", status.LastDecision.Commands[1].Profile)
+	assert.Equal(t, FanAuto, status.LastDecision.Commands[1].FanSpeed)
+}
@@ -0,0 +1,113 @@
+package planner
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+// startsWindow is the window maxStartsPerHour is counted over.
+const startsWindow = time.Hour
+
+// running reports whether the commands of the plan drive the compressor towards a
+// setpoint. Driving the air conditioners away from the target lets the compressor
+// rest, like turning them off does.
+func (p Plan) running() bool {
+	if p.Power == PowerOff {
+		return false
+	}
+	switch p.Action {
+	case ActionAdjustUp, ActionAdjustDown:
+		return false
+	default:
+		return true
+	}
+}
+
+// protectEquipment withholds the action of the plan when sending it would break a
+// limit of the equipment protection of spec, given the cycles recorded in previous.
+// The first limit found is reported, together with when the action is allowed.
+func (p *Plan) protectEquipment(spec thermopilotv1.ThermoPilotSpec, previous *thermopilotv1.EquipmentStatus) {
+	protection := spec.EquipmentProtection
+	if protection == nil || previous == nil || !p.NeedsAction() || p.Suspended || p.SafetyLimit {
+		return
+	}
+	running := p.running()
+
+	var until time.Time
+	var why string
+	switch {
+	case previous.Running && !running && protection.MinOnTime != nil && previous.LastStart != nil &&
+		p.Time.Before(previous.LastStart.Add(protection.MinOnTime.Duration)):
+		until = previous.LastStart.Add(protection.MinOnTime.Duration)
+		why = fmt.Sprintf("compressor started at %s, minimum on-time %s",
+			previous.LastStart.UTC().Format(time.RFC3339), protection.MinOnTime.Duration)
+	case !previous.Running && running && protection.MinOffTime != nil && previous.LastStop != nil &&
+		p.Time.Before(previous.LastStop.Add(protection.MinOffTime.Duration)):
+		until = previous.LastStop.Add(protection.MinOffTime.Duration)
+		why = fmt.Sprintf("compressor stopped at %s, minimum off-time %s",
+			previous.LastStop.UTC().Format(time.RFC3339), protection.MinOffTime.Duration)
+	case !previous.Running && running && protection.MaxStartsPerHour > 0 &&
+		len(recentStarts(previous.Starts, p.Time)) >= int(protection.MaxStartsPerHour):
+		starts := recentStarts(previous.Starts, p.Time)
+		until = starts[len(starts)-int(protection.MaxStartsPerHour)].Add(startsWindow)
+		why = fmt.Sprintf("%d starts within the last hour, maximum %d", len(starts), protection.MaxStartsPerHour)
+	case p.Mode != "" && previous.Mode != "" && p.Mode != previous.Mode && protection.MinModeChangeInterval != nil &&
+		previous.LastModeChange != nil && p.Time.Before(previous.LastModeChange.Add(protection.MinModeChangeInterval.Duration)):
+		until = previous.LastModeChange.Add(protection.MinModeChangeInterval.Duration)
+		why = fmt.Sprintf("mode changed to %s at %s, minimum interval %s",
+			previous.Mode, previous.LastModeChange.UTC().Format(time.RFC3339), protection.MinModeChangeInterval.Duration)
+	default:
+		return
+	}
+	p.reason("%s blocked by equipment protection until %s: %s", p.Action, until.UTC().Format(time.RFC3339), why)
+	p.Blocked, p.BlockedUntil, p.Action = p.Action, until, ActionNone
+}
+
+// trackEquipment sets the cycles to record once the commands of the plan are sent,
+// when the equipment protection of spec is enabled. Plans that send nothing, such
+// as dry runs, keep the cycles of previous.
+func (p *Plan) trackEquipment(spec thermopilotv1.ThermoPilotSpec, previous *thermopilotv1.EquipmentStatus) {
+	if spec.EquipmentProtection == nil {
+		p.Equipment = nil
+		return
+	}
+	equipment := previous.DeepCopy()
+	if equipment == nil {
+		equipment = &thermopilotv1.EquipmentStatus{}
+	}
+	if !p.Actuate() {
+		p.Equipment = equipment
+		return
+	}
+
+	now := metav1.NewTime(p.Time)
+	running := p.running()
+	equipment.Starts = recentStarts(equipment.Starts, p.Time)
+	switch {
+	case running && (previous == nil || !previous.Running):
+		equipment.LastStart = &now
+		equipment.Starts = append(equipment.Starts, now)
+	case !running && (previous == nil || previous.Running):
+		equipment.LastStop = &now
+	}
+	equipment.Running = running
+	if p.Mode != "" && p.Mode != equipment.Mode {
+		if equipment.Mode != "" {
+			equipment.LastModeChange = &now
+		}
+		equipment.Mode = p.Mode
+	}
+	p.Equipment = equipment
+}
+
+// recentStarts returns the starts within the hour before now.
+func recentStarts(starts []metav1.Time, now time.Time) []metav1.Time {
+	return slices.DeleteFunc(slices.Clone(starts), func(start metav1.Time) bool {
+		return !start.Add(startsWindow).After(now)
+	})
+}
@@ -0,0 +1,181 @@
+package planner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+func TestDecide_EquipmentProtection(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *metav1.Time {
+		t := metav1.NewTime(now.Add(d))
+		return &t
+	}
+	protection := &thermopilotv1.EquipmentProtection{
+		MinOnTime:             &metav1.Duration{Duration: 10 * time.Minute},
+		MinOffTime:            &metav1.Duration{Duration: 5 * time.Minute},
+		MaxStartsPerHour:      3,
+		MinModeChangeInterval: &metav1.Duration{Duration: 30 * time.Minute},
+	}
+	tests := []struct {
+		name             string
+		current          float64
+		mode             string
+		previous         *thermopilotv1.EquipmentStatus
+		wantAction       string
+		wantBlocked      string
+		wantBlockedUntil time.Time
+	}{
+		{
+			name:       "first command is allowed",
+			current:    27.0,
+			mode:       ModeCool,
+			wantAction: ActionCooling,
+		},
+		{
+			name:             "minimum on-time",
+			current:          23.0,
+			mode:             ModeCool,
+			previous:         &thermopilotv1.EquipmentStatus{Running: true, Mode: ModeCool, LastStart: at(-4 * time.Minute)},
+			wantAction:       ActionNone,
+			wantBlocked:      ActionAdjustUp,
+			wantBlockedUntil: now.Add(6 * time.Minute),
+		},
+		{
+			name:       "minimum on-time elapsed",
+			current:    23.0,
+			mode:       ModeCool,
+			previous:   &thermopilotv1.EquipmentStatus{Running: true, Mode: ModeCool, LastStart: at(-10 * time.Minute)},
+			wantAction: ActionAdjustUp,
+		},
+		{
+			name:             "minimum off-time",
+			current:          27.0,
+			mode:             ModeCool,
+			previous:         &thermopilotv1.EquipmentStatus{Mode: ModeCool, LastStop: at(-2 * time.Minute)},
+			wantAction:       ActionNone,
+			wantBlocked:      ActionCooling,
+			wantBlockedUntil: now.Add(3 * time.Minute),
+		},
+		{
+			name:    "maximum starts per hour",
+			current: 27.0,
+			mode:    ModeCool,
+			previous: &thermopilotv1.EquipmentStatus{Mode: ModeCool, LastStop: at(-10 * time.Minute),
+				Starts: []metav1.Time{*at(-70 * time.Minute), *at(-50 * time.Minute), *at(-40 * time.Minute), *at(-20 * time.Minute)}},
+			wantAction:       ActionNone,
+			wantBlocked:      ActionCooling,
+			wantBlockedUntil: now.Add(10 * time.Minute),
+		},
+		{
+			name:    "starts older than an hour are not counted",
+			current: 27.0,
+			mode:    ModeCool,
+			previous: &thermopilotv1.EquipmentStatus{Mode: ModeCool, LastStop: at(-10 * time.Minute),
+				Starts: []metav1.Time{*at(-90 * time.Minute), *at(-60 * time.Minute), *at(-20 * time.Minute)}},
+			wantAction: ActionCooling,
+		},
+		{
+			name:             "minimum mode change interval",
+			current:          20.0,
+			mode:             ModeHeat,
+			previous:         &thermopilotv1.EquipmentStatus{Running: true, Mode: ModeCool, LastStart: at(-time.Hour), LastModeChange: at(-10 * time.Minute)},
+			wantAction:       ActionNone,
+			wantBlocked:      ActionHeating,
+			wantBlockedUntil: now.Add(20 * time.Minute),
+		},
+		{
+			name:       "running commands are resent",
+			current:    27.0,
+			mode:       ModeCool,
+			previous:   &thermopilotv1.EquipmentStatus{Running: true, Mode: ModeCool, LastStart: at(-time.Minute)},
+			wantAction: ActionCooling,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := Decide(Input{
+				Now:                now,
+				CurrentTemperature: tt.current,
+				Spec: thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", Mode: tt.mode,
+					EquipmentProtection: protection},
+				Previous:          thermopilotv1.ThermoPilotStatus{Equipment: tt.previous},
+				AirConditionerIDs: []string{"ac1"},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAction, plan.Action)
+			assert.Equal(t, tt.wantBlocked, plan.Blocked)
+			assert.Equal(t, tt.wantBlockedUntil, plan.BlockedUntil)
+			if tt.wantBlocked != "" {
+				assert.Empty(t, plan.Commands)
+				assert.Equal(t, tt.previous, plan.Equipment)
+
+				var status thermopilotv1.ThermoPilotStatus
+				plan.Record(&status)
+				assert.Equal(t, tt.wantBlocked, status.LastDecision.BlockedAction)
+				assert.Equal(t, tt.wantBlockedUntil, status.LastDecision.BlockedUntil.Time)
+				assert.Nil(t, status.LastCommand)
+			}
+		})
+	}
+}
+
+func TestDecide_EquipmentTracking(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	spec := thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", Mode: ModeCool,
+		EquipmentProtection: &thermopilotv1.EquipmentProtection{MaxStartsPerHour: 4}}
+	var status thermopilotv1.ThermoPilotStatus
+	decide := func(current float64, mode string) Plan {
+		t.Helper()
+		spec.Mode = mode
+		plan, err := Decide(Input{Now: now, CurrentTemperature: current, Spec: spec, Previous: status, AirConditionerIDs: []string{"ac1"}})
+		require.NoError(t, err)
+		plan.Record(&status)
+		now = now.Add(15 * time.Minute)
+		return plan
+	}
+
+	decide(27.0, ModeCool)
+	require.NotNil(t, status.Equipment)
+	assert.True(t, status.Equipment.Running)
+	assert.Equal(t, ModeCool, status.Equipment.Mode)
+	assert.Len(t, status.Equipment.Starts, 1)
+	assert.Nil(t, status.Equipment.LastModeChange)
+	started := status.Equipment.LastStart
+
+	decide(27.0, ModeCool)
+	assert.Equal(t, started, status.Equipment.LastStart, "resending does not count as a start")
+	assert.Len(t, status.Equipment.Starts, 1)
+
+	decide(23.0, ModeCool)
+	assert.False(t, status.Equipment.Running)
+	require.NotNil(t, status.Equipment.LastStop)
+
+	decide(20.0, ModeHeat)
+	assert.True(t, status.Equipment.Running)
+	assert.Equal(t, ModeHeat, status.Equipment.Mode)
+	require.NotNil(t, status.Equipment.LastModeChange)
+	assert.Len(t, status.Equipment.Starts, 2)
+
+	decide(20.0, ModeHeat)
+	decide(20.0, ModeHeat)
+	assert.Len(t, status.Equipment.Starts, 1, "starts older than an hour are dropped")
+
+	spec.DryRun = true
+	changed := status.Equipment.LastModeChange
+	decide(27.0, ModeCool)
+	assert.Equal(t, ModeHeat, status.Equipment.Mode, "dry runs do not cycle the compressor")
+	assert.Equal(t, changed, status.Equipment.LastModeChange)
+	spec.DryRun = false
+
+	spec.EquipmentProtection = nil
+	decide(27.0, ModeCool)
+	assert.Nil(t, status.Equipment)
+}
@@ -0,0 +1,109 @@
+package planner
+
+import (
+	"math"
+	"slices"
+	"strconv"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+// Smoothing methods of a reading filter.
+const (
+	SmoothingNone   = "none"
+	SmoothingEMA    = "ema"
+	SmoothingMedian = "median"
+)
+
+const (
+	defaultAlpha  = 0.5
+	defaultWindow = 5
+)
+
+// FilterReading runs raw through the reading filter of spec, continuing from the
+// filter state recorded by the previous reconcile. It returns the value to decide
+// on, the new filter state and an explanation. Without a filter raw is returned
+// unchanged and the state is nil.
+//
+// A reading further than maxJump from the filtered value is rejected and the
+// previous value is kept, unless the previous reading was rejected too and this
+// one confirms it, in which case the filter restarts from the new level.
+func FilterReading(spec thermopilotv1.ThermoPilotSpec, previous *thermopilotv1.FilterStatus, raw float64) (float64, *thermopilotv1.FilterStatus, string) {
+	filter := spec.Filter
+	if filter == nil {
+		return raw, nil, ""
+	}
+	smoothing := filter.Smoothing
+	if smoothing == "" {
+		smoothing = SmoothingNone
+	}
+	window := defaultWindow
+	if filter.Window > 0 {
+		window = int(filter.Window)
+	}
+
+	var last, lastRaw float64
+	var hasLast bool
+	var history []float64
+	if previous != nil {
+		var err error
+		last, err = strconv.ParseFloat(previous.FilteredTemperature, 64)
+		hasLast = err == nil
+		lastRaw, _ = strconv.ParseFloat(previous.RawTemperature, 64)
+		for _, value := range previous.Window {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				history = append(history, parsed)
+			}
+		}
+	}
+
+	if maxJump, err := strconv.ParseFloat(filter.MaxJump, 64); err == nil && hasLast && math.Abs(raw-last) > maxJump {
+		if !previous.Rejected || math.Abs(raw-lastRaw) > maxJump {
+			status := previous.DeepCopy()
+			status.RawTemperature = FormatTemperature(raw)
+			status.Rejected = true
+			return last, status, UnitOf(spec).Sprintf("reading %.1f°C rejected as an outlier, more than %.1f°C from %.2f°C",
+				raw, maxJump, last)
+		}
+		// the jump is real: restart the filter from the new level
+		history, hasLast = nil, false
+	}
+
+	history = append(history, raw)
+	if len(history) > window {
+		history = history[len(history)-window:]
+	}
+	value := raw
+	switch smoothing {
+	case SmoothingEMA:
+		alpha := defaultAlpha
+		if parsed, err := strconv.ParseFloat(filter.Alpha, 64); err == nil && parsed > 0 && parsed <= 1 {
+			alpha = parsed
+		}
+		if hasLast {
+			value = alpha*raw + (1-alpha)*last
+		}
+	case SmoothingMedian:
+		value = median(history)
+	}
+
+	status := &thermopilotv1.FilterStatus{
+		RawTemperature:      FormatTemperature(raw),
+		FilteredTemperature: strconv.FormatFloat(value, 'f', 2, 64),
+	}
+	for _, v := range history {
+		status.Window = append(status.Window, FormatTemperature(v))
+	}
+	reason := UnitOf(spec).Sprintf("reading %.1f°C filtered to %.2f°C (%s)", raw, value, smoothing)
+	return value, status, reason
+}
+
+func median(values []float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
@@ -0,0 +1,123 @@
+package planner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+func TestFilterReading(t *testing.T) {
+	tests := []struct {
+		name         string
+		filter       *thermopilotv1.ReadingFilter
+		previous     *thermopilotv1.FilterStatus
+		raw          float64
+		want         float64
+		wantWindow   []string
+		wantRejected bool
+	}{
+		{
+			name: "no filter",
+			raw:  26.3,
+			want: 26.3,
+		},
+		{
+			name:       "ema starts from the first reading",
+			filter:     &thermopilotv1.ReadingFilter{Smoothing: SmoothingEMA, Alpha: "0.25"},
+			raw:        26.0,
+			want:       26.0,
+			wantWindow: []string{"26.0"},
+		},
+		{
+			name:   "ema",
+			filter: &thermopilotv1.ReadingFilter{Smoothing: SmoothingEMA, Alpha: "0.25"},
+			previous: &thermopilotv1.FilterStatus{
+				RawTemperature: "26.0", FilteredTemperature: "26.00", Window: []string{"26.0"},
+			},
+			raw:        28.0,
+			want:       26.5,
+			wantWindow: []string{"26.0", "28.0"},
+		},
+		{
+			name:   "median of the window",
+			filter: &thermopilotv1.ReadingFilter{Smoothing: SmoothingMedian, Window: 3},
+			previous: &thermopilotv1.FilterStatus{
+				RawTemperature: "25.4", FilteredTemperature: "25.20", Window: []string{"25.0", "25.2", "25.4"},
+			},
+			raw:        29.0,
+			want:       25.4,
+			wantWindow: []string{"25.2", "25.4", "29.0"},
+		},
+		{
+			name:   "median of an even window",
+			filter: &thermopilotv1.ReadingFilter{Smoothing: SmoothingMedian, Window: 4},
+			previous: &thermopilotv1.FilterStatus{
+				RawTemperature: "25.2", FilteredTemperature: "25.10", Window: []string{"25.0", "25.2"},
+			},
+			raw:        25.6,
+			want:       25.2,
+			wantWindow: []string{"25.0", "25.2", "25.6"},
+		},
+		{
+			name:   "outlier rejected",
+			filter: &thermopilotv1.ReadingFilter{Smoothing: SmoothingEMA, MaxJump: "1.5"},
+			previous: &thermopilotv1.FilterStatus{
+				RawTemperature: "25.0", FilteredTemperature: "25.00", Window: []string{"25.0"},
+			},
+			raw:          28.0,
+			want:         25.0,
+			wantWindow:   []string{"25.0"},
+			wantRejected: true,
+		},
+		{
+			name:   "outlier confirmed by the next reading restarts the filter",
+			filter: &thermopilotv1.ReadingFilter{Smoothing: SmoothingEMA, MaxJump: "1.5"},
+			previous: &thermopilotv1.FilterStatus{
+				RawTemperature: "28.0", FilteredTemperature: "25.00", Window: []string{"25.0"}, Rejected: true,
+			},
+			raw:        28.4,
+			want:       28.4,
+			wantWindow: []string{"28.4"},
+		},
+		{
+			name:   "a different outlier is rejected again",
+			filter: &thermopilotv1.ReadingFilter{MaxJump: "1.5"},
+			previous: &thermopilotv1.FilterStatus{
+				RawTemperature: "28.0", FilteredTemperature: "25.00", Window: []string{"25.0"}, Rejected: true,
+			},
+			raw:          21.0,
+			want:         25.0,
+			wantWindow:   []string{"25.0"},
+			wantRejected: true,
+		},
+		{
+			name:   "within the maximum jump",
+			filter: &thermopilotv1.ReadingFilter{MaxJump: "1.5"},
+			previous: &thermopilotv1.FilterStatus{
+				RawTemperature: "25.0", FilteredTemperature: "25.00", Window: []string{"25.0"},
+			},
+			raw:        26.0,
+			want:       26.0,
+			wantWindow: []string{"25.0", "26.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := thermopilotv1.ThermoPilotSpec{Filter: tt.filter}
+			got, status, reason := FilterReading(spec, tt.previous, tt.raw)
+			assert.InDelta(t, tt.want, got, 1e-9)
+			if tt.filter == nil {
+				assert.Nil(t, status)
+				assert.Empty(t, reason)
+				return
+			}
+			assert.NotEmpty(t, reason)
+			assert.Equal(t, FormatTemperature(tt.raw), status.RawTemperature)
+			assert.Equal(t, tt.wantWindow, status.Window)
+			assert.Equal(t, tt.wantRejected, status.Rejected)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package planner
+
+import (
+	"fmt"
+	"time"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+const (
+	// DefaultLowBattery is the battery level, in percent, at or below which a
+	// sensor is reported as low on battery when the policy does not say.
+	DefaultLowBattery = 20
+	// DefaultFrozenAfter is how long a sensor may report the same reading before
+	// it is reported as frozen when the policy does not say.
+	DefaultFrozenAfter = 3 * time.Hour
+)
+
+// Reading is a reading as reported by a sensor, before filtering.
+type Reading struct {
+	DeviceID    string
+	Temperature float64
+	Humidity    int
+	// Battery is the battery level in percent, nil when the sensor does not report one
+	Battery *int
+}
+
+// SensorHealth is the outcome of the health checks of a reading.
+type SensorHealth struct {
+	// Battery is the battery level to record, nil when unknown
+	Battery *int32
+	// UnchangedSince is when the sensor started reporting the current reading
+	UnchangedSince time.Time
+	// LowBattery and Frozen explain why the sensor is unhealthy, empty when it is not
+	LowBattery string
+	Frozen     string
+}
+
+// CheckSensor checks reading against the sensor health policy of spec. previous
+// is the status recorded by the last reconcile, from which the last reading of the
+// same sensor and since when it has not changed are taken.
+//
+// The SwitchBot status API does not say when a sensor last measured, so a frozen
+// sensor is told apart by the temperature and the humidity both staying the same.
+func CheckSensor(spec thermopilotv1.ThermoPilotSpec, previous thermopilotv1.ThermoPilotStatus, reading Reading, now time.Time) SensorHealth {
+	lowBattery, frozenAfter := DefaultLowBattery, DefaultFrozenAfter
+	if policy := spec.SensorHealth; policy != nil {
+		if policy.LowBattery != nil {
+			lowBattery = int(*policy.LowBattery)
+		}
+		if policy.FrozenAfter != nil {
+			frozenAfter = policy.FrozenAfter.Duration
+		}
+	}
+
+	health := SensorHealth{UnchangedSince: now}
+	if sensor := previous.Sensor; sensor != nil && sensor.DeviceID == reading.DeviceID && sensor.UnchangedSince != nil &&
+		lastRawTemperature(previous) == FormatTemperature(reading.Temperature) &&
+		previous.CurrentHumidity != nil && int(*previous.CurrentHumidity) == reading.Humidity {
+		health.UnchangedSince = sensor.UnchangedSince.Time
+	}
+	if frozenAfter > 0 && now.Sub(health.UnchangedSince) >= frozenAfter {
+		health.Frozen = UnitOf(spec).Sprintf("sensor %s has reported %.1f°C and %d%% humidity since %s",
+			reading.DeviceID, reading.Temperature, reading.Humidity, health.UnchangedSince.UTC().Format(time.RFC3339))
+	}
+
+	if reading.Battery != nil {
+		battery := int32(*reading.Battery)
+		health.Battery = &battery
+		if lowBattery > 0 && *reading.Battery <= lowBattery {
+			health.LowBattery = fmt.Sprintf("sensor %s battery is at %d%%", reading.DeviceID, *reading.Battery)
+		}
+	}
+	return health
+}
+
+// lastRawTemperature returns the last reading recorded in status, as reported by
+// the sensor.
+func lastRawTemperature(status thermopilotv1.ThermoPilotStatus) string {
+	if status.Sensor != nil && status.Sensor.ReportedTemperature != "" {
+		return status.Sensor.ReportedTemperature
+	}
+	if status.Filter != nil {
+		return status.Filter.RawTemperature
+	}
+	return status.CurrentTemperature
+}
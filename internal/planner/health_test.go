@@ -0,0 +1,135 @@
+package planner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+func TestCheckSensor(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	since := metav1.NewTime(now.Add(-3 * time.Hour))
+	previous := thermopilotv1.ThermoPilotStatus{
+		CurrentTemperature: "26.0",
+		CurrentHumidity:    ptr.To[int32](50),
+		Sensor:             &thermopilotv1.SensorStatus{Source: SourcePrimary, DeviceID: "meter1", UnchangedSince: &since},
+	}
+	reading := Reading{DeviceID: "meter1", Temperature: 26.0, Humidity: 50, Battery: ptr.To(80)}
+	tests := []struct {
+		name               string
+		policy             *thermopilotv1.SensorHealthPolicy
+		previous           thermopilotv1.ThermoPilotStatus
+		mutate             func(*Reading)
+		wantUnchangedSince time.Time
+		wantFrozen         bool
+		wantLowBattery     bool
+	}{
+		{
+			name:               "frozen after the default window",
+			previous:           previous,
+			wantUnchangedSince: since.Time,
+			wantFrozen:         true,
+		},
+		{
+			name:               "temperature changed",
+			previous:           previous,
+			mutate:             func(r *Reading) { r.Temperature = 26.1 },
+			wantUnchangedSince: now,
+		},
+		{
+			name:               "humidity changed",
+			previous:           previous,
+			mutate:             func(r *Reading) { r.Humidity = 51 },
+			wantUnchangedSince: now,
+		},
+		{
+			name:               "another sensor",
+			previous:           previous,
+			mutate:             func(r *Reading) { r.DeviceID = "meter2" },
+			wantUnchangedSince: now,
+		},
+		{
+			name:               "longer window",
+			policy:             &thermopilotv1.SensorHealthPolicy{FrozenAfter: &metav1.Duration{Duration: 4 * time.Hour}},
+			previous:           previous,
+			wantUnchangedSince: since.Time,
+		},
+		{
+			name:               "frozen check disabled",
+			policy:             &thermopilotv1.SensorHealthPolicy{FrozenAfter: &metav1.Duration{}},
+			previous:           previous,
+			wantUnchangedSince: since.Time,
+		},
+		{
+			name: "compared to the raw reading when filtered",
+			previous: func() thermopilotv1.ThermoPilotStatus {
+				status := *previous.DeepCopy()
+				status.CurrentTemperature = "25.80"
+				status.Filter = &thermopilotv1.FilterStatus{RawTemperature: "26.0", FilteredTemperature: "25.80"}
+				return status
+			}(),
+			wantUnchangedSince: since.Time,
+			wantFrozen:         true,
+		},
+		{
+			name: "compared to the reported reading when calibrated",
+			previous: func() thermopilotv1.ThermoPilotStatus {
+				status := *previous.DeepCopy()
+				status.CurrentTemperature = "24.8"
+				status.Filter = &thermopilotv1.FilterStatus{RawTemperature: "24.8", FilteredTemperature: "24.80"}
+				status.Sensor.ReportedTemperature = "26.0"
+				status.Sensor.CalibrationOffset = "-1.2"
+				return status
+			}(),
+			wantUnchangedSince: since.Time,
+			wantFrozen:         true,
+		},
+		{
+			name:               "low battery at the default threshold",
+			mutate:             func(r *Reading) { r.Battery = ptr.To(20) },
+			wantUnchangedSince: now,
+			wantLowBattery:     true,
+		},
+		{
+			name:               "custom battery threshold",
+			policy:             &thermopilotv1.SensorHealthPolicy{LowBattery: ptr.To[int32](10)},
+			mutate:             func(r *Reading) { r.Battery = ptr.To(20) },
+			wantUnchangedSince: now,
+		},
+		{
+			name:               "battery check disabled",
+			policy:             &thermopilotv1.SensorHealthPolicy{LowBattery: ptr.To[int32](0)},
+			mutate:             func(r *Reading) { r.Battery = ptr.To(0) },
+			wantUnchangedSince: now,
+		},
+		{
+			name:               "no battery reported",
+			mutate:             func(r *Reading) { r.Battery = nil },
+			wantUnchangedSince: now,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := reading
+			if tt.mutate != nil {
+				tt.mutate(&r)
+			}
+			spec := thermopilotv1.ThermoPilotSpec{SensorHealth: tt.policy}
+			got := CheckSensor(spec, tt.previous, r, now)
+			assert.Equal(t, tt.wantUnchangedSince, got.UnchangedSince)
+			assert.Equal(t, tt.wantFrozen, got.Frozen != "", got.Frozen)
+			assert.Equal(t, tt.wantLowBattery, got.LowBattery != "", got.LowBattery)
+			if r.Battery != nil {
+				assert.Equal(t, ptr.To(int32(*r.Battery)), got.Battery)
+			} else {
+				assert.Nil(t, got.Battery)
+			}
+		})
+	}
+}
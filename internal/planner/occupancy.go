@@ -0,0 +1,104 @@
+package planner
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+	"github.com/seipan/thermo-pilot-controller/internal/calendar"
+)
+
+// occupancyHorizon is how far ahead the calendar is searched for the next change
+// of occupancy.
+const occupancyHorizon = 7 * 24 * time.Hour
+
+// Occupancy is the occupancy of the room according to its calendar.
+type Occupancy struct {
+	Occupied bool
+	// Event is the summary of the event in progress
+	Event string
+	// Until is when the occupancy changes next, zero when it does not within the horizon
+	Until time.Time
+}
+
+// OccupancyAt returns the occupancy according to cal at now. Events count from
+// preConditioning before they start, and overlapping or back-to-back events make
+// a single occupied period.
+func OccupancyAt(cal *calendar.Calendar, preConditioning time.Duration, now time.Time) Occupancy {
+	var occupancy Occupancy
+	for _, event := range cal.Between(now, now.Add(occupancyHorizon+preConditioning)) {
+		start := event.Start.Add(-preConditioning)
+		switch {
+		case !occupancy.Occupied && start.After(now):
+			occupancy.Until = start
+			return occupancy
+		case !occupancy.Occupied:
+			occupancy = Occupancy{Occupied: true, Event: event.Summary, Until: event.End}
+		case start.After(occupancy.Until):
+			return occupancy
+		case event.End.After(occupancy.Until):
+			occupancy.Until = event.End
+		}
+	}
+	return occupancy
+}
+
+// occupancyTarget returns the occupancy of in.Calendar at in.Now and the target
+// it calls for: the comfort target, comfort by default, or the setback target.
+func occupancyTarget(in Input, comfort float64) (Occupancy, float64, error) {
+	schedule := in.Spec.Occupancy
+	if in.Calendar == nil {
+		return Occupancy{}, 0, errors.New("the occupancy calendar is not loaded")
+	}
+	var preConditioning time.Duration
+	if schedule.PreConditioning != nil {
+		preConditioning = schedule.PreConditioning.Duration
+	}
+	occupancy := OccupancyAt(in.Calendar, preConditioning, in.Now)
+	if !occupancy.Occupied {
+		setback, err := ParseTemperature(schedule.SetbackTemperature)
+		if err != nil {
+			return Occupancy{}, 0, fmt.Errorf("invalid setback temperature: %w", err)
+		}
+		return occupancy, setback, nil
+	}
+	if schedule.ComfortTemperature != "" {
+		var err error
+		if comfort, err = ParseTemperature(schedule.ComfortTemperature); err != nil {
+			return Occupancy{}, 0, fmt.Errorf("invalid comfort temperature: %w", err)
+		}
+	}
+	return occupancy, comfort, nil
+}
+
+// reason explains the target the occupancy calls for.
+func (o Occupancy) reason(unit Unit, target float64) string {
+	until := "with no event within a week"
+	if !o.Until.IsZero() {
+		until = "until " + o.Until.UTC().Format(time.RFC3339)
+	}
+	if !o.Occupied {
+		return unit.Sprintf("unoccupied %s: setback target %.1f°C", until, target)
+	}
+	event := o.Event
+	if event == "" {
+		event = "busy"
+	}
+	return unit.Sprintf("occupied (%s) %s: comfort target %.1f°C", event, until, target)
+}
+
+// status returns the occupancy to record in status, nil when there is none.
+func (o *Occupancy) status() *thermopilotv1.OccupancyStatus {
+	if o == nil {
+		return nil
+	}
+	status := &thermopilotv1.OccupancyStatus{Occupied: o.Occupied, Event: o.Event}
+	if !o.Until.IsZero() {
+		until := metav1.NewTime(o.Until)
+		status.Until = &until
+	}
+	return status
+}
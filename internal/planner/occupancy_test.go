@@ -0,0 +1,179 @@
+package planner
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+	"github.com/seipan/thermo-pilot-controller/internal/calendar"
+)
+
+// bookings is a meeting room calendar: a daily standup from 9:00 to 9:30 followed
+// by a weekly review on Mondays from 9:30 to 11:00, in Tokyo.
+const bookings = `BEGIN:VCALENDAR
+VERSION:2.0
+BEGIN:VEVENT
+UID:standup
+SUMMARY:Standup
+DTSTART;TZID=Asia/Tokyo:20250106T090000
+DTEND;TZID=Asia/Tokyo:20250106T093000
+RRULE:FREQ=DAILY
+END:VEVENT
+BEGIN:VEVENT
+UID:review
+SUMMARY:Review
+DTSTART;TZID=Asia/Tokyo:20250106T093000
+DTEND;TZID=Asia/Tokyo:20250106T110000
+RRULE:FREQ=WEEKLY;BYDAY=MO
+END:VEVENT
+END:VCALENDAR
+`
+
+func TestOccupancyAt(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	cal, err := calendar.Parse(strings.ReplaceAll(bookings, "\n", "\r\n"), tokyo)
+	require.NoError(t, err)
+	at := func(day, hour, minute int) time.Time { return time.Date(2025, 1, day, hour, minute, 0, 0, tokyo) }
+	tests := []struct {
+		name            string
+		now             time.Time
+		preConditioning time.Duration
+		want            Occupancy
+	}{
+		{
+			name: "before the first event",
+			now:  at(13, 7, 0),
+			want: Occupancy{Until: at(13, 9, 0)},
+		},
+		{
+			name: "back-to-back events make one occupied period",
+			now:  at(13, 9, 10),
+			want: Occupancy{Occupied: true, Event: "Standup", Until: at(13, 11, 0)},
+		},
+		{
+			name: "single event",
+			now:  at(14, 9, 10),
+			want: Occupancy{Occupied: true, Event: "Standup", Until: at(14, 9, 30)},
+		},
+		{
+			name:            "pre-conditioning before the event",
+			now:             at(14, 8, 40),
+			preConditioning: 30 * time.Minute,
+			want:            Occupancy{Occupied: true, Event: "Standup", Until: at(14, 9, 30)},
+		},
+		{
+			name: "after the events",
+			now:  at(14, 12, 0),
+			want: Occupancy{Until: at(15, 9, 0)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := OccupancyAt(cal, tt.preConditioning, tt.now)
+			assert.Equal(t, tt.want.Occupied, got.Occupied)
+			assert.Equal(t, tt.want.Event, got.Event)
+			assert.True(t, tt.want.Until.Equal(got.Until), "until %s, want %s", got.Until, tt.want.Until)
+		})
+	}
+}
+
+func TestTargetAt_Occupancy(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	cal, err := calendar.Parse(bookings, tokyo)
+	require.NoError(t, err)
+	occupied, unoccupied := time.Date(2025, 1, 14, 9, 10, 0, 0, tokyo), time.Date(2025, 1, 14, 12, 0, 0, 0, tokyo)
+	spec := thermopilotv1.ThermoPilotSpec{TargetTemperature: "24.0", Mode: ModeCool, Occupancy: &thermopilotv1.OccupancySchedule{
+		CalendarRef:        thermopilotv1.ConfigMapKeyReference{Name: "room-calendar"},
+		SetbackTemperature: "28.0",
+	}}
+	withComfort := *spec.DeepCopy()
+	withComfort.Occupancy.ComfortTemperature = "23.0"
+	withOverride := *spec.DeepCopy()
+	withOverride.Override = &thermopilotv1.Override{TargetTemperature: "26.0", ExpiresAt: metav1.NewTime(occupied.Add(time.Hour))}
+	tests := []struct {
+		name           string
+		spec           thermopilotv1.ThermoPilotSpec
+		now            time.Time
+		want           float64
+		wantNextChange time.Time
+		wantOccupied   *bool
+	}{
+		{
+			name:           "comfort defaults to the target",
+			spec:           spec,
+			now:            occupied,
+			want:           24.0,
+			wantNextChange: time.Date(2025, 1, 14, 9, 30, 0, 0, tokyo),
+			wantOccupied:   ptr.To(true),
+		},
+		{
+			name:           "comfort target",
+			spec:           withComfort,
+			now:            occupied,
+			want:           23.0,
+			wantNextChange: time.Date(2025, 1, 14, 9, 30, 0, 0, tokyo),
+			wantOccupied:   ptr.To(true),
+		},
+		{
+			name:           "setback target",
+			spec:           spec,
+			now:            unoccupied,
+			want:           28.0,
+			wantNextChange: time.Date(2025, 1, 15, 9, 0, 0, 0, tokyo),
+			wantOccupied:   ptr.To(false),
+		},
+		{
+			name: "override takes precedence",
+			spec: withOverride,
+			now:  occupied,
+			want: 26.0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TargetAt(Input{Now: tt.now, Spec: tt.spec, Calendar: cal})
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, got.Temperature, 1e-9)
+			assert.True(t, tt.wantNextChange.Equal(got.NextChange), "next change %s", got.NextChange)
+			if tt.wantOccupied == nil {
+				assert.Nil(t, got.Occupancy)
+			} else {
+				require.NotNil(t, got.Occupancy)
+				assert.Equal(t, *tt.wantOccupied, got.Occupancy.Occupied)
+			}
+		})
+	}
+
+	_, err = TargetAt(Input{Now: occupied, Spec: spec})
+	assert.Error(t, err, "the calendar is required")
+}
+
+func TestDecide_RecordsOccupancy(t *testing.T) {
+	cal, err := calendar.Parse(bookings, time.UTC)
+	require.NoError(t, err)
+	now := time.Date(2025, 1, 14, 0, 10, 0, 0, time.UTC) // 9:10 in Tokyo
+	spec := thermopilotv1.ThermoPilotSpec{TargetTemperature: "24.0", Threshold: "1.0", Mode: ModeCool, Occupancy: &thermopilotv1.OccupancySchedule{
+		CalendarRef:        thermopilotv1.ConfigMapKeyReference{Name: "room-calendar"},
+		SetbackTemperature: "28.0",
+	}}
+	plan, err := Decide(Input{Now: now, CurrentTemperature: 26.0, Spec: spec, Calendar: cal})
+	require.NoError(t, err)
+	assert.Equal(t, ActionCooling, plan.Action)
+	assert.Contains(t, plan.Reasons, "occupied (Standup) until 2025-01-14T00:30:00Z: comfort target 24.0°C")
+
+	var status thermopilotv1.ThermoPilotStatus
+	plan.Record(&status)
+	require.NotNil(t, status.Occupancy)
+	assert.True(t, status.Occupancy.Occupied)
+	assert.Equal(t, "Standup", status.Occupancy.Event)
+	require.NotNil(t, status.Occupancy.Until)
+	assert.Equal(t, now.Add(20*time.Minute), status.Occupancy.Until.UTC())
+}
@@ -0,0 +1,285 @@
+// Package planner contains the temperature control decision logic. It is pure:
+// given the readings, the ThermoPilot spec and the previous status it returns a
+// Plan describing what should be sent to the air conditioners and why, without
+// talking to the SwitchBot API or the Kubernetes API.
+package planner
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+	"github.com/seipan/thermo-pilot-controller/internal/calendar"
+)
+
+const (
+	ModeCool = "cool"
+	ModeHeat = "heat"
+)
+
+const (
+	PowerOn  = "on"
+	PowerOff = "off"
+)
+
+const (
+	ActionNone       = "none"
+	ActionCooling    = "cooling"
+	ActionHeating    = "heating"
+	ActionAdjustUp   = "adjusting up (too cold)"
+	ActionAdjustDown = "adjusting down (too warm)"
+)
+
+const (
+	defaultThreshold = 1.0
+	// defaultAggressiveOffset is how far beyond the target the AC setpoint is
+	// driven when the room overshoots in the direction opposite to the mode.
+	defaultAggressiveOffset = 3.0
+)
+
+// Input is everything the planner needs to make a decision.
+type Input struct {
+	// Now is the time the decision is made at
+	Now time.Time
+	// CurrentTemperature is the room temperature read from the sensor
+	CurrentTemperature float64
+	// Spec is the desired state of the ThermoPilot
+	Spec thermopilotv1.ThermoPilotSpec
+	// Previous is the status recorded by the previous reconcile
+	Previous thermopilotv1.ThermoPilotStatus
+	// AirConditionerIDs are the devices to command, if already known
+	AirConditionerIDs []string
+	// DryRun forces dry-run regardless of the spec
+	DryRun bool
+	// Reasons explain how the reading was obtained, such as filtering, and are
+	// recorded before the reasons of the decision
+	Reasons []string
+	// AwaySwitches are the ClusterAwaySwitches selecting the ThermoPilot
+	AwaySwitches []thermopilotv1.ClusterAwaySwitch
+	// Calendar is the occupancy calendar of the spec, nil without an occupancy schedule
+	Calendar *calendar.Calendar
+}
+
+// Command is the command planned for a single air conditioner.
+type Command struct {
+	DeviceID string
+	// Setpoint is the one sent, fitted to the capabilities of the air conditioner
+	Setpoint float64
+	Mode     string
+	Power    string
+	FanSpeed string
+	// Offset is the calibration offset added to the setpoint of the plan
+	Offset float64
+	// Profile is the capability profile the command was fitted to, if any
+	Profile string
+}
+
+// Plan is the outcome of a decision.
+type Plan struct {
+	Time               time.Time
+	CurrentTemperature float64
+	TargetTemperature  float64
+	UpperThreshold     float64
+	LowerThreshold     float64
+	Mode               string
+	Action             string
+	Setpoint           float64
+	Power              string
+	Suspended          bool
+	DryRun             bool
+	Override           *thermopilotv1.Override
+	// Stale is set when no reading could be used and the plan is the safe action
+	Stale bool
+	// SafetyLimit is set when the room is outside the safety limits and the plan
+	// enforces them
+	SafetyLimit bool
+	// Blocked is the action withheld by equipment protection until BlockedUntil
+	Blocked      string
+	BlockedUntil time.Time
+	// Equipment is the compressor cycles to record once the commands are sent, nil
+	// without equipment protection
+	Equipment *thermopilotv1.EquipmentStatus
+	// Target is the effective target to record, nil when it could not be computed
+	Target *Target
+	// Unit is the unit of every temperature of the plan
+	Unit Unit
+	// Profiles are the capability profiles the commands are fitted to
+	Profiles []thermopilotv1.CapabilityProfile
+	// Offsets are the calibration offsets of the air conditioners
+	Offsets  []thermopilotv1.CalibrationOffset
+	Commands []Command
+	Reasons  []string
+}
+
+// NeedsAction reports whether the room is outside the comfort band.
+func (p Plan) NeedsAction() bool {
+	return p.Action != ActionNone
+}
+
+// Actuate reports whether commands should actually be sent.
+func (p Plan) Actuate() bool {
+	return p.NeedsAction() && !p.Suspended && !p.DryRun
+}
+
+// WithDevices returns a copy of the plan with one command per air conditioner,
+// calibrated and fitted to its capability profile. An air conditioner whose profile does not
+// support the mode is left out, and the reason recorded.
+func (p Plan) WithDevices(deviceIDs []string) Plan {
+	p.Commands = nil
+	if !p.NeedsAction() {
+		return p
+	}
+	p.Reasons = slices.Clone(p.Reasons)
+	for _, deviceID := range deviceIDs {
+		command, calibrated := CalibrateCommand(p.Unit, p.Offsets, Command{
+			DeviceID: deviceID,
+			Setpoint: p.Setpoint,
+			Mode:     p.Mode,
+			Power:    p.Power,
+		})
+		if calibrated != "" {
+			p.reason("%s", calibrated)
+		}
+		command, why, err := FitCommand(p.Unit, p.Profiles, command)
+		if err != nil {
+			p.reason("%s skipped: %v", deviceID, err)
+			continue
+		}
+		if why != "" {
+			p.reason("%s", why)
+		}
+		p.Commands = append(p.Commands, command)
+	}
+	return p
+}
+
+// Decide computes the plan for in. Safety limits are checked first and take
+// precedence over the mode, the override, away periods and suspend.
+func Decide(in Input) (Plan, error) {
+	if plan, ok, err := decideSafety(in); ok || err != nil {
+		return plan, err
+	}
+	effective, err := TargetAt(in)
+	if err != nil {
+		return Plan{}, err
+	}
+	if effective.Away != nil && effective.Away.Off {
+		return decideAwayOff(in, effective, false), nil
+	}
+	target, mode := effective.Temperature, effective.Mode
+	band, bandReasons, err := ParseBand(in.Spec)
+	if err != nil {
+		return Plan{}, err
+	}
+	reasons := append(append(append([]string(nil), in.Reasons...), effective.Reasons...), bandReasons...)
+
+	plan := Plan{
+		Time:               in.Now,
+		CurrentTemperature: in.CurrentTemperature,
+		TargetTemperature:  target,
+		UpperThreshold:     band.Upper,
+		LowerThreshold:     band.Lower,
+		Mode:               mode,
+		Action:             ActionNone,
+		Setpoint:           target,
+		Power:              PowerOn,
+		Suspended:          in.Spec.Suspend,
+		DryRun:             in.DryRun || in.Spec.DryRun,
+		Override:           ActiveOverride(in.Spec, in.Now),
+		Target:             &effective,
+		Unit:               UnitOf(in.Spec),
+		Profiles:           in.Spec.CapabilityProfiles,
+		Offsets:            airConditionerOffsets(in.Spec),
+		Reasons:            reasons,
+	}
+	if plan.Override != nil {
+		plan.reason("manual override active until %s", plan.Override.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+
+	diff := in.CurrentTemperature - target
+	switch mode {
+	case ModeCool:
+		switch {
+		case diff > band.Upper:
+			plan.Action = ActionCooling
+		case diff < -band.Lower:
+			plan.Action = ActionAdjustUp
+			plan.Setpoint = target + band.CoolOffset
+		}
+	case ModeHeat:
+		switch {
+		case diff < -band.Lower:
+			plan.Action = ActionHeating
+		case diff > band.Upper:
+			plan.Action = ActionAdjustDown
+			plan.Setpoint = target - band.HeatOffset
+		}
+	default:
+		return Plan{}, fmt.Errorf("unsupported mode: %s", mode)
+	}
+
+	switch {
+	case diff > band.Upper:
+		plan.reason("current %.1f°C is %.1f°C above target %.1f°C (upper threshold %.1f°C) in %s mode",
+			in.CurrentTemperature, diff, target, band.Upper, mode)
+	case diff < -band.Lower:
+		plan.reason("current %.1f°C is %.1f°C below target %.1f°C (lower threshold %.1f°C) in %s mode",
+			in.CurrentTemperature, -diff, target, band.Lower, mode)
+	case band.Upper == band.Lower:
+		plan.reason("current %.1f°C is within %.1f°C of target %.1f°C", in.CurrentTemperature, band.Upper, target)
+	default:
+		plan.reason("current %.1f°C is within -%.1f/+%.1f°C of target %.1f°C", in.CurrentTemperature, band.Lower, band.Upper, target)
+	}
+	plan.resumeAfterAwayOff(in.Previous.LastCommand)
+	if plan.NeedsAction() {
+		plan.reason("%s: setpoint %.1f°C, mode %s", plan.Action, plan.Setpoint, mode)
+	}
+	plan.protectEquipment(in.Spec, in.Previous.Equipment)
+	plan.trackEquipment(in.Spec, in.Previous.Equipment)
+	if plan.Suspended {
+		plan.reason("control is suspended, no command is sent")
+	}
+	if plan.DryRun {
+		plan.reason("dry run, commands are recorded but not sent")
+	}
+
+	return plan.WithDevices(in.AirConditionerIDs), nil
+}
+
+func (p *Plan) reason(format string, args ...any) {
+	p.Reasons = append(p.Reasons, p.Unit.Sprintf(format, args...))
+}
+
+// ActiveOverride returns the manual override of spec if it has not expired at now.
+func ActiveOverride(spec thermopilotv1.ThermoPilotSpec, now time.Time) *thermopilotv1.Override {
+	override := spec.Override
+	if override == nil || !now.Before(override.ExpiresAt.Time) {
+		return nil
+	}
+	return override
+}
+
+// EffectiveSetpoint returns the target temperature and mode in force at now,
+// taking an active override into account.
+func EffectiveSetpoint(spec thermopilotv1.ThermoPilotSpec, now time.Time) (string, string) {
+	target, mode := spec.TargetTemperature, spec.Mode
+	if override := ActiveOverride(spec, now); override != nil {
+		if override.TargetTemperature != "" {
+			target = override.TargetTemperature
+		}
+		if override.Mode != "" {
+			mode = override.Mode
+		}
+	}
+	return target, mode
+}
+
+func ParseTemperature(temp string) (float64, error) {
+	value, err := strconv.ParseFloat(temp, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid temperature format: %s", temp)
+	}
+	return value, nil
+}
@@ -0,0 +1,266 @@
+package planner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+func TestDecide(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		current      float64
+		spec         thermopilotv1.ThermoPilotSpec
+		dryRun       bool
+		wantAction   string
+		wantSetpoint float64
+		wantMode     string
+		wantActuate  bool
+		wantErr      bool
+	}{
+		{
+			name:         "cool - too warm",
+			current:      26.5,
+			spec:         thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", Threshold: "1.0", Mode: ModeCool},
+			wantAction:   ActionCooling,
+			wantSetpoint: 25.0,
+			wantMode:     ModeCool,
+			wantActuate:  true,
+		},
+		{
+			name:         "cool - too cold",
+			current:      23.5,
+			spec:         thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", Threshold: "1.0", Mode: ModeCool},
+			wantAction:   ActionAdjustUp,
+			wantSetpoint: 28.0,
+			wantMode:     ModeCool,
+			wantActuate:  true,
+		},
+		{
+			name:         "cool - within threshold",
+			current:      25.8,
+			spec:         thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", Threshold: "1.0", Mode: ModeCool},
+			wantAction:   ActionNone,
+			wantSetpoint: 25.0,
+			wantMode:     ModeCool,
+		},
+		{
+			name:         "cool - exactly at threshold",
+			current:      26.0,
+			spec:         thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", Threshold: "1.0", Mode: ModeCool},
+			wantAction:   ActionNone,
+			wantSetpoint: 25.0,
+			wantMode:     ModeCool,
+		},
+		{
+			name:         "heat - too cold",
+			current:      19.0,
+			spec:         thermopilotv1.ThermoPilotSpec{TargetTemperature: "21.0", Threshold: "1.0", Mode: ModeHeat},
+			wantAction:   ActionHeating,
+			wantSetpoint: 21.0,
+			wantMode:     ModeHeat,
+			wantActuate:  true,
+		},
+		{
+			name:         "heat - too warm",
+			current:      23.0,
+			spec:         thermopilotv1.ThermoPilotSpec{TargetTemperature: "21.0", Threshold: "1.0", Mode: ModeHeat},
+			wantAction:   ActionAdjustDown,
+			wantSetpoint: 18.0,
+			wantMode:     ModeHeat,
+			wantActuate:  true,
+		},
+		{
+			name:         "heat - within threshold",
+			current:      20.5,
+			spec:         thermopilotv1.ThermoPilotSpec{TargetTemperature: "21.0", Threshold: "1.0", Mode: ModeHeat},
+			wantAction:   ActionNone,
+			wantSetpoint: 21.0,
+			wantMode:     ModeHeat,
+		},
+		{
+			name:         "default threshold",
+			current:      26.5,
+			spec:         thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", Mode: ModeCool},
+			wantAction:   ActionCooling,
+			wantSetpoint: 25.0,
+			wantMode:     ModeCool,
+			wantActuate:  true,
+		},
+		{
+			name:         "suspended",
+			current:      30.0,
+			spec:         thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", Mode: ModeCool, Suspend: true},
+			wantAction:   ActionCooling,
+			wantSetpoint: 25.0,
+			wantMode:     ModeCool,
+		},
+		{
+			name:         "dry run from spec",
+			current:      30.0,
+			spec:         thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", Mode: ModeCool, DryRun: true},
+			wantAction:   ActionCooling,
+			wantSetpoint: 25.0,
+			wantMode:     ModeCool,
+		},
+		{
+			name:         "dry run from manager",
+			current:      30.0,
+			spec:         thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", Mode: ModeCool},
+			dryRun:       true,
+			wantAction:   ActionCooling,
+			wantSetpoint: 25.0,
+			wantMode:     ModeCool,
+		},
+		{
+			name:    "active override",
+			current: 22.0,
+			spec: thermopilotv1.ThermoPilotSpec{
+				TargetTemperature: "25.0",
+				Mode:              ModeCool,
+				Override: &thermopilotv1.Override{
+					TargetTemperature: "24.0",
+					Mode:              ModeHeat,
+					ExpiresAt:         metav1.NewTime(now.Add(time.Hour)),
+				},
+			},
+			wantAction:   ActionHeating,
+			wantSetpoint: 24.0,
+			wantMode:     ModeHeat,
+			wantActuate:  true,
+		},
+		{
+			name:    "expired override",
+			current: 22.0,
+			spec: thermopilotv1.ThermoPilotSpec{
+				TargetTemperature: "25.0",
+				Mode:              ModeCool,
+				Override: &thermopilotv1.Override{
+					TargetTemperature: "24.0",
+					Mode:              ModeHeat,
+					ExpiresAt:         metav1.NewTime(now.Add(-time.Hour)),
+				},
+			},
+			wantAction:   ActionAdjustUp,
+			wantSetpoint: 28.0,
+			wantMode:     ModeCool,
+			wantActuate:  true,
+		},
+		{
+			name:    "invalid target",
+			current: 22.0,
+			spec:    thermopilotv1.ThermoPilotSpec{TargetTemperature: "warm", Mode: ModeCool},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported mode",
+			current: 22.0,
+			spec:    thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", Mode: "dry"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := Decide(Input{
+				Now:                now,
+				CurrentTemperature: tt.current,
+				Spec:               tt.spec,
+				AirConditionerIDs:  []string{"ac1", "ac2"},
+				DryRun:             tt.dryRun,
+			})
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAction, plan.Action)
+			assert.InDelta(t, tt.wantSetpoint, plan.Setpoint, 0.001)
+			assert.Equal(t, tt.wantMode, plan.Mode)
+			assert.Equal(t, tt.wantActuate, plan.Actuate())
+			assert.NotEmpty(t, plan.Reasons)
+			if plan.NeedsAction() {
+				require.Len(t, plan.Commands, 2)
+				assert.Equal(t, "ac1", plan.Commands[0].DeviceID)
+				assert.InDelta(t, tt.wantSetpoint, plan.Commands[0].Setpoint, 0.001)
+			} else {
+				assert.Empty(t, plan.Commands)
+			}
+		})
+	}
+}
+
+func TestPlan_WithDevices(t *testing.T) {
+	plan := Plan{Action: ActionCooling, Setpoint: 24.0, Mode: ModeCool, Power: PowerOn}
+	got := plan.WithDevices([]string{"ac1"})
+	require.Len(t, got.Commands, 1)
+	assert.Equal(t, Command{DeviceID: "ac1", Setpoint: 24.0, Mode: ModeCool, Power: PowerOn, FanSpeed: FanAuto}, got.Commands[0])
+	assert.Empty(t, plan.Commands)
+}
+
+func TestPlan_Record(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		plan        Plan
+		wantCommand bool
+	}{
+		{
+			name:        "command due",
+			plan:        Plan{Time: now, CurrentTemperature: 27.04, Action: ActionCooling, Setpoint: 25, Commands: []Command{{DeviceID: "ac1", Setpoint: 25}}},
+			wantCommand: true,
+		},
+		{
+			name: "within threshold",
+			plan: Plan{Time: now, CurrentTemperature: 25.3, Action: ActionNone, Setpoint: 25},
+		},
+		{
+			name: "suspended",
+			plan: Plan{Time: now, CurrentTemperature: 27.0, Action: ActionCooling, Setpoint: 25, Suspended: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var status thermopilotv1.ThermoPilotStatus
+			tt.plan.Record(&status)
+			assert.Equal(t, FormatTemperature(tt.plan.CurrentTemperature), status.CurrentTemperature)
+			require.NotNil(t, status.LastDecision)
+			assert.Equal(t, tt.plan.Action, status.LastDecision.Action)
+			assert.Len(t, status.LastDecision.Commands, len(tt.plan.Commands))
+			if tt.wantCommand {
+				require.NotNil(t, status.LastCommand)
+				assert.Equal(t, []string{"ac1"}, status.LastCommand.AirConditionerIDs)
+				assert.Equal(t, "25.0", status.LastCommand.Setpoint)
+			} else {
+				assert.Nil(t, status.LastCommand)
+			}
+		})
+	}
+}
+
+func TestRequeueAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		override *thermopilotv1.Override
+		want     time.Duration
+	}{
+		{name: "no override", want: DefaultRequeueInterval},
+		{name: "override expiring soon", override: &thermopilotv1.Override{ExpiresAt: metav1.NewTime(now.Add(time.Minute))}, want: time.Minute},
+		{name: "override expiring later", override: &thermopilotv1.Override{ExpiresAt: metav1.NewTime(now.Add(time.Hour))}, want: DefaultRequeueInterval},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := thermopilotv1.ThermoPilotSpec{Override: tt.override}
+			assert.Equal(t, tt.want, RequeueAfter(spec, now))
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package planner
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+// DefaultRequeueInterval is how often a ThermoPilot is re-evaluated.
+const DefaultRequeueInterval = 5 * time.Minute
+
+// RequeueAfter returns the delay until the next decision, waking up early when
+// an active override expires before the regular interval.
+func RequeueAfter(spec thermopilotv1.ThermoPilotSpec, now time.Time) time.Duration {
+	interval := DefaultRequeueInterval
+	if override := ActiveOverride(spec, now); override != nil {
+		if untilExpiry := override.ExpiresAt.Sub(now); untilExpiry < interval {
+			interval = untilExpiry
+		}
+	}
+	return interval
+}
+
+// Record stores the outcome of the plan in status: the reading, the decision
+// record and, when commands were due, the last command.
+func (p Plan) Record(status *thermopilotv1.ThermoPilotStatus) {
+	if !p.Stale {
+		status.CurrentTemperature = FormatTemperature(p.CurrentTemperature)
+	}
+	status.LastDecision = p.decision()
+	if p.NeedsAction() && !p.Suspended {
+		status.LastCommand = p.command()
+	}
+	status.Equipment = p.Equipment
+	if p.Target != nil {
+		status.EffectiveTarget = p.Target.status()
+		status.Away = p.Target.Away.status()
+		status.Occupancy = p.Target.Occupancy.status()
+	}
+}
+
+// RecordUnsent undoes in status what Record assumed about commands that could not
+// be sent to any air conditioner: the last command and the equipment state are
+// restored from previous, so that the next decision sends them again.
+func RecordUnsent(status *thermopilotv1.ThermoPilotStatus, previous *thermopilotv1.ThermoPilotStatus) {
+	status.LastCommand = previous.LastCommand.DeepCopy()
+	status.Equipment = previous.Equipment.DeepCopy()
+}
+
+// decision converts the plan into the decision record persisted in status.
+func (p Plan) decision() *thermopilotv1.Decision {
+	decision := &thermopilotv1.Decision{
+		Time:              metav1.Time{Time: p.Time},
+		TargetTemperature: FormatTemperature(p.TargetTemperature),
+		Mode:              p.Mode,
+		Action:            p.Action,
+		Setpoint:          formatSetpoint(p.Setpoint, p.Power),
+		Power:             p.Power,
+		Suspended:         p.Suspended,
+		DryRun:            p.DryRun,
+		BlockedAction:     p.Blocked,
+		Reasons:           p.Reasons,
+	}
+	if !p.BlockedUntil.IsZero() {
+		decision.BlockedUntil = &metav1.Time{Time: p.BlockedUntil}
+	}
+	if !p.Stale {
+		decision.CurrentTemperature = FormatTemperature(p.CurrentTemperature)
+	}
+	for _, command := range p.Commands {
+		deviceCommand := thermopilotv1.DeviceCommand{
+			DeviceID: command.DeviceID,
+			Setpoint: formatSetpoint(command.Setpoint, command.Power),
+			Mode:     command.Mode,
+			Power:    command.Power,
+			FanSpeed: command.FanSpeed,
+			Profile:  command.Profile,
+		}
+		if command.Offset != 0 {
+			deviceCommand.CalibrationOffset = FormatOffset(command.Offset)
+		}
+		decision.Commands = append(decision.Commands, deviceCommand)
+	}
+	return decision
+}
+
+// command records the commands of the plan as the last command sent. The setpoint
+// is the one sent when every air conditioner was sent the same, and the one
+// decided on otherwise.
+func (p Plan) command() *thermopilotv1.CommandStatus {
+	command := &thermopilotv1.CommandStatus{
+		Time:     metav1.Time{Time: p.Time},
+		Action:   p.Action,
+		Setpoint: formatSetpoint(p.Setpoint, p.Power),
+		Mode:     p.Mode,
+		DryRun:   p.DryRun,
+	}
+	for _, c := range p.Commands {
+		command.AirConditionerIDs = append(command.AirConditionerIDs, c.DeviceID)
+	}
+	if len(p.Commands) > 0 && p.Power != PowerOff {
+		sent := formatSetpoint(p.Commands[0].Setpoint, p.Power)
+		for _, c := range p.Commands[1:] {
+			if formatSetpoint(c.Setpoint, p.Power) != sent {
+				sent = command.Setpoint
+			}
+		}
+		if sent != command.Setpoint {
+			command.RequestedSetpoint, command.Setpoint = command.Setpoint, sent
+		}
+	}
+	return command
+}
+
+// formatSetpoint formats the setpoint of a command, which has none when it turns
+// the air conditioner off.
+func formatSetpoint(setpoint float64, power string) string {
+	if power == PowerOff {
+		return ""
+	}
+	return FormatTemperature(setpoint)
+}
+
+func FormatTemperature(temp float64) string {
+	return fmt.Sprintf("%.1f", temp)
+}
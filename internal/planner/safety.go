@@ -0,0 +1,102 @@
+package planner
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	ActionFreezeProtection = "freeze protection"
+	ActionOverheatGuard    = "overheat guard"
+)
+
+// safetyMargin is how far inside a safety limit the default safety setpoint is.
+const safetyMargin = 3.0
+
+// IsSafetyAction reports whether action was forced by a safety limit.
+func IsSafetyAction(action string) bool {
+	return action == ActionFreezeProtection || action == ActionOverheatGuard
+}
+
+// decideSafety returns the plan forced by the safety limits of in.Spec when the
+// current temperature is outside them. The plan ignores the mode, the override and
+// suspend; only dry run is honoured. ok is false when the room is within the limits.
+func decideSafety(in Input) (plan Plan, ok bool, err error) {
+	safety := in.Spec.Safety
+	if safety == nil {
+		return Plan{}, false, nil
+	}
+
+	parse := func(value, name string) (float64, bool, error) {
+		if value == "" {
+			return 0, false, nil
+		}
+		parsed, err := ParseTemperature(value)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid safety %s: %w", name, err)
+		}
+		return parsed, true, nil
+	}
+	minimum, hasMinimum, err := parse(safety.MinTemperature, "minimum")
+	if err != nil {
+		return Plan{}, false, err
+	}
+	maximum, hasMaximum, err := parse(safety.MaxTemperature, "maximum")
+	if err != nil {
+		return Plan{}, false, err
+	}
+
+	var limit, setpoint float64
+	var mode, action, setpointSpec string
+	switch {
+	case hasMinimum && in.CurrentTemperature < minimum:
+		limit, mode, action, setpoint, setpointSpec = minimum, ModeHeat, ActionFreezeProtection, minimum+safetyMargin, safety.HeatSetpoint
+	case hasMaximum && in.CurrentTemperature > maximum:
+		limit, mode, action, setpoint, setpointSpec = maximum, ModeCool, ActionOverheatGuard, maximum-safetyMargin, safety.CoolSetpoint
+	default:
+		return Plan{}, false, nil
+	}
+	if value, ok, err := parse(setpointSpec, mode+" setpoint"); err != nil {
+		return Plan{}, false, err
+	} else if ok {
+		setpoint = value
+	}
+
+	plan = Plan{
+		Time:               in.Now,
+		CurrentTemperature: in.CurrentTemperature,
+		UpperThreshold:     defaultThreshold,
+		LowerThreshold:     defaultThreshold,
+		Mode:               mode,
+		Action:             action,
+		Setpoint:           setpoint,
+		Power:              PowerOn,
+		DryRun:             in.DryRun || in.Spec.DryRun,
+		Override:           ActiveOverride(in.Spec, in.Now),
+		SafetyLimit:        true,
+		Unit:               UnitOf(in.Spec),
+		Profiles:           in.Spec.CapabilityProfiles,
+		Offsets:            airConditionerOffsets(in.Spec),
+		Reasons:            append([]string(nil), in.Reasons...),
+	}
+	if effective, err := TargetAt(in); err == nil {
+		plan.TargetTemperature, plan.Target = effective.Temperature, &effective
+	}
+	if mode == ModeHeat {
+		plan.reason("current %.1f°C is below the safety minimum %.1f°C", in.CurrentTemperature, limit)
+	} else {
+		plan.reason("current %.1f°C is above the safety maximum %.1f°C", in.CurrentTemperature, limit)
+	}
+	plan.reason("%s: setpoint %.1f°C, mode %s, overriding the configured mode", action, setpoint, mode)
+	if plan.Override != nil {
+		plan.reason("manual override until %s is ignored", plan.Override.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	if in.Spec.Suspend {
+		plan.reason("control is suspended, but safety limits are still enforced")
+	}
+	plan.trackEquipment(in.Spec, in.Previous.Equipment)
+	if plan.DryRun {
+		plan.reason("dry run, commands are recorded but not sent")
+	}
+	return plan.WithDevices(in.AirConditionerIDs), true, nil
+}
@@ -0,0 +1,128 @@
+package planner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+func TestDecide_Safety(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	limits := &thermopilotv1.SafetyLimits{MinTemperature: "10", MaxTemperature: "32"}
+	tests := []struct {
+		name         string
+		current      float64
+		spec         thermopilotv1.ThermoPilotSpec
+		dryRun       bool
+		wantAction   string
+		wantSetpoint float64
+		wantMode     string
+		wantSafety   bool
+		wantActuate  bool
+		wantErr      bool
+	}{
+		{
+			name:         "freeze protection heats in cool mode",
+			current:      9.5,
+			spec:         thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", Mode: ModeCool, Safety: limits},
+			wantAction:   ActionFreezeProtection,
+			wantSetpoint: 13.0,
+			wantMode:     ModeHeat,
+			wantSafety:   true,
+			wantActuate:  true,
+		},
+		{
+			name:         "overheat guard cools in heat mode",
+			current:      33.0,
+			spec:         thermopilotv1.ThermoPilotSpec{TargetTemperature: "22.0", Mode: ModeHeat, Safety: limits},
+			wantAction:   ActionOverheatGuard,
+			wantSetpoint: 29.0,
+			wantMode:     ModeCool,
+			wantSafety:   true,
+			wantActuate:  true,
+		},
+		{
+			name:    "custom heat setpoint while suspended",
+			current: 4.0,
+			spec: thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", Mode: ModeCool, Suspend: true,
+				Safety: &thermopilotv1.SafetyLimits{MinTemperature: "5", HeatSetpoint: "12"}},
+			wantAction:   ActionFreezeProtection,
+			wantSetpoint: 12.0,
+			wantMode:     ModeHeat,
+			wantSafety:   true,
+			wantActuate:  true,
+		},
+		{
+			name:    "override is ignored",
+			current: 35.0,
+			spec: thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", Mode: ModeCool, Safety: limits,
+				Override: &thermopilotv1.Override{Mode: ModeHeat, TargetTemperature: "30", ExpiresAt: metav1.NewTime(now.Add(time.Hour))}},
+			wantAction:   ActionOverheatGuard,
+			wantSetpoint: 29.0,
+			wantMode:     ModeCool,
+			wantSafety:   true,
+			wantActuate:  true,
+		},
+		{
+			name:         "dry run is honoured",
+			current:      9.0,
+			spec:         thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", Mode: ModeCool, Safety: limits},
+			dryRun:       true,
+			wantAction:   ActionFreezeProtection,
+			wantSetpoint: 13.0,
+			wantMode:     ModeHeat,
+			wantSafety:   true,
+		},
+		{
+			name:         "at the limit falls back to normal control",
+			current:      10.0,
+			spec:         thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", Mode: ModeCool, Safety: limits},
+			wantAction:   ActionAdjustUp,
+			wantSetpoint: 28.0,
+			wantMode:     ModeCool,
+			wantActuate:  true,
+		},
+		{
+			name:         "only a maximum",
+			current:      5.0,
+			spec:         thermopilotv1.ThermoPilotSpec{TargetTemperature: "5.0", Mode: ModeHeat, Safety: &thermopilotv1.SafetyLimits{MaxTemperature: "30"}},
+			wantAction:   ActionNone,
+			wantSetpoint: 5.0,
+			wantMode:     ModeHeat,
+		},
+		{
+			name:    "invalid limit",
+			current: 20.0,
+			spec:    thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", Mode: ModeCool, Safety: &thermopilotv1.SafetyLimits{MinTemperature: "cold"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := Decide(Input{
+				Now:                now,
+				CurrentTemperature: tt.current,
+				Spec:               tt.spec,
+				DryRun:             tt.dryRun,
+				AirConditionerIDs:  []string{"ac1"},
+			})
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAction, plan.Action)
+			assert.InDelta(t, tt.wantSetpoint, plan.Setpoint, 1e-9)
+			assert.Equal(t, tt.wantMode, plan.Mode)
+			assert.Equal(t, tt.wantSafety, plan.SafetyLimit)
+			assert.Equal(t, tt.wantSafety, IsSafetyAction(plan.Action))
+			assert.Equal(t, tt.wantActuate, plan.Actuate())
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package planner
+
+import (
+	"fmt"
+	"time"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+// Sources of the reading a decision is based on, as reported in status.sensor.source.
+const (
+	SourcePrimary  = "primary"
+	SourceFallback = "fallback"
+	SourceHeld     = "held"
+	SourceNone     = "none"
+)
+
+// Safe actions of a sensor failure policy.
+const (
+	SafeActionKeep     = "keep"
+	SafeActionOff      = "off"
+	SafeActionSetpoint = "safeSetpoint"
+)
+
+const (
+	ActionSafeOff      = "safe off (sensors stale)"
+	ActionSafeSetpoint = "safe setpoint (sensors stale)"
+)
+
+const (
+	// DefaultHoldLastReading is how long the last known reading is used when the
+	// policy does not say.
+	DefaultHoldLastReading = 10 * time.Minute
+	// SensorRetryInterval is how often the sensors are retried while none can be read.
+	SensorRetryInterval = time.Minute
+)
+
+// HeldReading returns the last known reading of previous if the policy of spec
+// allows using it at now, no sensor having been readable since failingSince.
+func HeldReading(spec thermopilotv1.ThermoPilotSpec, previous thermopilotv1.ThermoPilotStatus, failingSince, now time.Time) (float64, bool) {
+	policy := spec.SensorFailurePolicy
+	if policy == nil || previous.CurrentTemperature == "" {
+		return 0, false
+	}
+	hold := DefaultHoldLastReading
+	if policy.HoldLastReading != nil {
+		hold = policy.HoldLastReading.Duration
+	}
+	if now.Sub(failingSince) >= hold {
+		return 0, false
+	}
+	temperature, err := ParseTemperature(previous.CurrentTemperature)
+	if err != nil {
+		return 0, false
+	}
+	return temperature, true
+}
+
+// DecideStale computes the plan once no reading can be used: the safe action of
+// the sensor failure policy, sent once per failure. in.CurrentTemperature is ignored.
+func DecideStale(in Input) (Plan, error) {
+	policy := in.Spec.SensorFailurePolicy
+	safeAction := SafeActionKeep
+	if policy != nil && policy.SafeAction != "" {
+		safeAction = policy.SafeAction
+	}
+	_, mode := EffectiveSetpoint(in.Spec, in.Now)
+	plan := Plan{
+		Time:      in.Now,
+		Mode:      mode,
+		Action:    ActionNone,
+		Power:     PowerOn,
+		Suspended: in.Spec.Suspend,
+		DryRun:    in.DryRun || in.Spec.DryRun,
+		Override:  ActiveOverride(in.Spec, in.Now),
+		Stale:     true,
+		Unit:      UnitOf(in.Spec),
+		Profiles:  in.Spec.CapabilityProfiles,
+		Offsets:   airConditionerOffsets(in.Spec),
+	}
+	effective, err := TargetAt(in)
+	if err == nil {
+		if effective.Away != nil && effective.Away.Off {
+			return decideAwayOff(in, effective, true), nil
+		}
+		plan.TargetTemperature, plan.Target = effective.Temperature, &effective
+	}
+	plan.reason("no temperature reading is available")
+
+	switch safeAction {
+	case SafeActionKeep:
+		plan.reason("keeping the air conditioners as they are")
+		plan.trackEquipment(in.Spec, in.Previous.Equipment)
+		return plan, nil
+	case SafeActionOff:
+		plan.Action, plan.Power, plan.Mode = ActionSafeOff, PowerOff, ""
+	case SafeActionSetpoint:
+		setpoint, err := ParseTemperature(policy.SafeTemperature)
+		if err != nil {
+			return Plan{}, fmt.Errorf("invalid safe temperature: %w", err)
+		}
+		if policy.SafeMode != ModeCool && policy.SafeMode != ModeHeat {
+			return Plan{}, fmt.Errorf("unsupported safe mode: %s", policy.SafeMode)
+		}
+		plan.Action, plan.Setpoint, plan.Mode = ActionSafeSetpoint, setpoint, policy.SafeMode
+	default:
+		return Plan{}, fmt.Errorf("unsupported safe action: %s", safeAction)
+	}
+
+	if applied := in.Previous.LastCommand; applied != nil && applied.Action == plan.Action && !applied.DryRun &&
+		in.Previous.Sensor != nil && in.Previous.Sensor.FailingSince != nil && !applied.Time.Before(in.Previous.Sensor.FailingSince) {
+		plan.Action = ActionNone
+		plan.reason("safe action %s already applied at %s", safeAction, applied.Time.UTC().Format(time.RFC3339))
+		plan.trackEquipment(in.Spec, in.Previous.Equipment)
+		return plan, nil
+	}
+	if plan.Power == PowerOff {
+		plan.reason("%s: turning the air conditioners off", plan.Action)
+	} else {
+		plan.reason("%s: setpoint %.1f°C, mode %s", plan.Action, plan.Setpoint, plan.Mode)
+	}
+	plan.protectEquipment(in.Spec, in.Previous.Equipment)
+	plan.trackEquipment(in.Spec, in.Previous.Equipment)
+	if plan.Suspended {
+		plan.reason("control is suspended, no command is sent")
+	}
+	if plan.DryRun {
+		plan.reason("dry run, commands are recorded but not sent")
+	}
+	return plan.WithDevices(in.AirConditionerIDs), nil
+}
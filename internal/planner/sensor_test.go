@@ -0,0 +1,199 @@
+package planner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+func TestHeldReading(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	previous := thermopilotv1.ThermoPilotStatus{CurrentTemperature: "26.4"}
+	tests := []struct {
+		name         string
+		policy       *thermopilotv1.SensorFailurePolicy
+		previous     thermopilotv1.ThermoPilotStatus
+		failingSince time.Time
+		want         float64
+		wantOK       bool
+	}{
+		{
+			name:         "no policy",
+			previous:     previous,
+			failingSince: now,
+		},
+		{
+			name:         "within the default hold",
+			policy:       &thermopilotv1.SensorFailurePolicy{},
+			previous:     previous,
+			failingSince: now.Add(-9 * time.Minute),
+			want:         26.4,
+			wantOK:       true,
+		},
+		{
+			name:         "default hold expired",
+			policy:       &thermopilotv1.SensorFailurePolicy{},
+			previous:     previous,
+			failingSince: now.Add(-10 * time.Minute),
+		},
+		{
+			name:         "custom hold",
+			policy:       &thermopilotv1.SensorFailurePolicy{HoldLastReading: &metav1.Duration{Duration: 30 * time.Minute}},
+			previous:     previous,
+			failingSince: now.Add(-20 * time.Minute),
+			want:         26.4,
+			wantOK:       true,
+		},
+		{
+			name:         "hold disabled",
+			policy:       &thermopilotv1.SensorFailurePolicy{HoldLastReading: &metav1.Duration{}},
+			previous:     previous,
+			failingSince: now,
+		},
+		{
+			name:         "no previous reading",
+			policy:       &thermopilotv1.SensorFailurePolicy{},
+			failingSince: now,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := thermopilotv1.ThermoPilotSpec{SensorFailurePolicy: tt.policy}
+			got, ok := HeldReading(spec, tt.previous, tt.failingSince, now)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.InDelta(t, tt.want, got, 1e-9)
+		})
+	}
+}
+
+func TestDecideStale(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	failingSince := metav1.NewTime(now.Add(-15 * time.Minute))
+	failing := thermopilotv1.ThermoPilotStatus{
+		CurrentTemperature: "26.4",
+		Sensor:             &thermopilotv1.SensorStatus{Source: SourceNone, FailingSince: &failingSince},
+	}
+	withCommand := func(action string, at time.Time) thermopilotv1.ThermoPilotStatus {
+		status := *failing.DeepCopy()
+		status.LastCommand = &thermopilotv1.CommandStatus{Time: metav1.NewTime(at), Action: action}
+		return status
+	}
+	tests := []struct {
+		name         string
+		policy       *thermopilotv1.SensorFailurePolicy
+		previous     thermopilotv1.ThermoPilotStatus
+		wantAction   string
+		wantCommands []Command
+		wantErr      bool
+	}{
+		{
+			name:       "keep",
+			policy:     &thermopilotv1.SensorFailurePolicy{SafeAction: SafeActionKeep},
+			previous:   failing,
+			wantAction: ActionNone,
+		},
+		{
+			name:         "off",
+			policy:       &thermopilotv1.SensorFailurePolicy{SafeAction: SafeActionOff},
+			previous:     failing,
+			wantAction:   ActionSafeOff,
+			wantCommands: []Command{{DeviceID: "AC1", Power: PowerOff}},
+		},
+		{
+			name: "safe setpoint",
+			policy: &thermopilotv1.SensorFailurePolicy{
+				SafeAction: SafeActionSetpoint, SafeTemperature: "28", SafeMode: ModeCool,
+			},
+			previous:     failing,
+			wantAction:   ActionSafeSetpoint,
+			wantCommands: []Command{{DeviceID: "AC1", Setpoint: 28, Mode: ModeCool, Power: PowerOn, FanSpeed: FanAuto}},
+		},
+		{
+			name:       "already applied during this failure",
+			policy:     &thermopilotv1.SensorFailurePolicy{SafeAction: SafeActionOff},
+			previous:   withCommand(ActionSafeOff, now.Add(-5*time.Minute)),
+			wantAction: ActionNone,
+		},
+		{
+			name:         "applied during an earlier failure",
+			policy:       &thermopilotv1.SensorFailurePolicy{SafeAction: SafeActionOff},
+			previous:     withCommand(ActionSafeOff, now.Add(-time.Hour)),
+			wantAction:   ActionSafeOff,
+			wantCommands: []Command{{DeviceID: "AC1", Power: PowerOff}},
+		},
+		{
+			name: "invalid safe temperature",
+			policy: &thermopilotv1.SensorFailurePolicy{
+				SafeAction: SafeActionSetpoint, SafeTemperature: "warm", SafeMode: ModeCool,
+			},
+			previous: failing,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := DecideStale(Input{
+				Now:               now,
+				Spec:              thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", Mode: ModeCool, SensorFailurePolicy: tt.policy},
+				Previous:          tt.previous,
+				AirConditionerIDs: []string{"AC1"},
+			})
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, plan.Stale)
+			assert.Equal(t, tt.wantAction, plan.Action)
+			assert.Equal(t, tt.wantCommands, plan.Commands)
+
+			status := *tt.previous.DeepCopy()
+			plan.Record(&status)
+			assert.Equal(t, "26.4", status.CurrentTemperature, "the last known reading is kept")
+			assert.Empty(t, status.LastDecision.CurrentTemperature)
+			if tt.wantAction == ActionSafeOff {
+				assert.Empty(t, status.LastCommand.Setpoint)
+			}
+		})
+	}
+}
+
+func TestDecideStaleRetriesUnsentSafeAction(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	failingSince := metav1.NewTime(now.Add(-15 * time.Minute))
+	previous := thermopilotv1.ThermoPilotStatus{
+		CurrentTemperature: "26.4",
+		Sensor:             &thermopilotv1.SensorStatus{Source: SourceNone, FailingSince: &failingSince},
+	}
+	input := Input{
+		Now: now,
+		Spec: thermopilotv1.ThermoPilotSpec{
+			TargetTemperature:   "25.0",
+			Mode:                ModeCool,
+			SensorFailurePolicy: &thermopilotv1.SensorFailurePolicy{SafeAction: SafeActionOff},
+		},
+		Previous:          previous,
+		AirConditionerIDs: []string{"AC1"},
+	}
+	plan, err := DecideStale(input)
+	require.NoError(t, err)
+	require.Equal(t, ActionSafeOff, plan.Action)
+
+	status := *previous.DeepCopy()
+	plan.Record(&status)
+	RecordUnsent(&status, &previous)
+	assert.Nil(t, status.LastCommand)
+
+	input.Now, input.Previous = now.Add(time.Minute), status
+	retry, err := DecideStale(input)
+	require.NoError(t, err)
+	assert.Equal(t, ActionSafeOff, retry.Action)
+	assert.Equal(t, []Command{{DeviceID: "AC1", Power: PowerOff}}, retry.Commands)
+}
@@ -0,0 +1,227 @@
+package planner
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+	// sleep curves need the zone database, which slim images do not ship
+	_ "time/tzdata"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+// defaultRampStep is how far the effective target moves at once when the ramp
+// does not say.
+const defaultRampStep = 0.5
+
+// Target is the target in force at a time.
+type Target struct {
+	// Temperature is the target the room is controlled towards
+	Temperature float64
+	Mode        string
+	// Desired is the target the ramp moves towards, including SleepOffset
+	Desired     float64
+	SleepOffset float64
+	// Since is when Temperature last moved, or when the ramp towards Desired started
+	Since time.Time
+	// NextChange is when Temperature is due to move next, an away period starts
+	// or ends, or the occupancy changes, zero when none is due
+	NextChange time.Time
+	// Away is the away period in force, nil when there is none
+	Away *Away
+	// Occupancy is the occupancy the target follows, nil without an occupancy schedule
+	Occupancy *Occupancy
+	// Reasons explain how the ramp and the sleep curve shaped the target
+	Reasons []string
+}
+
+// TargetAt returns the target in force at in.Now: the target of the spec or of its
+// occupancy schedule, of its active override or of the away period in force,
+// shifted by the sleep curve and approached at the ramp rate from the effective
+// target recorded in in.Previous. An override setting a target, or an away period,
+// takes effect at once and replaces the sleep curve and the occupancy schedule.
+// An active override takes precedence over away periods.
+func TargetAt(in Input) (Target, error) {
+	target, err := targetAt(in)
+	if err != nil {
+		return Target{}, err
+	}
+	// a target holding still keeps the time it last moved, so that the recorded
+	// status does not change on every reconcile
+	if previous := in.Previous.EffectiveTarget; previous != nil &&
+		previous.Temperature == FormatTemperature(target.Temperature) && previous.Desired == FormatTemperature(target.Desired) {
+		target.Since = previous.Since.Time
+	}
+	return target, nil
+}
+
+// targetAt computes the target of TargetAt, moved at now unless it is ramping.
+func targetAt(in Input) (Target, error) {
+	spec, previous, now := in.Spec, in.Previous.EffectiveTarget, in.Now
+	unit := UnitOf(spec)
+	targetSpec, mode := EffectiveSetpoint(spec, now)
+	desired, err := ParseTemperature(targetSpec)
+	if err != nil {
+		return Target{}, err
+	}
+	away, awayChange, err := ActiveAway(spec, in.AwaySwitches, now)
+	if err != nil {
+		return Target{}, err
+	}
+	target := Target{Temperature: desired, Mode: mode, Desired: desired, Since: now, NextChange: awayChange}
+	if override := ActiveOverride(spec, now); override != nil {
+		if override.TargetTemperature != "" {
+			return target, nil
+		}
+	} else if away != nil {
+		target.Away = away
+		until := "the switch is removed"
+		if !away.End.IsZero() {
+			until = away.End.UTC().Format(time.RFC3339)
+		}
+		if away.Off {
+			target.Reasons = append(target.Reasons, fmt.Sprintf("away (%s) until %s", away.Source, until))
+			return target, nil
+		}
+		target.Temperature, target.Desired, target.Mode = away.TargetTemperature, away.TargetTemperature, away.Mode
+		target.Reasons = append(target.Reasons, unit.Sprintf("away (%s) until %s: target %.1f°C, mode %s",
+			away.Source, until, target.Temperature, target.Mode))
+		return target, nil
+	}
+
+	if spec.Occupancy != nil {
+		occupancy, temperature, err := occupancyTarget(in, desired)
+		if err != nil {
+			return Target{}, err
+		}
+		target.Temperature, target.Desired, target.Occupancy = temperature, temperature, &occupancy
+		target.NextChange = earliest(target.NextChange, occupancy.Until)
+		target.Reasons = append(target.Reasons, occupancy.reason(unit, temperature))
+	}
+
+	offset, next, err := sleepOffset(spec.SleepCurve, now)
+	if err != nil {
+		return Target{}, err
+	}
+	target.NextChange = earliest(target.NextChange, next)
+	if offset != 0 {
+		target.SleepOffset = offset
+		target.Desired += offset
+		target.Temperature = target.Desired
+		target.Reasons = append(target.Reasons, unit.Sprintf("sleep curve offset %s°C, desired target %.1f°C",
+			FormatOffset(offset), target.Desired))
+	}
+
+	if spec.Ramp == nil || previous == nil {
+		return target, nil
+	}
+	rate, step, err := parseRamp(spec.Ramp)
+	if err != nil {
+		return Target{}, err
+	}
+	from, err := ParseTemperature(previous.Temperature)
+	if err != nil {
+		// nothing to ramp from, start at the desired target
+		return target, nil
+	}
+	diff := target.Desired - from
+	// a target that had reached what it was moving towards starts ramping now
+	start := previous.Since.Time
+	if previous.Temperature == previous.Desired {
+		start = now
+	}
+	stepDuration := time.Duration(step / rate * float64(time.Hour))
+	steps := math.Max(0, math.Floor(now.Sub(start).Seconds()/stepDuration.Seconds()))
+	if math.Abs(diff) < 0.05 || steps*step >= math.Abs(diff) {
+		return target, nil
+	}
+	target.Temperature = from + math.Copysign(steps*step, diff)
+	target.Since = start.Add(time.Duration(steps) * stepDuration)
+	target.NextChange = earliest(target.NextChange, target.Since.Add(stepDuration))
+	target.Reasons = append(target.Reasons, unit.Sprintf("ramping towards %.1f°C at %.1f°C/h, effective target %.1f°C",
+		target.Desired, rate, target.Temperature))
+	return target, nil
+}
+
+// status returns the effective target to record in status.
+func (t Target) status() *thermopilotv1.EffectiveTargetStatus {
+	status := &thermopilotv1.EffectiveTargetStatus{
+		Temperature: FormatTemperature(t.Temperature),
+		Desired:     FormatTemperature(t.Desired),
+		Since:       metav1.NewTime(t.Since),
+	}
+	if t.SleepOffset != 0 {
+		status.SleepOffset = FormatOffset(t.SleepOffset)
+	}
+	if !t.NextChange.IsZero() {
+		nextChange := metav1.NewTime(t.NextChange)
+		status.NextChange = &nextChange
+	}
+	return status
+}
+
+// parseRamp returns the rate, in degrees per hour, and the step of ramp.
+func parseRamp(ramp *thermopilotv1.Ramp) (rate, step float64, err error) {
+	rate, err = ParseTemperature(ramp.Rate)
+	if err != nil || rate <= 0 {
+		return 0, 0, fmt.Errorf("invalid ramp rate %q", ramp.Rate)
+	}
+	step = defaultRampStep
+	if ramp.Step != "" {
+		step, err = ParseTemperature(ramp.Step)
+		if err != nil || step <= 0 {
+			return 0, 0, fmt.Errorf("invalid ramp step %q", ramp.Step)
+		}
+	}
+	return rate, step, nil
+}
+
+// sleepOffset returns the offset of curve at now and when it changes next: the
+// next point, the end of the curve or the next bedtime.
+func sleepOffset(curve *thermopilotv1.SleepCurve, now time.Time) (float64, time.Time, error) {
+	if curve == nil {
+		return 0, time.Time{}, nil
+	}
+	zone := curve.TimeZone
+	if zone == "" {
+		zone = "UTC"
+	}
+	location, err := time.LoadLocation(zone)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("invalid sleep curve time zone %q", curve.TimeZone)
+	}
+	clock, err := time.Parse("15:04", curve.Bedtime)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("invalid bedtime %q", curve.Bedtime)
+	}
+
+	local := now.In(location)
+	bedtime := time.Date(local.Year(), local.Month(), local.Day(), clock.Hour(), clock.Minute(), 0, 0, location)
+	if bedtime.After(local) {
+		bedtime = bedtime.AddDate(0, 0, -1)
+	}
+	elapsed := local.Sub(bedtime)
+	if elapsed >= curve.Duration.Duration {
+		return 0, bedtime.AddDate(0, 0, 1).UTC(), nil
+	}
+
+	points := slices.Clone(curve.Points)
+	slices.SortStableFunc(points, func(a, b thermopilotv1.SleepCurvePoint) int {
+		return int(a.After.Duration - b.After.Duration)
+	})
+	var offset float64
+	next := bedtime.Add(curve.Duration.Duration)
+	for _, point := range points {
+		if point.After.Duration > elapsed {
+			next = bedtime.Add(min(point.After.Duration, curve.Duration.Duration))
+			break
+		}
+		if offset, err = ParseTemperature(point.Offset); err != nil {
+			return 0, time.Time{}, fmt.Errorf("invalid sleep curve offset %q", point.Offset)
+		}
+	}
+	return offset, next.UTC(), nil
+}
@@ -0,0 +1,171 @@
+package planner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+func TestTargetAt_Ramp(t *testing.T) {
+	now := time.Date(2025, 1, 1, 22, 0, 0, 0, time.UTC)
+	spec := thermopilotv1.ThermoPilotSpec{TargetTemperature: "22.0", Mode: ModeCool, Ramp: &thermopilotv1.Ramp{Rate: "2.0", Step: "0.5"}}
+	previous := func(temperature, desired string, since time.Duration) *thermopilotv1.EffectiveTargetStatus {
+		return &thermopilotv1.EffectiveTargetStatus{Temperature: temperature, Desired: desired, Since: metav1.NewTime(now.Add(-since))}
+	}
+	tests := []struct {
+		name           string
+		spec           thermopilotv1.ThermoPilotSpec
+		previous       *thermopilotv1.EffectiveTargetStatus
+		want           float64
+		wantSince      time.Time
+		wantNextChange time.Time
+	}{
+		{
+			name:      "first reconcile starts at the target",
+			spec:      spec,
+			want:      22.0,
+			wantSince: now,
+		},
+		{
+			name:      "reached target stays",
+			spec:      spec,
+			previous:  previous("22.0", "22.0", 5*time.Minute),
+			want:      22.0,
+			wantSince: now.Add(-5 * time.Minute),
+		},
+		{
+			name:           "starts ramping when the target changes",
+			spec:           spec,
+			previous:       previous("25.0", "25.0", 3*time.Hour),
+			want:           25.0,
+			wantSince:      now,
+			wantNextChange: now.Add(15 * time.Minute),
+		},
+		{
+			name:           "waits for the first step",
+			spec:           spec,
+			previous:       previous("25.0", "22.0", 5*time.Minute),
+			want:           25.0,
+			wantSince:      now.Add(-5 * time.Minute),
+			wantNextChange: now.Add(10 * time.Minute),
+		},
+		{
+			name:           "moves by whole steps",
+			spec:           spec,
+			previous:       previous("25.0", "22.0", 40*time.Minute),
+			want:           24.0,
+			wantSince:      now.Add(-10 * time.Minute),
+			wantNextChange: now.Add(5 * time.Minute),
+		},
+		{
+			name:      "does not overshoot",
+			spec:      spec,
+			previous:  previous("23.0", "22.0", 3*time.Hour),
+			want:      22.0,
+			wantSince: now,
+		},
+		{
+			name: "override applies at once",
+			spec: func() thermopilotv1.ThermoPilotSpec {
+				spec := *spec.DeepCopy()
+				spec.Override = &thermopilotv1.Override{TargetTemperature: "27.0", ExpiresAt: metav1.NewTime(now.Add(time.Hour))}
+				return spec
+			}(),
+			previous:  previous("22.0", "22.0", 5*time.Minute),
+			want:      27.0,
+			wantSince: now,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TargetAt(Input{Now: now, Spec: tt.spec, Previous: thermopilotv1.ThermoPilotStatus{EffectiveTarget: tt.previous}})
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, got.Temperature, 1e-9)
+			assert.True(t, tt.wantSince.Equal(got.Since), "since %s", got.Since)
+			assert.True(t, tt.wantNextChange.Equal(got.NextChange), "next change %s", got.NextChange)
+		})
+	}
+}
+
+func TestTargetAt_SleepCurve(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	spec := thermopilotv1.ThermoPilotSpec{TargetTemperature: "26.0", Mode: ModeCool, SleepCurve: &thermopilotv1.SleepCurve{
+		Bedtime:  "23:00",
+		TimeZone: "Asia/Tokyo",
+		Duration: metav1.Duration{Duration: 8 * time.Hour},
+		Points: []thermopilotv1.SleepCurvePoint{
+			{After: metav1.Duration{Duration: 3 * time.Hour}, Offset: "1.5"},
+			{After: metav1.Duration{}, Offset: "0.5"},
+		},
+	}}
+	tests := []struct {
+		name           string
+		now            time.Time
+		want           float64
+		wantNextChange time.Time
+	}{
+		{
+			name:           "before bedtime",
+			now:            time.Date(2025, 1, 1, 21, 0, 0, 0, tokyo),
+			want:           26.0,
+			wantNextChange: time.Date(2025, 1, 1, 23, 0, 0, 0, tokyo),
+		},
+		{
+			name:           "first point",
+			now:            time.Date(2025, 1, 1, 23, 30, 0, 0, tokyo),
+			want:           26.5,
+			wantNextChange: time.Date(2025, 1, 2, 2, 0, 0, 0, tokyo),
+		},
+		{
+			name:           "after midnight",
+			now:            time.Date(2025, 1, 2, 4, 0, 0, 0, tokyo),
+			want:           27.5,
+			wantNextChange: time.Date(2025, 1, 2, 7, 0, 0, 0, tokyo),
+		},
+		{
+			name:           "curve over",
+			now:            time.Date(2025, 1, 2, 7, 0, 0, 0, tokyo),
+			want:           26.0,
+			wantNextChange: time.Date(2025, 1, 2, 23, 0, 0, 0, tokyo),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TargetAt(Input{Now: tt.now, Spec: spec})
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, got.Temperature, 1e-9)
+			assert.True(t, tt.wantNextChange.Equal(got.NextChange), "next change %s", got.NextChange)
+		})
+	}
+
+	spec.SleepCurve.TimeZone = "Mars/Olympus"
+	_, err = TargetAt(Input{Now: time.Date(2025, 1, 1, 21, 0, 0, 0, tokyo), Spec: spec})
+	assert.Error(t, err)
+}
+
+func TestDecide_RecordsEffectiveTarget(t *testing.T) {
+	now := time.Date(2025, 1, 1, 22, 0, 0, 0, time.UTC)
+	spec := thermopilotv1.ThermoPilotSpec{TargetTemperature: "22.0", Threshold: "1.0", Mode: ModeCool, Ramp: &thermopilotv1.Ramp{Rate: "1.0"}}
+	status := thermopilotv1.ThermoPilotStatus{EffectiveTarget: &thermopilotv1.EffectiveTargetStatus{
+		Temperature: "25.0", Desired: "22.0", Since: metav1.NewTime(now.Add(-time.Hour)),
+	}}
+	plan, err := Decide(Input{Now: now, CurrentTemperature: 25.0, Spec: spec, Previous: status})
+	require.NoError(t, err)
+	assert.InDelta(t, 24.0, plan.TargetTemperature, 1e-9)
+	assert.Equal(t, ActionNone, plan.Action)
+	assert.Contains(t, plan.Reasons, "ramping towards 22.0°C at 1.0°C/h, effective target 24.0°C")
+
+	plan.Record(&status)
+	require.NotNil(t, status.EffectiveTarget)
+	assert.Equal(t, "24.0", status.EffectiveTarget.Temperature)
+	assert.Equal(t, "22.0", status.EffectiveTarget.Desired)
+	require.NotNil(t, status.EffectiveTarget.NextChange)
+	assert.Equal(t, now.Add(30*time.Minute), status.EffectiveTarget.NextChange.Time)
+	assert.Equal(t, "24.0", status.LastDecision.TargetTemperature)
+}
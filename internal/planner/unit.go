@@ -0,0 +1,58 @@
+package planner
+
+import (
+	"fmt"
+	"strings"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+// Unit is the temperature unit of a ThermoPilot. Every temperature the planner
+// handles is in this unit; the SwitchBot API works in Celsius, so readings and
+// setpoints are converted by the controller when talking to it.
+type Unit string
+
+const (
+	Celsius    Unit = "celsius"
+	Fahrenheit Unit = "fahrenheit"
+)
+
+// UnitOf returns the unit of spec, Celsius unless it says otherwise.
+func UnitOf(spec thermopilotv1.ThermoPilotSpec) Unit {
+	if spec.Unit == string(Fahrenheit) {
+		return Fahrenheit
+	}
+	return Celsius
+}
+
+// Symbol returns the symbol temperatures in u are written with.
+func (u Unit) Symbol() string {
+	if u == Fahrenheit {
+		return "°F"
+	}
+	return "°C"
+}
+
+// FromCelsius converts a temperature in Celsius to u.
+func (u Unit) FromCelsius(celsius float64) float64 {
+	if u == Fahrenheit {
+		return celsius*9/5 + 32
+	}
+	return celsius
+}
+
+// ToCelsius converts a temperature in u to Celsius.
+func (u Unit) ToCelsius(value float64) float64 {
+	if u == Fahrenheit {
+		return (value - 32) * 5 / 9
+	}
+	return value
+}
+
+// Sprintf formats like fmt.Sprintf, writing every °C of format in u.
+func (u Unit) Sprintf(format string, args ...any) string {
+	if u != Celsius {
+		format = strings.ReplaceAll(format, "°C", u.Symbol())
+	}
+	return fmt.Sprintf(format, args...)
+}
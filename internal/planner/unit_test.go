@@ -0,0 +1,91 @@
+package planner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+)
+
+func TestUnit_Conversion(t *testing.T) {
+	tests := []struct {
+		unit    Unit
+		celsius float64
+		want    float64
+	}{
+		{unit: Celsius, celsius: 25.0, want: 25.0},
+		{unit: Fahrenheit, celsius: 25.0, want: 77.0},
+		{unit: Fahrenheit, celsius: 0, want: 32.0},
+		{unit: Fahrenheit, celsius: -40.0, want: -40.0},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.unit), func(t *testing.T) {
+			assert.InDelta(t, tt.want, tt.unit.FromCelsius(tt.celsius), 1e-9)
+			assert.InDelta(t, tt.celsius, tt.unit.ToCelsius(tt.want), 1e-9)
+		})
+	}
+	assert.Equal(t, Celsius, UnitOf(thermopilotv1.ThermoPilotSpec{}))
+	assert.Equal(t, Fahrenheit, UnitOf(thermopilotv1.ThermoPilotSpec{Unit: "fahrenheit"}))
+}
+
+func TestDecide_Fahrenheit(t *testing.T) {
+	spec := thermopilotv1.ThermoPilotSpec{Unit: "fahrenheit", TargetTemperature: "75", Threshold: "2", Mode: ModeCool}
+	plan, err := Decide(Input{
+		Now:                time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+		CurrentTemperature: 78.0,
+		Spec:               spec,
+		AirConditionerIDs:  []string{"ac1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ActionCooling, plan.Action)
+	assert.Equal(t, Fahrenheit, plan.Unit)
+	assert.Contains(t, plan.Reasons, "current 78.0°F is 3.0°F above target 75.0°F (upper threshold 2.0°F) in cool mode")
+	require.Len(t, plan.Commands, 1)
+	// 75°F is 23.9°C, sent as 24°C
+	assert.InDelta(t, 75.2, plan.Commands[0].Setpoint, 1e-9)
+}
+
+func TestFitCommand_Fahrenheit(t *testing.T) {
+	profiles := []thermopilotv1.CapabilityProfile{{Name: "half", Devices: []string{"ac2"}, Step: "0.5"}}
+	tests := []struct {
+		name    string
+		command Command
+		want    float64
+		wantWhy string
+	}{
+		{
+			name:    "quantized in Celsius",
+			command: Command{DeviceID: "ac1", Setpoint: 75.0, Mode: ModeCool, Power: PowerOn},
+			want:    75.2,
+			wantWhy: "ac1: setpoint 75.0°F sent as 24°C (default capabilities: 16-30°C in steps of 1°C)",
+		},
+		{
+			name:    "clamped in Celsius",
+			command: Command{DeviceID: "ac1", Setpoint: 90.0, Mode: ModeCool, Power: PowerOn},
+			want:    86.0,
+			wantWhy: "ac1: setpoint 90.0°F sent as 30°C (default capabilities: 16-30°C in steps of 1°C)",
+		},
+		{
+			name:    "profile step in Celsius",
+			command: Command{DeviceID: "ac2", Setpoint: 73.0, Mode: ModeCool, Power: PowerOn},
+			want:    73.4,
+			wantWhy: "ac2: setpoint 73.0°F sent as 23°C (profile half: 16-30°C in steps of 0.5°C)",
+		},
+		{
+			name:    "whole degree Celsius unchanged",
+			command: Command{DeviceID: "ac1", Setpoint: 77.0, Mode: ModeCool, Power: PowerOn},
+			want:    77.0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, why, err := FitCommand(Fahrenheit, profiles, tt.command)
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, got.Setpoint, 1e-9)
+			assert.Equal(t, tt.wantWhy, why)
+		})
+	}
+}
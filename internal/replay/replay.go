@@ -0,0 +1,203 @@
+// Package replay runs the controller's decision logic against recorded sensor
+// traces on a simulated clock, to compare control strategies offline.
+package replay
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+	"github.com/seipan/thermo-pilot-controller/internal/calendar"
+	"github.com/seipan/thermo-pilot-controller/internal/planner"
+)
+
+// Options tune a replay.
+type Options struct {
+	// Interval between decisions. Zero uses the reconciler's requeue interval.
+	Interval time.Duration
+	// AirConditionerIDs are the devices commands are addressed to.
+	AirConditionerIDs []string
+	// Calendar is the occupancy calendar, required when the spec has an occupancy schedule.
+	Calendar *calendar.Calendar
+	// SensorID is the device ID of the sensor the trace was recorded from, whose
+	// calibration offset in the spec is applied to the samples.
+	SensorID string
+}
+
+// Step is a single decision of the replay.
+type Step struct {
+	Time        time.Time `json:"time"`
+	Temperature float64   `json:"temperature"`
+	Humidity    *float64  `json:"humidity,omitempty"`
+	// Filtered is the reading the decision was based on when the spec calibrates or
+	// filters readings
+	Filtered          *float64 `json:"filtered,omitempty"`
+	TargetTemperature float64  `json:"targetTemperature"`
+	Action            string   `json:"action"`
+	Setpoint          float64  `json:"setpoint"`
+	Mode              string   `json:"mode"`
+	// Sent is true when the reconciler would have sent the commands
+	Sent     bool `json:"sent"`
+	Commands int  `json:"commands"`
+	// Blocked is the action withheld by equipment protection
+	Blocked string   `json:"blocked,omitempty"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// Stats summarizes comfort and runtime over a replay.
+type Stats struct {
+	Duration  time.Duration `json:"duration"`
+	Decisions int           `json:"decisions"`
+	// Commands is the number of decisions that sent commands
+	Commands         int            `json:"commands"`
+	CommandsByAction map[string]int `json:"commandsByAction"`
+	// Blocked is the number of decisions whose action equipment protection withheld
+	Blocked int `json:"blocked,omitempty"`
+	// WithinThreshold is the fraction of time the room was within the threshold of the target
+	WithinThreshold float64 `json:"withinThreshold"`
+	// MeanAbsoluteError is the time-weighted mean distance to the target, in degrees of the spec unit
+	MeanAbsoluteError float64 `json:"meanAbsoluteError"`
+	// MaxDeviation is the largest distance to the target, in degrees of the spec unit
+	MaxDeviation float64 `json:"maxDeviation"`
+	// DegreeHoursOutside integrates how far outside the threshold the room was, in degree-hours of the spec unit
+	DegreeHoursOutside float64 `json:"degreeHoursOutside"`
+	// Runtime is how long the air conditioners were on after the first command
+	Runtime time.Duration `json:"runtime"`
+}
+
+// Result is the outcome of replaying a trace against a spec.
+type Result struct {
+	Name  string `json:"name"`
+	Steps []Step `json:"steps"`
+	Stats Stats  `json:"stats"`
+}
+
+// Run replays samples against spec. At every decision the latest sample at or
+// before the simulated time is used as the sensor reading, and the status is
+// carried over between decisions exactly as the reconciler records it.
+func Run(name string, spec thermopilotv1.ThermoPilotSpec, samples []Sample, opts Options) (*Result, error) {
+	if len(samples) == 0 {
+		return nil, errors.New("trace has no samples")
+	}
+	deviceIDs := opts.AirConditionerIDs
+	if len(deviceIDs) == 0 {
+		deviceIDs = []string{"ac"}
+		if spec.AirConditionerID != "" {
+			deviceIDs = []string{spec.AirConditionerID}
+		}
+	}
+
+	result := &Result{Name: name, Stats: Stats{CommandsByAction: map[string]int{}}}
+	stats := &result.Stats
+	var status thermopilotv1.ThermoPilotStatus
+	var acOn bool
+	var absErrorHours, withinHours float64
+
+	start, end := samples[0].Time, samples[len(samples)-1].Time
+	index := 0
+	for now := start; !now.After(end); {
+		for index+1 < len(samples) && !samples[index+1].Time.After(now) {
+			index++
+		}
+		sample := samples[index]
+
+		var reasons []string
+		calibrated, offset, calibrationReason := planner.CalibrateReading(spec, opts.SensorID, sample.Temperature)
+		if calibrationReason != "" {
+			reasons = append(reasons, calibrationReason)
+		}
+		temperature, filterStatus, filterReason := planner.FilterReading(spec, status.Filter, calibrated)
+		status.Filter = filterStatus
+		if filterReason != "" {
+			reasons = append(reasons, filterReason)
+		}
+		plan, err := planner.Decide(planner.Input{
+			Now:                now,
+			CurrentTemperature: temperature,
+			Spec:               spec,
+			Previous:           status,
+			AirConditionerIDs:  deviceIDs,
+			Reasons:            reasons,
+			Calendar:           opts.Calendar,
+		})
+		if err != nil {
+			return nil, err
+		}
+		plan.Record(&status)
+
+		step := Step{
+			Time:              now,
+			Temperature:       sample.Temperature,
+			Humidity:          sample.Humidity,
+			TargetTemperature: plan.TargetTemperature,
+			Action:            plan.Action,
+			Setpoint:          plan.Setpoint,
+			Mode:              plan.Mode,
+			Sent:              plan.Actuate(),
+			Commands:          len(plan.Commands),
+			Blocked:           plan.Blocked,
+			Reasons:           plan.Reasons,
+		}
+		if filterStatus != nil || offset != 0 {
+			step.Filtered = &temperature
+		}
+		result.Steps = append(result.Steps, step)
+		stats.Decisions++
+		if step.Sent {
+			stats.Commands++
+			stats.CommandsByAction[plan.Action]++
+			acOn = plan.Power == planner.PowerOn
+		}
+		if plan.Blocked != "" {
+			stats.Blocked++
+		}
+
+		interval := opts.Interval
+		if interval <= 0 {
+			interval = planner.RequeueAfter(spec, now)
+			if !plan.BlockedUntil.IsZero() {
+				interval = min(interval, plan.BlockedUntil.Sub(now))
+			}
+			if plan.Target != nil && plan.Target.NextChange.After(now) {
+				interval = min(interval, plan.Target.NextChange.Sub(now))
+			}
+		}
+		next := now.Add(interval)
+		span := minTime(next, end).Sub(now)
+		hours := span.Hours()
+		deviation := math.Abs(sample.Temperature - plan.TargetTemperature)
+		threshold := plan.UpperThreshold
+		if sample.Temperature < plan.TargetTemperature {
+			threshold = plan.LowerThreshold
+		}
+		stats.MaxDeviation = math.Max(stats.MaxDeviation, deviation)
+		absErrorHours += deviation * hours
+		if deviation <= threshold {
+			withinHours += hours
+		} else {
+			stats.DegreeHoursOutside += (deviation - threshold) * hours
+		}
+		if acOn {
+			stats.Runtime += span
+		}
+		if !next.After(now) {
+			break
+		}
+		now = next
+	}
+
+	stats.Duration = end.Sub(start)
+	if total := stats.Duration.Hours(); total > 0 {
+		stats.WithinThreshold = withinHours / total
+		stats.MeanAbsoluteError = absErrorHours / total
+	}
+	return result, nil
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
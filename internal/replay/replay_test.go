@@ -0,0 +1,212 @@
+package replay
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+	"github.com/seipan/thermo-pilot-controller/internal/planner"
+)
+
+func TestReadTrace(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      string
+		input       string
+		wantSamples int
+		wantErr     bool
+	}{
+		{
+			name:        "csv",
+			format:      FormatCSV,
+			input:       "time,temperature,humidity\n2025-07-01T12:00:00Z,26.5,55\n2025-07-01T12:05:00Z,26.0,\n",
+			wantSamples: 2,
+		},
+		{
+			name:        "csv with reordered columns",
+			format:      FormatCSV,
+			input:       "temperature, time\n26.5, 2025-07-01T12:00:00Z\n",
+			wantSamples: 1,
+		},
+		{
+			name:        "json lines",
+			format:      FormatJSONLines,
+			input:       `{"time":"2025-07-01T12:00:00Z","temperature":26.5,"humidity":55}` + "\n\n" + `{"time":"2025-07-01T12:05:00Z","temperature":26.0}` + "\n",
+			wantSamples: 2,
+		},
+		{
+			name:    "missing temperature column",
+			format:  FormatCSV,
+			input:   "time,humidity\n2025-07-01T12:00:00Z,55\n",
+			wantErr: true,
+		},
+		{
+			name:    "out of order",
+			format:  FormatCSV,
+			input:   "time,temperature\n2025-07-01T12:05:00Z,26.5\n2025-07-01T12:00:00Z,26.0\n",
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			format:  FormatJSONLines,
+			input:   "",
+			wantErr: true,
+		},
+		{
+			name:    "unknown format",
+			format:  "xml",
+			input:   "<trace/>",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			samples, err := ReadTrace(strings.NewReader(tt.input), tt.format)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, samples, tt.wantSamples)
+			assert.Equal(t, 26.5, samples[0].Temperature)
+		})
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	assert.Equal(t, FormatCSV, FormatFromPath("trace.csv"))
+	assert.Equal(t, FormatJSONLines, FormatFromPath("trace.jsonl"))
+	assert.Equal(t, FormatJSONLines, FormatFromPath("trace.NDJSON"))
+}
+
+func TestRun(t *testing.T) {
+	f, err := os.Open("testdata/trace.csv")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	samples, err := ReadTrace(f, FormatCSV)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		spec          thermopilotv1.ThermoPilotSpec
+		interval      time.Duration
+		wantDecisions int
+		wantCommands  bool
+	}{
+		{
+			name:          "threshold 1.0",
+			spec:          thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", Threshold: "1.0", Mode: planner.ModeCool},
+			wantDecisions: 73,
+			wantCommands:  true,
+		},
+		{
+			name:          "threshold 5.0 never acts",
+			spec:          thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", Threshold: "5.0", Mode: planner.ModeCool},
+			wantDecisions: 73,
+		},
+		{
+			name:          "suspended never sends",
+			spec:          thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", Mode: planner.ModeCool, Suspend: true},
+			wantDecisions: 73,
+		},
+		{
+			name:          "custom interval",
+			spec:          thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", Threshold: "1.0", Mode: planner.ModeCool},
+			interval:      30 * time.Minute,
+			wantDecisions: 13,
+			wantCommands:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Run(tt.name, tt.spec, samples, Options{Interval: tt.interval})
+			require.NoError(t, err)
+			stats := result.Stats
+			assert.Equal(t, 6*time.Hour, stats.Duration)
+			assert.Equal(t, tt.wantDecisions, stats.Decisions)
+			assert.InDelta(t, 3.0, stats.MaxDeviation, 0.001)
+			assert.Greater(t, stats.WithinThreshold, 0.0)
+			assert.LessOrEqual(t, stats.WithinThreshold, 1.0)
+			if tt.wantCommands {
+				assert.Positive(t, stats.Commands)
+				assert.Equal(t, stats.Commands, stats.CommandsByAction[planner.ActionCooling])
+				assert.Positive(t, stats.Runtime)
+				assert.Positive(t, stats.DegreeHoursOutside)
+			} else {
+				assert.Zero(t, stats.Commands)
+				assert.Zero(t, stats.Runtime)
+			}
+		})
+	}
+}
+
+func TestRun_SharesDecisionsWithPlanner(t *testing.T) {
+	start := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	samples := []Sample{
+		{Time: start, Temperature: 27.0},
+		{Time: start.Add(10 * time.Minute), Temperature: 25.5},
+	}
+	spec := thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", Threshold: "1.0", Mode: planner.ModeCool}
+
+	result, err := Run("test", spec, samples, Options{})
+	require.NoError(t, err)
+	require.Len(t, result.Steps, 3)
+	assert.Equal(t, planner.ActionCooling, result.Steps[0].Action)
+	assert.True(t, result.Steps[0].Sent)
+	// the sample is held until the next one arrives
+	assert.Equal(t, 27.0, result.Steps[1].Temperature)
+	assert.Equal(t, planner.ActionNone, result.Steps[2].Action)
+	assert.Equal(t, 10*time.Minute, result.Stats.Runtime)
+}
+
+func TestRun_FilterRejectsSpike(t *testing.T) {
+	start := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	samples := []Sample{
+		{Time: start, Temperature: 25.0},
+		// a door opens for a single reading
+		{Time: start.Add(5 * time.Minute), Temperature: 28.5},
+		{Time: start.Add(10 * time.Minute), Temperature: 25.2},
+	}
+	spec := thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", Threshold: "1.0", Mode: planner.ModeCool}
+
+	unfiltered, err := Run("unfiltered", spec, samples, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, unfiltered.Stats.Commands)
+
+	spec.Filter = &thermopilotv1.ReadingFilter{Smoothing: planner.SmoothingNone, MaxJump: "2.0"}
+	filtered, err := Run("filtered", spec, samples, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, filtered.Stats.Commands)
+	require.NotNil(t, filtered.Steps[1].Filtered)
+	assert.Equal(t, 28.5, filtered.Steps[1].Temperature)
+	assert.Equal(t, 25.0, *filtered.Steps[1].Filtered)
+}
+
+func TestRun_CalibratesSensor(t *testing.T) {
+	start := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	// the sensor reads 1.5°C high
+	samples := []Sample{{Time: start, Temperature: 26.5}}
+	spec := thermopilotv1.ThermoPilotSpec{TargetTemperature: "25.0", Threshold: "1.0", Mode: planner.ModeCool,
+		Calibration: &thermopilotv1.Calibration{Sensors: []thermopilotv1.CalibrationOffset{{DeviceID: "meter", Offset: "-1.5"}}}}
+
+	uncalibrated, err := Run("uncalibrated", spec, samples, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, planner.ActionCooling, uncalibrated.Steps[0].Action)
+	assert.Nil(t, uncalibrated.Steps[0].Filtered)
+
+	calibrated, err := Run("calibrated", spec, samples, Options{SensorID: "meter"})
+	require.NoError(t, err)
+	step := calibrated.Steps[0]
+	assert.Equal(t, planner.ActionNone, step.Action)
+	assert.Equal(t, 26.5, step.Temperature)
+	require.NotNil(t, step.Filtered)
+	assert.InDelta(t, 25.0, *step.Filtered, 1e-9)
+	assert.Contains(t, step.Reasons, "sensor meter reported 26.5°C, calibrated by -1.5°C to 25.0°C")
+}
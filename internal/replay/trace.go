@@ -0,0 +1,137 @@
+package replay
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Trace formats understood by ReadTrace.
+const (
+	FormatCSV       = "csv"
+	FormatJSONLines = "jsonl"
+)
+
+// Sample is a single sensor reading of a recorded trace.
+type Sample struct {
+	Time        time.Time `json:"time"`
+	Temperature float64   `json:"temperature"`
+	Humidity    *float64  `json:"humidity,omitempty"`
+}
+
+// FormatFromPath guesses the trace format from the file extension.
+func FormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson", ".json":
+		return FormatJSONLines
+	default:
+		return FormatCSV
+	}
+}
+
+// ReadTrace reads a trace in the given format. CSV traces need a header with a
+// time column (RFC 3339) and a temperature column, and may have a humidity
+// column; JSON lines traces have one Sample object per line. Samples must be in
+// chronological order.
+func ReadTrace(r io.Reader, format string) ([]Sample, error) {
+	var samples []Sample
+	var err error
+	switch format {
+	case FormatCSV:
+		samples, err = readCSV(r)
+	case FormatJSONLines:
+		samples, err = readJSONLines(r)
+	default:
+		return nil, fmt.Errorf("unsupported trace format: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(samples) == 0 {
+		return nil, errors.New("trace has no samples")
+	}
+	for i := 1; i < len(samples); i++ {
+		if samples[i].Time.Before(samples[i-1].Time) {
+			return nil, fmt.Errorf("sample %d at %s is earlier than the previous one", i+1, samples[i].Time.Format(time.RFC3339))
+		}
+	}
+	return samples, nil
+}
+
+func readCSV(r io.Reader) ([]Sample, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	timeCol, ok := columns["time"]
+	if !ok {
+		return nil, errors.New("CSV header has no time column")
+	}
+	tempCol, ok := columns["temperature"]
+	if !ok {
+		return nil, errors.New("CSV header has no temperature column")
+	}
+	humidityCol, hasHumidity := columns["humidity"]
+
+	var samples []Sample
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		t, err := time.Parse(time.RFC3339, record[timeCol])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid time: %w", line, err)
+		}
+		temperature, err := strconv.ParseFloat(record[tempCol], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid temperature: %w", line, err)
+		}
+		sample := Sample{Time: t, Temperature: temperature}
+		if hasHumidity && record[humidityCol] != "" {
+			humidity, err := strconv.ParseFloat(record[humidityCol], 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid humidity: %w", line, err)
+			}
+			sample.Humidity = &humidity
+		}
+		samples = append(samples, sample)
+	}
+	return samples, nil
+}
+
+func readJSONLines(r io.Reader) ([]Sample, error) {
+	var samples []Sample
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var sample Sample
+		if err := json.Unmarshal([]byte(text), &sample); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		samples = append(samples, sample)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
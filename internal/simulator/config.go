@@ -0,0 +1,174 @@
+package simulator
+
+import (
+	"fmt"
+	"os"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	defaultTimeScale    = 1.0
+	defaultHeatLossRate = 0.2
+	defaultCapacity     = 4.0
+	defaultHumidity     = 50
+	defaultBattery      = 100
+	defaultSensorType   = "MeterPro"
+	defaultHubType      = "Hub Mini"
+)
+
+// Config describes the simulated account: its credentials, the rooms with their
+// devices, the weather and the failures to inject.
+type Config struct {
+	// Token and Secret are the credentials requests must be signed with
+	Token  string `json:"token"`
+	Secret string `json:"secret"`
+	// TimeScale is the number of simulated seconds that pass per real second
+	TimeScale float64 `json:"timeScale,omitempty"`
+	// OutdoorTemperature is the temperature every room drifts towards, in °C
+	OutdoorTemperature float64 `json:"outdoorTemperature"`
+	// Seed seeds the random failure injection
+	Seed uint64 `json:"seed,omitempty"`
+	// Hubs relay the commands of the sensors and infrared remotes attached to them
+	Hubs     []DeviceConfig `json:"hubs,omitempty"`
+	Rooms    []RoomConfig   `json:"rooms"`
+	Failures FailureConfig  `json:"failures,omitempty"`
+}
+
+// RoomConfig describes a room and the devices in it.
+type RoomConfig struct {
+	Name               string  `json:"name"`
+	InitialTemperature float64 `json:"initialTemperature"`
+	Humidity           int     `json:"humidity,omitempty"`
+	// HeatLossRate is the fraction of the indoor/outdoor difference the room
+	// loses per hour; higher values mean worse insulation
+	HeatLossRate    float64        `json:"heatLossRate,omitempty"`
+	Sensors         []DeviceConfig `json:"sensors,omitempty"`
+	AirConditioners []DeviceConfig `json:"airConditioners,omitempty"`
+}
+
+// DeviceConfig describes a single device.
+type DeviceConfig struct {
+	ID    string `json:"id"`
+	Name  string `json:"name,omitempty"`
+	Type  string `json:"type,omitempty"`
+	HubID string `json:"hubId,omitempty"`
+	// Capacity is how fast an air conditioner changes the room temperature at
+	// full power, in °C per hour
+	Capacity float64 `json:"capacity,omitempty"`
+	// Battery is the battery level reported by a sensor
+	Battery int `json:"battery,omitempty"`
+}
+
+// FailureConfig describes the failures injected into API responses.
+type FailureConfig struct {
+	// Latency is added to every API request
+	Latency metav1.Duration `json:"latency,omitempty"`
+	// RateLimitProbability is the fraction of API requests answered with 429
+	RateLimitProbability float64 `json:"rateLimitProbability,omitempty"`
+	// OfflineHubs are hubs whose attached devices do not respond
+	OfflineHubs []string `json:"offlineHubs,omitempty"`
+	// FrozenSensors are sensors that keep reporting their first reading
+	FrozenSensors []string `json:"frozenSensors,omitempty"`
+}
+
+// LoadConfig reads a YAML configuration file. Defaults are applied by New.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	var cfg Config
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// setDefaults fills in the optional fields and validates the configuration.
+func (c *Config) setDefaults() error {
+	if c.Token == "" || c.Secret == "" {
+		return fmt.Errorf("token and secret are required")
+	}
+	if c.TimeScale <= 0 {
+		c.TimeScale = defaultTimeScale
+	}
+	if c.Failures.RateLimitProbability < 0 || c.Failures.RateLimitProbability > 1 {
+		return fmt.Errorf("rateLimitProbability must be between 0 and 1")
+	}
+
+	ids := map[string]bool{}
+	checkID := func(d *DeviceConfig) error {
+		if d.ID == "" {
+			return fmt.Errorf("device id is required")
+		}
+		if ids[d.ID] {
+			return fmt.Errorf("duplicate device id %s", d.ID)
+		}
+		ids[d.ID] = true
+		if d.Name == "" {
+			d.Name = d.ID
+		}
+		return nil
+	}
+	for i := range c.Hubs {
+		hub := &c.Hubs[i]
+		if err := checkID(hub); err != nil {
+			return err
+		}
+		if hub.Type == "" {
+			hub.Type = defaultHubType
+		}
+	}
+	for i := range c.Rooms {
+		room := &c.Rooms[i]
+		if room.Name == "" {
+			return fmt.Errorf("room name is required")
+		}
+		if room.HeatLossRate <= 0 {
+			room.HeatLossRate = defaultHeatLossRate
+		}
+		if room.Humidity == 0 {
+			room.Humidity = defaultHumidity
+		}
+		for j := range room.Sensors {
+			sensor := &room.Sensors[j]
+			if err := checkID(sensor); err != nil {
+				return err
+			}
+			if sensor.Type == "" {
+				sensor.Type = defaultSensorType
+			}
+			if sensor.Battery == 0 {
+				sensor.Battery = defaultBattery
+			}
+		}
+		for j := range room.AirConditioners {
+			ac := &room.AirConditioners[j]
+			if err := checkID(ac); err != nil {
+				return err
+			}
+			if ac.Capacity <= 0 {
+				ac.Capacity = defaultCapacity
+			}
+		}
+	}
+	for _, room := range c.Rooms {
+		for _, d := range append(append([]DeviceConfig{}, room.Sensors...), room.AirConditioners...) {
+			if d.HubID != "" && !c.hasHub(d.HubID) {
+				return fmt.Errorf("device %s refers to unknown hub %s", d.ID, d.HubID)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Config) hasHub(id string) bool {
+	for _, hub := range c.Hubs {
+		if hub.ID == id {
+			return true
+		}
+	}
+	return false
+}
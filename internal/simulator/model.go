@@ -0,0 +1,214 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Air conditioner modes, as used in the setAll command of infrared remotes.
+const (
+	modeAuto = 1
+	modeCool = 2
+	modeDry  = 3
+	modeFan  = 4
+	modeHeat = 5
+)
+
+const (
+	// responseGain is how strongly an air conditioner reacts to the distance
+	// between the room and its setpoint, per hour, before it hits its capacity.
+	responseGain = 2.0
+	// maxStep bounds the integration step of the thermal model.
+	maxStep = time.Minute
+)
+
+// room is the thermal state of a simulated room.
+type room struct {
+	name         string
+	temperature  float64
+	humidity     int
+	heatLossRate float64
+}
+
+// airConditioner is the state of a simulated infrared air conditioner.
+type airConditioner struct {
+	config   DeviceConfig
+	room     *room
+	power    bool
+	setpoint float64
+	mode     int
+	fan      int
+}
+
+// RoomState is a snapshot of a room exposed for tests and debugging.
+type RoomState struct {
+	Name            string                `json:"name"`
+	Temperature     float64               `json:"temperature"`
+	AirConditioners []AirConditionerState `json:"airConditioners"`
+}
+
+// AirConditionerState is a snapshot of an air conditioner.
+type AirConditionerState struct {
+	ID       string  `json:"id"`
+	Power    bool    `json:"power"`
+	Setpoint float64 `json:"setpoint"`
+	Mode     int     `json:"mode"`
+	Fan      int     `json:"fan"`
+}
+
+// model integrates the room temperatures over simulated time.
+type model struct {
+	mu        sync.Mutex
+	now       func() time.Time
+	last      time.Time
+	timeScale float64
+	outdoor   float64
+	rooms     []*room
+	acs       map[string]*airConditioner
+	// sensorRooms maps sensor IDs to the room they measure
+	sensorRooms map[string]*room
+}
+
+func newModel(cfg *Config, now func() time.Time) *model {
+	m := &model{
+		now:         now,
+		last:        now(),
+		timeScale:   cfg.TimeScale,
+		outdoor:     cfg.OutdoorTemperature,
+		acs:         map[string]*airConditioner{},
+		sensorRooms: map[string]*room{},
+	}
+	for _, rc := range cfg.Rooms {
+		r := &room{
+			name:         rc.Name,
+			temperature:  rc.InitialTemperature,
+			humidity:     rc.Humidity,
+			heatLossRate: rc.HeatLossRate,
+		}
+		m.rooms = append(m.rooms, r)
+		for _, sensor := range rc.Sensors {
+			m.sensorRooms[sensor.ID] = r
+		}
+		for _, ac := range rc.AirConditioners {
+			m.acs[ac.ID] = &airConditioner{config: ac, room: r, setpoint: rc.InitialTemperature, mode: modeAuto, fan: 1}
+		}
+	}
+	return m
+}
+
+// advance integrates the model up to the current time. It must be called with mu held.
+func (m *model) advance() {
+	now := m.now()
+	elapsed := time.Duration(float64(now.Sub(m.last)) * m.timeScale)
+	m.last = now
+	for elapsed > 0 {
+		step := min(elapsed, maxStep)
+		elapsed -= step
+		hours := step.Hours()
+		for _, r := range m.rooms {
+			delta := r.heatLossRate * (m.outdoor - r.temperature)
+			for _, ac := range m.acs {
+				if ac.room == r {
+					delta += ac.effect()
+				}
+			}
+			r.temperature += delta * hours
+		}
+	}
+}
+
+// effect returns how fast the air conditioner changes its room temperature, in °C per hour.
+func (ac *airConditioner) effect() float64 {
+	if !ac.power {
+		return 0
+	}
+	demand := responseGain * (ac.setpoint - ac.room.temperature)
+	capacity := ac.config.Capacity
+	switch ac.mode {
+	case modeCool:
+		return math.Max(math.Min(demand, 0), -capacity)
+	case modeDry:
+		return math.Max(math.Min(demand, 0), -capacity/2)
+	case modeHeat:
+		return math.Min(math.Max(demand, 0), capacity)
+	case modeAuto:
+		return math.Max(math.Min(demand, capacity), -capacity)
+	default:
+		return 0
+	}
+}
+
+// temperature returns the temperature measured by a sensor, rounded like a real meter.
+func (m *model) temperature(sensorID string) (float64, int, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.sensorRooms[sensorID]
+	if !ok {
+		return 0, 0, false
+	}
+	m.advance()
+	return math.Round(r.temperature*10) / 10, r.humidity, true
+}
+
+// command applies an infrared command to an air conditioner.
+func (m *model) command(deviceID, command, parameter string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ac, ok := m.acs[deviceID]
+	if !ok {
+		return fmt.Errorf("command is not supported by device %s", deviceID)
+	}
+	// settle the room under the previous state before changing it
+	m.advance()
+	switch command {
+	case "turnOn":
+		ac.power = true
+	case "turnOff":
+		ac.power = false
+	case "setAll":
+		var setpoint float64
+		var mode, fan int
+		var power string
+		if _, err := fmt.Sscanf(parameter, "%g,%d,%d,%s", &setpoint, &mode, &fan, &power); err != nil {
+			return fmt.Errorf("invalid setAll parameter %q", parameter)
+		}
+		if mode < modeAuto || mode > modeHeat || (power != "on" && power != "off") {
+			return fmt.Errorf("invalid setAll parameter %q", parameter)
+		}
+		ac.setpoint, ac.mode, ac.fan, ac.power = setpoint, mode, fan, power == "on"
+	default:
+		return fmt.Errorf("unsupported command %s", command)
+	}
+	return nil
+}
+
+// state returns a snapshot of every room.
+func (m *model) state() []RoomState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.advance()
+	states := make([]RoomState, 0, len(m.rooms))
+	for _, r := range m.rooms {
+		state := RoomState{Name: r.name, Temperature: r.temperature, AirConditioners: []AirConditionerState{}}
+		for _, ac := range m.acs {
+			if ac.room == r {
+				state.AirConditioners = append(state.AirConditioners, AirConditionerState{
+					ID:       ac.config.ID,
+					Power:    ac.power,
+					Setpoint: ac.setpoint,
+					Mode:     ac.mode,
+					Fan:      ac.fan,
+				})
+			}
+		}
+		slices.SortFunc(state.AirConditioners, func(a, b AirConditionerState) int {
+			return strings.Compare(a.ID, b.ID)
+		})
+		states = append(states, state)
+	}
+	return states
+}
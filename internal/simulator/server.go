@@ -0,0 +1,298 @@
+// Package simulator implements a fake SwitchBot API v1.1 backed by a thermal
+// model of the rooms, so that the controller can be run in a closed loop
+// without real devices.
+package simulator
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SwitchBot API status codes returned in the response body.
+const (
+	statusSuccess         = 100
+	statusDeviceNotFound  = 152
+	statusNotSupported    = 160
+	statusDeviceOffline   = 161
+	statusHubOffline      = 171
+	statusInvalidArgument = 190
+)
+
+// Simulator serves the subset of the SwitchBot API v1.1 used by the controller.
+type Simulator struct {
+	cfg   *Config
+	model *model
+	sleep func(time.Duration)
+
+	mu     sync.Mutex
+	rand   *rand.Rand
+	frozen map[string]sensorReading
+}
+
+// New returns a simulator for cfg. now is the clock driving the thermal model;
+// nil means time.Now.
+func New(cfg *Config, now func() time.Time) (*Simulator, error) {
+	if err := cfg.setDefaults(); err != nil {
+		return nil, err
+	}
+	if now == nil {
+		now = time.Now
+	}
+	return &Simulator{
+		cfg:    cfg,
+		model:  newModel(cfg, now),
+		sleep:  time.Sleep,
+		rand:   rand.New(rand.NewPCG(cfg.Seed, cfg.Seed)),
+		frozen: map[string]sensorReading{},
+	}, nil
+}
+
+// Handler returns the HTTP handler serving the API under /v1.1 and the
+// simulation state under /sim/state.
+func (s *Simulator) Handler() http.Handler {
+	api := http.NewServeMux()
+	api.HandleFunc("GET /v1.1/devices", s.listDevices)
+	api.HandleFunc("GET /v1.1/devices/{id}/status", s.deviceStatus)
+	api.HandleFunc("POST /v1.1/devices/{id}/commands", s.deviceCommand)
+
+	mux := http.NewServeMux()
+	mux.Handle("/v1.1/", s.injectFailures(s.authenticate(api)))
+	mux.HandleFunc("GET /sim/state", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.model.state())
+	})
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return mux
+}
+
+// State returns a snapshot of the simulated rooms.
+func (s *Simulator) State() []RoomState {
+	return s.model.state()
+}
+
+// injectFailures adds the configured latency and rate limit errors.
+func (s *Simulator) injectFailures(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if latency := s.cfg.Failures.Latency.Duration; latency > 0 {
+			s.sleep(latency)
+		}
+		if p := s.cfg.Failures.RateLimitProbability; p > 0 {
+			s.mu.Lock()
+			limited := s.rand.Float64() < p
+			s.mu.Unlock()
+			if limited {
+				writeJSON(w, http.StatusTooManyRequests, map[string]string{"message": "Too Many Requests"})
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticate verifies the token and the HMAC-SHA256 signature of the request.
+func (s *Simulator) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("Authorization")
+		data := token + r.Header.Get("t") + r.Header.Get("nonce")
+		mac := hmac.New(sha256.New, []byte(s.cfg.Secret))
+		mac.Write([]byte(data))
+		want := strings.ToUpper(base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+		if token != s.cfg.Token || !hmac.Equal([]byte(want), []byte(r.Header.Get("sign"))) {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "Unauthorized"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type deviceJSON struct {
+	DeviceID           string `json:"deviceId"`
+	DeviceName         string `json:"deviceName"`
+	DeviceType         string `json:"deviceType"`
+	EnableCloudService bool   `json:"enableCloudService"`
+	HubDeviceID        string `json:"hubDeviceId"`
+}
+
+type infraredRemoteJSON struct {
+	DeviceID    string `json:"deviceId"`
+	DeviceName  string `json:"deviceName"`
+	RemoteType  string `json:"remoteType"`
+	HubDeviceID string `json:"hubDeviceId"`
+}
+
+func (s *Simulator) listDevices(w http.ResponseWriter, r *http.Request) {
+	devices := []deviceJSON{}
+	remotes := []infraredRemoteJSON{}
+	for _, hub := range s.cfg.Hubs {
+		devices = append(devices, deviceJSON{DeviceID: hub.ID, DeviceName: hub.Name, DeviceType: hub.Type, EnableCloudService: true})
+	}
+	for _, room := range s.cfg.Rooms {
+		for _, sensor := range room.Sensors {
+			devices = append(devices, deviceJSON{
+				DeviceID:           sensor.ID,
+				DeviceName:         sensor.Name,
+				DeviceType:         sensor.Type,
+				EnableCloudService: true,
+				HubDeviceID:        sensor.HubID,
+			})
+		}
+		for _, ac := range room.AirConditioners {
+			remotes = append(remotes, infraredRemoteJSON{
+				DeviceID:    ac.ID,
+				DeviceName:  ac.Name,
+				RemoteType:  "Air Conditioner",
+				HubDeviceID: ac.HubID,
+			})
+		}
+	}
+	writeBody(w, statusSuccess, "success", map[string]any{
+		"deviceList":         devices,
+		"infraredRemoteList": remotes,
+	})
+}
+
+func (s *Simulator) deviceStatus(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	device, kind := s.findDevice(id)
+	switch {
+	case kind == "":
+		writeBody(w, statusDeviceNotFound, "device not found", nil)
+		return
+	case kind == kindAirConditioner:
+		writeBody(w, statusNotSupported, "status is not supported by infrared remotes", nil)
+		return
+	}
+	if code, message, offline := s.offline(device); offline {
+		writeBody(w, code, message, nil)
+		return
+	}
+	body := map[string]any{
+		"deviceId":    device.ID,
+		"deviceType":  device.Type,
+		"hubDeviceId": device.HubID,
+		"version":     "V1.0",
+	}
+	if kind == kindSensor {
+		temperature, humidity := s.reading(id)
+		body["temperature"] = temperature
+		body["humidity"] = humidity
+		body["battery"] = device.Battery
+	}
+	writeBody(w, statusSuccess, "success", body)
+}
+
+// reading returns the temperature and humidity reported by a sensor, which for a
+// frozen sensor is the first one it reported.
+func (s *Simulator) reading(id string) (float64, int) {
+	temperature, humidity, _ := s.model.temperature(id)
+	if !slices.Contains(s.cfg.Failures.FrozenSensors, id) {
+		return temperature, humidity
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if frozen, ok := s.frozen[id]; ok {
+		return frozen.temperature, frozen.humidity
+	}
+	s.frozen[id] = sensorReading{temperature: temperature, humidity: humidity}
+	return temperature, humidity
+}
+
+// sensorReading is the reading a frozen sensor keeps reporting.
+type sensorReading struct {
+	temperature float64
+	humidity    int
+}
+
+func (s *Simulator) deviceCommand(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	device, kind := s.findDevice(id)
+	if kind == "" {
+		writeBody(w, statusDeviceNotFound, "device not found", nil)
+		return
+	}
+	if kind != kindAirConditioner {
+		writeBody(w, statusNotSupported, "command is not supported", nil)
+		return
+	}
+	if code, message, offline := s.offline(device); offline {
+		writeBody(w, code, message, nil)
+		return
+	}
+	var payload struct {
+		Command     string `json:"command"`
+		Parameter   string `json:"parameter"`
+		CommandType string `json:"commandType"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeBody(w, statusInvalidArgument, "invalid request body", nil)
+		return
+	}
+	if err := s.model.command(id, payload.Command, payload.Parameter); err != nil {
+		writeBody(w, statusInvalidArgument, err.Error(), nil)
+		return
+	}
+	writeBody(w, statusSuccess, "success", map[string]any{})
+}
+
+const (
+	kindHub            = "hub"
+	kindSensor         = "sensor"
+	kindAirConditioner = "airConditioner"
+)
+
+// findDevice returns the configuration of the device with the given ID and its kind,
+// or an empty kind when there is no such device.
+func (s *Simulator) findDevice(id string) (DeviceConfig, string) {
+	for _, hub := range s.cfg.Hubs {
+		if hub.ID == id {
+			return hub, kindHub
+		}
+	}
+	for _, room := range s.cfg.Rooms {
+		for _, sensor := range room.Sensors {
+			if sensor.ID == id {
+				return sensor, kindSensor
+			}
+		}
+		for _, ac := range room.AirConditioners {
+			if ac.ID == id {
+				return ac, kindAirConditioner
+			}
+		}
+	}
+	return DeviceConfig{}, ""
+}
+
+// offline reports whether device is unreachable because it, or its hub, is offline.
+func (s *Simulator) offline(device DeviceConfig) (int, string, bool) {
+	if slices.Contains(s.cfg.Failures.OfflineHubs, device.ID) {
+		return statusDeviceOffline, "device offline", true
+	}
+	if device.HubID != "" && slices.Contains(s.cfg.Failures.OfflineHubs, device.HubID) {
+		return statusHubOffline, "hub device is offline", true
+	}
+	return 0, "", false
+}
+
+func writeBody(w http.ResponseWriter, statusCode int, message string, body any) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"statusCode": statusCode,
+		"message":    message,
+		"body":       body,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
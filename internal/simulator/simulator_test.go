@@ -0,0 +1,270 @@
+package simulator
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	thermopilotv1 "github.com/seipan/thermo-pilot-controller/api/v1"
+	switchbotclient "github.com/seipan/thermo-pilot-controller/internal/client"
+	"github.com/seipan/thermo-pilot-controller/internal/planner"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Step(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func testConfig() *Config {
+	return &Config{
+		Token:              "test-token",
+		Secret:             "test-secret",
+		OutdoorTemperature: 31.0,
+		Hubs:               []DeviceConfig{{ID: "HUB1"}},
+		Rooms: []RoomConfig{{
+			Name:               "living-room",
+			InitialTemperature: 28.5,
+			Sensors:            []DeviceConfig{{ID: "METER1", Type: "MeterPro", HubID: "HUB1"}},
+			AirConditioners:    []DeviceConfig{{ID: "AC1", HubID: "HUB1"}},
+		}},
+	}
+}
+
+func newTestServer(t *testing.T, cfg *Config) (*Simulator, *fakeClock, *switchbotclient.Client) {
+	t.Helper()
+	clock := &fakeClock{now: time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)}
+	sim, err := New(cfg, clock.Now)
+	require.NoError(t, err)
+	sim.sleep = func(time.Duration) {}
+	server := httptest.NewServer(sim.Handler())
+	t.Cleanup(server.Close)
+	client := switchbotclient.NewClient(cfg.Token, cfg.Secret,
+		switchbotclient.WithBaseURL(server.URL+"/v1.1"),
+		switchbotclient.WithTransport(server.Client().Transport))
+	return sim, clock, client
+}
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := LoadConfig("../../cmd/switchbot-sim/switchbot-sim.yaml")
+	require.NoError(t, err)
+	_, err = New(cfg, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 60.0, cfg.TimeScale)
+	assert.Equal(t, 50*time.Millisecond, cfg.Failures.Latency.Duration)
+	require.Len(t, cfg.Rooms, 1)
+	assert.Equal(t, 4.0, cfg.Rooms[0].AirConditioners[0].Capacity)
+}
+
+func TestConfig_Validation(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*Config)
+	}{
+		{name: "missing credentials", mutate: func(c *Config) { c.Secret = "" }},
+		{name: "duplicate device", mutate: func(c *Config) { c.Rooms[0].AirConditioners[0].ID = "METER1" }},
+		{name: "unknown hub", mutate: func(c *Config) { c.Rooms[0].Sensors[0].HubID = "HUB2" }},
+		{name: "invalid rate limit probability", mutate: func(c *Config) { c.Failures.RateLimitProbability = 2 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			tt.mutate(cfg)
+			_, err := New(cfg, nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSimulator_Devices(t *testing.T) {
+	_, _, client := newTestServer(t, testConfig())
+	ctx := context.Background()
+
+	meter, err := client.GetMeterPro(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "METER1", meter.DeviceID)
+
+	acs, err := client.MultiGetAirConditioners(ctx)
+	require.NoError(t, err)
+	require.Len(t, acs, 1)
+	assert.Equal(t, "AC1", acs[0].DeviceID)
+
+	temperature, err := client.GetNowTemperature(ctx, "METER1")
+	require.NoError(t, err)
+	assert.Equal(t, 28.5, temperature)
+}
+
+func TestSimulator_FrozenSensor(t *testing.T) {
+	cfg := testConfig()
+	cfg.Rooms[0].Sensors[0].Battery = 15
+	cfg.Failures.FrozenSensors = []string{"METER1"}
+	_, clock, client := newTestServer(t, cfg)
+	ctx := context.Background()
+
+	first, err := client.GetMeterStatus(ctx, "METER1")
+	require.NoError(t, err)
+	require.NotNil(t, first.Battery)
+	assert.Equal(t, 15, *first.Battery)
+
+	clock.Step(2 * time.Hour)
+	second, err := client.GetMeterStatus(ctx, "METER1")
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+}
+
+func TestSimulator_Authentication(t *testing.T) {
+	sim, err := New(testConfig(), nil)
+	require.NoError(t, err)
+	server := httptest.NewServer(sim.Handler())
+	defer server.Close()
+
+	client := switchbotclient.NewClient("test-token", "wrong-secret",
+		switchbotclient.WithBaseURL(server.URL+"/v1.1"),
+		switchbotclient.WithTransport(server.Client().Transport))
+	_, err = client.ListDevices(context.Background())
+	require.Error(t, err)
+	assert.True(t, switchbotclient.IsUnauthorized(err))
+}
+
+func TestSimulator_Failures(t *testing.T) {
+	tests := []struct {
+		name       string
+		failures   FailureConfig
+		deviceID   string
+		wantStatus int
+	}{
+		{name: "offline hub", failures: FailureConfig{OfflineHubs: []string{"HUB1"}}, deviceID: "METER1"},
+		{name: "unknown device", deviceID: "METER2"},
+		{name: "rate limited", failures: FailureConfig{RateLimitProbability: 1}, deviceID: "METER1", wantStatus: http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Failures = tt.failures
+			_, _, client := newTestServer(t, cfg)
+
+			_, err := client.GetNowTemperature(context.Background(), tt.deviceID)
+			require.Error(t, err)
+			var apiErr *switchbotclient.APIError
+			if tt.wantStatus != 0 {
+				require.True(t, errors.As(err, &apiErr))
+				assert.Equal(t, tt.wantStatus, apiErr.StatusCode)
+			} else {
+				assert.False(t, errors.As(err, &apiErr))
+			}
+		})
+	}
+}
+
+func TestSimulator_ThermalModel(t *testing.T) {
+	tests := []struct {
+		name    string
+		command func(context.Context, *switchbotclient.Client) error
+		wantMin float64
+		wantMax float64
+	}{
+		{
+			name:    "drifts towards outdoor temperature when off",
+			command: func(context.Context, *switchbotclient.Client) error { return nil },
+			wantMin: 30.0,
+			wantMax: 31.0,
+		},
+		{
+			name: "cools towards the setpoint",
+			command: func(ctx context.Context, c *switchbotclient.Client) error {
+				return c.SetTemperature(ctx, "AC1", 24, switchbotclient.ModeCool)
+			},
+			wantMin: 24.0,
+			wantMax: 25.5,
+		},
+		{
+			name: "heating does not cool a warm room",
+			command: func(ctx context.Context, c *switchbotclient.Client) error {
+				return c.SetTemperature(ctx, "AC1", 20, switchbotclient.ModeHeat)
+			},
+			wantMin: 30.0,
+			wantMax: 31.0,
+		},
+		{
+			name: "stops cooling once turned off",
+			command: func(ctx context.Context, c *switchbotclient.Client) error {
+				if err := c.SetTemperature(ctx, "AC1", 24, switchbotclient.ModeCool); err != nil {
+					return err
+				}
+				return c.TurnOff(ctx, "AC1")
+			},
+			wantMin: 30.0,
+			wantMax: 31.0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, clock, client := newTestServer(t, testConfig())
+			ctx := context.Background()
+			require.NoError(t, tt.command(ctx, client))
+
+			clock.Step(12 * time.Hour)
+			temperature, err := client.GetNowTemperature(ctx, "METER1")
+			require.NoError(t, err)
+			assert.GreaterOrEqual(t, temperature, tt.wantMin)
+			assert.LessOrEqual(t, temperature, tt.wantMax)
+		})
+	}
+}
+
+// TestSimulator_ClosedLoop drives the simulated room with the planner, the way
+// the controller does, and checks that it settles within the threshold.
+func TestSimulator_ClosedLoop(t *testing.T) {
+	sim, clock, client := newTestServer(t, testConfig())
+	ctx := context.Background()
+	spec := thermopilotv1.ThermoPilotSpec{
+		TargetTemperature: "25.0",
+		Threshold:         "1.0",
+		Mode:              planner.ModeCool,
+	}
+
+	var temperature float64
+	for range 36 {
+		var err error
+		temperature, err = client.GetNowTemperature(ctx, "METER1")
+		require.NoError(t, err)
+		plan, err := planner.Decide(planner.Input{
+			Now:                clock.Now(),
+			CurrentTemperature: temperature,
+			Spec:               spec,
+			AirConditionerIDs:  []string{"AC1"},
+		})
+		require.NoError(t, err)
+		for _, command := range plan.Commands {
+			require.NoError(t, client.SetTemperature(ctx, command.DeviceID, command.Setpoint, switchbotclient.ModeCool))
+		}
+		clock.Step(5 * time.Minute)
+	}
+
+	assert.InDelta(t, 25.0, temperature, 1.0)
+	state := sim.State()
+	require.Len(t, state, 1)
+	assert.True(t, state[0].AirConditioners[0].Power)
+	assert.Equal(t, modeCool, state[0].AirConditioners[0].Mode)
+}
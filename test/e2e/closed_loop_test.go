@@ -0,0 +1,161 @@
+//go:build e2e
+// +build e2e
+
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/seipan/thermo-pilot-controller/test/utils"
+)
+
+// controllerDeploymentName is the name of the controller-manager deployment
+const controllerDeploymentName = "thermo-pilot-controller-controller-manager"
+
+// closedLoopThermoPilot is the ThermoPilot created from testdata/thermopilot.yaml
+const closedLoopThermoPilot = "e2e-closed-loop"
+
+var _ = Describe("Closed loop against the SwitchBot simulator", Ordered, func() {
+	// Deploy the controller pointed at an in-cluster simulator whose room starts
+	// above the target temperature.
+	BeforeAll(func() {
+		By("creating manager namespace")
+		cmd := exec.Command("kubectl", "create", "ns", namespace)
+		_, err := utils.Run(cmd)
+		Expect(err).NotTo(HaveOccurred(), "Failed to create namespace")
+
+		By("labeling the namespace to enforce the restricted security policy")
+		cmd = exec.Command("kubectl", "label", "--overwrite", "ns", namespace,
+			"pod-security.kubernetes.io/enforce=restricted")
+		_, err = utils.Run(cmd)
+		Expect(err).NotTo(HaveOccurred(), "Failed to label namespace with restricted policy")
+
+		By("installing CRDs")
+		cmd = exec.Command("make", "install")
+		_, err = utils.Run(cmd)
+		Expect(err).NotTo(HaveOccurred(), "Failed to install CRDs")
+
+		By("deploying the SwitchBot simulator")
+		cmd = exec.Command("kubectl", "apply", "-n", namespace, "-f", "test/e2e/testdata/switchbot-sim.yaml")
+		_, err = utils.Run(cmd)
+		Expect(err).NotTo(HaveOccurred(), "Failed to deploy the SwitchBot simulator")
+		cmd = exec.Command("kubectl", "rollout", "status", "deployment/switchbot-sim", "-n", namespace, "--timeout=2m")
+		_, err = utils.Run(cmd)
+		Expect(err).NotTo(HaveOccurred(), "SwitchBot simulator did not become ready")
+
+		By("deploying the controller-manager against the simulator")
+		cmd = exec.Command("make", "deploy", fmt.Sprintf("IMG=%s", projectImage))
+		_, err = utils.Run(cmd)
+		Expect(err).NotTo(HaveOccurred(), "Failed to deploy the controller-manager")
+		apiURL := fmt.Sprintf("--switchbot-api-url=http://switchbot-sim.%s.svc:8080/v1.1", namespace)
+		cmd = exec.Command("kubectl", "patch", "deployment", controllerDeploymentName, "-n", namespace,
+			"--type=json", "-p", fmt.Sprintf(`[{"op":"add","path":"/spec/template/spec/containers/0/args/-","value":%q}]`, apiURL))
+		_, err = utils.Run(cmd)
+		Expect(err).NotTo(HaveOccurred(), "Failed to point the controller-manager at the simulator")
+		cmd = exec.Command("kubectl", "rollout", "status", "deployment/"+controllerDeploymentName, "-n", namespace, "--timeout=2m")
+		_, err = utils.Run(cmd)
+		Expect(err).NotTo(HaveOccurred(), "controller-manager did not become ready")
+	})
+
+	AfterAll(func() {
+		By("deleting the ThermoPilot")
+		cmd := exec.Command("kubectl", "delete", "-n", namespace, "-f", "test/e2e/testdata/thermopilot.yaml", "--ignore-not-found")
+		_, _ = utils.Run(cmd)
+
+		By("undeploying the controller-manager")
+		cmd = exec.Command("make", "undeploy")
+		_, _ = utils.Run(cmd)
+
+		By("removing the SwitchBot simulator")
+		cmd = exec.Command("kubectl", "delete", "-n", namespace, "-f", "test/e2e/testdata/switchbot-sim.yaml", "--ignore-not-found")
+		_, _ = utils.Run(cmd)
+
+		By("uninstalling CRDs")
+		cmd = exec.Command("make", "uninstall")
+		_, _ = utils.Run(cmd)
+
+		By("removing manager namespace")
+		cmd = exec.Command("kubectl", "delete", "ns", namespace)
+		_, _ = utils.Run(cmd)
+	})
+
+	AfterEach(func() {
+		if CurrentSpecReport().Failed() {
+			By("Fetching controller manager logs")
+			cmd := exec.Command("kubectl", "logs", "deployment/"+controllerDeploymentName, "-n", namespace)
+			if logs, err := utils.Run(cmd); err == nil {
+				_, _ = fmt.Fprintf(GinkgoWriter, "Controller logs:\n %s", logs)
+			}
+
+			By("Fetching the ThermoPilot")
+			cmd = exec.Command("kubectl", "get", "thermopilot", closedLoopThermoPilot, "-n", namespace, "-o", "yaml")
+			if output, err := utils.Run(cmd); err == nil {
+				_, _ = fmt.Fprintf(GinkgoWriter, "ThermoPilot:\n%s", output)
+			}
+		}
+	})
+
+	It("should drive the simulated room to the target temperature", func() {
+		By("creating a ThermoPilot for the simulated room")
+		cmd := exec.Command("kubectl", "apply", "-n", namespace, "-f", "test/e2e/testdata/thermopilot.yaml")
+		_, err := utils.Run(cmd)
+		Expect(err).NotTo(HaveOccurred(), "Failed to create the ThermoPilot")
+
+		By("sending a cooling command while the room is too warm")
+		Eventually(func(g Gomega) {
+			cmd := exec.Command("kubectl", "get", "thermopilot", closedLoopThermoPilot, "-n", namespace,
+				"-o", "jsonpath={.status.lastCommand.action}")
+			output, err := utils.Run(cmd)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(output).To(Equal("cooling"))
+		}, 2*time.Minute, time.Second).Should(Succeed())
+
+		By("settling within the threshold of the target")
+		poke := 0
+		Eventually(func(g Gomega) {
+			// Reconcile on demand rather than waiting for the requeue interval,
+			// since simulated time passes much faster than real time.
+			poke++
+			cmd := exec.Command("kubectl", "annotate", "--overwrite", "thermopilot", closedLoopThermoPilot,
+				"-n", namespace, fmt.Sprintf("e2e.thermo-pilot.yadon3141.com/poke=%d", poke))
+			_, err := utils.Run(cmd)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			cmd = exec.Command("kubectl", "get", "thermopilot", closedLoopThermoPilot, "-n", namespace,
+				"-o", "jsonpath={.status.currentTemperature}")
+			output, err := utils.Run(cmd)
+			g.Expect(err).NotTo(HaveOccurred())
+			current, err := strconv.ParseFloat(output, 64)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(current).To(BeNumerically("~", 25.0, 1.0))
+		}, 5*time.Minute, 10*time.Second).Should(Succeed())
+
+		By("reporting Ready")
+		cmd = exec.Command("kubectl", "wait", "thermopilot/"+closedLoopThermoPilot, "-n", namespace,
+			"--for=condition=Ready", "--timeout=1m")
+		_, err = utils.Run(cmd)
+		Expect(err).NotTo(HaveOccurred(), "ThermoPilot did not become Ready")
+	})
+})
@@ -43,6 +43,9 @@ var (
 	// projectImage is the name of the image which will be build and loaded
 	// with the code source changes to be tested.
 	projectImage = "example.com/thermo-pilot-controller:v0.0.1"
+
+	// simulatorImage is the SwitchBot API simulator the closed-loop tests run against.
+	simulatorImage = "example.com/switchbot-sim:v0.0.1"
 )
 
 // TestE2E runs the end-to-end (e2e) test suite for the project. These tests execute in an isolated,
@@ -67,6 +70,15 @@ var _ = BeforeSuite(func() {
 	err = utils.LoadImageToKindClusterWithName(projectImage)
 	ExpectWithOffset(1, err).NotTo(HaveOccurred(), "Failed to load the manager(Operator) image into Kind")
 
+	By("building the SwitchBot simulator image")
+	cmd = exec.Command("make", "docker-build-sim", fmt.Sprintf("SIM_IMG=%s", simulatorImage))
+	_, err = utils.Run(cmd)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred(), "Failed to build the SwitchBot simulator image")
+
+	By("loading the SwitchBot simulator image on Kind")
+	err = utils.LoadImageToKindClusterWithName(simulatorImage)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred(), "Failed to load the SwitchBot simulator image into Kind")
+
 	// The tests-e2e are intended to run on a temporary cluster that is created and destroyed for testing.
 	// To prevent errors when tests run in environments with CertManager already installed,
 	// we check for its presence before execution.